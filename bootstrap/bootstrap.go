@@ -2,6 +2,7 @@ package bootstrap
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
@@ -9,18 +10,39 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/kasasunil/chat_app/config"
 	"github.com/kasasunil/chat_app/controller"
 	"github.com/kasasunil/chat_app/database"
+	in_memory "github.com/kasasunil/chat_app/database/in-memory"
+	"github.com/kasasunil/chat_app/database/sqlite"
 	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/auth"
 	"github.com/kasasunil/chat_app/internal/pkg/logger"
 	"github.com/kasasunil/chat_app/internal/services/websocket"
 
 	"github.com/gorilla/mux"
 )
 
-// SetupDemoData creates sample users, groups, and simulates connections
+// NewRepository creates the Repository implementation selected by database.mode
+func NewRepository(cfg *config.Config) (database.Repository, error) {
+	switch cfg.Database.Mode {
+	case "", config.DatabaseModeMemory:
+		return in_memory.NewStore(), nil
+	case config.DatabaseModeSQLite:
+		store, err := sqlite.NewStore(cfg.Database.Path, cfg.Database.MaxConnections)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Using SQLite database at %s", cfg.Database.Path)
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unsupported database mode: %s", cfg.Database.Mode)
+	}
+}
+
+// SetupDemoData creates sample users and groups
 // Accepts interface for store (following Go best practices)
-func SetupDemoData(store database.Repository, wsManager websocket.WebSocketManager) {
+func SetupDemoData(store database.Repository) {
 	// Create users
 	user1 := &database.User{
 		ID:    "user1",
@@ -50,23 +72,61 @@ func SetupDemoData(store database.Repository, wsManager websocket.WebSocketManag
 		CreatedBy:   "user1",
 	}
 	store.CreateGroup(group1)
-	store.AddGroupMember("group1", "user1")
-	store.AddGroupMember("group1", "user2")
-	store.AddGroupMember("group1", "user3")
-
-	// Simulate active connections
-	wsManager.AddConnection("user1", "conn1")
-	wsManager.AddConnection("user2", "conn2")
-	wsManager.AddConnection("user3", "conn3")
+	store.AddGroupMember("group1", "user1", database.GroupRoleOwner)
+	store.AddGroupMember("group1", "user2", database.GroupRoleMember)
+	store.AddGroupMember("group1", "user3", database.GroupRoleMember)
 
 	logger.Info(logger.TraceDemoDataInitialized)
 	logger.Info("  Users: Alice (user1), Bob (user2), Charlie (user3)")
 	logger.Info("  Group: Project Team (group1)")
-	logger.Info("  All users are connected")
+	logger.Info("  Clients connect for live updates at /api/v1/ws")
+}
+
+// SetupCredentials copies the configured auth clients into store, each linked to its
+// user. Credentials that already exist keep their login name, which the user may have
+// changed since; only their password follows the config. Clients whose user does not
+// exist are skipped, so they cannot sign in.
+func SetupCredentials(cfg *config.Config, store database.Repository) {
+	for _, client := range cfg.GetAuthClients() {
+		userID := client.GetUserID()
+		if existing, err := store.GetCredential(userID); err == nil {
+			if hash, changed := configuredPasswordHash(client, existing.PasswordHash); changed {
+				if err := store.SetCredentialPassword(userID, hash); err != nil {
+					logger.Warn(logger.TraceCredentialSkipped, client.Username, err)
+				}
+			}
+			continue
+		}
+
+		hash, _ := configuredPasswordHash(client, "")
+		credential := &database.Credential{UserID: userID, LoginName: client.Username, PasswordHash: hash}
+		if err := store.CreateCredential(credential); err != nil {
+			logger.Warn(logger.TraceCredentialSkipped, client.Username, err)
+			continue
+		}
+		logger.Debug(logger.TraceCredentialSeeded, userID, client.Username)
+	}
+}
+
+// configuredPasswordHash returns the hash to store for client's password and whether
+// it differs from current. Deprecated plaintext passwords are hashed here.
+func configuredPasswordHash(client config.ClientAuth, current string) (string, bool) {
+	if client.PasswordHash != "" {
+		return client.PasswordHash, client.PasswordHash != current
+	}
+	if current != "" && auth.CheckPassword(current, client.Password) {
+		return current, false
+	}
+	hash, err := auth.HashPassword(client.Password, auth.DefaultPasswordCost)
+	if err != nil {
+		logger.Warn(logger.TraceCredentialSkipped, client.Username, err)
+		return current, false
+	}
+	return hash, true
 }
 
 // SetupRouter initializes and configures all routes
-func SetupRouter(handler *controller.Handler, authMiddleware *middleware.AuthMiddleware) *mux.Router {
+func SetupRouter(handler *controller.Handler, wsHub *websocket.Hub, authMiddleware *middleware.AuthMiddleware, rateLimit *middleware.RateLimitMiddleware) *mux.Router {
 	router := mux.NewRouter()
 
 	// Public routes (no authentication required)
@@ -75,17 +135,51 @@ func SetupRouter(handler *controller.Handler, authMiddleware *middleware.AuthMid
 		w.Write([]byte("OK"))
 	}).Methods("GET")
 
+	// Sign-up, and sessions started and ended with credentials in the body, not the Authorization header.
+	// Their requests are limited per client IP.
+	router.Handle("/api/v1/auth/login", rateLimit.FailedLogins(rateLimit.Limit(http.HandlerFunc(handler.Login)))).Methods("POST")
+	router.Handle("/api/v1/auth/refresh", rateLimit.Limit(http.HandlerFunc(handler.RefreshToken))).Methods("POST")
+	router.Handle("/api/v1/auth/logout", rateLimit.Limit(http.HandlerFunc(handler.Logout))).Methods("POST")
+	router.Handle("/api/v1/auth/signup", rateLimit.Limit(http.HandlerFunc(handler.SignUp))).Methods("POST")
+
 	// Protected routes (authentication required)
 	// API v1 routes
 	apiRouter := router.PathPrefix("/api/v1").Subrouter()
-	apiRouter.Use(authMiddleware.Authenticate)
+	// Failed Basic sign-ins are counted before authentication, other requests per user after it
+	apiRouter.Use(rateLimit.FailedBasicAuth, authMiddleware.Authenticate, rateLimit.Limit)
 
+	apiRouter.HandleFunc("/users/me", handler.GetMe).Methods("GET")
+	apiRouter.HandleFunc("/users/me", handler.UpdateMe).Methods("PATCH")
+	apiRouter.HandleFunc("/users/me/login-name", handler.RenameLogin).Methods("PUT")
+	apiRouter.HandleFunc("/users/{userId}", handler.GetUserProfile).Methods("GET")
 	apiRouter.HandleFunc("/sendMessage", handler.SendMessage).Methods("POST")
 	apiRouter.HandleFunc("/ack/delivered", handler.AckDelivered).Methods("POST")
 	apiRouter.HandleFunc("/ack/read", handler.AckRead).Methods("POST")
 	apiRouter.HandleFunc("/conversations/{destinationId}/messages", handler.GetMessages).Methods("GET")
+	apiRouter.HandleFunc("/conversations/{destinationId}/read", handler.MarkConversationRead).Methods("POST")
 	apiRouter.HandleFunc("/users/{userId}/conversations", handler.GetUserConversations).Methods("GET")
 	apiRouter.HandleFunc("/search/{userId}", handler.SearchMessages).Methods("GET")
+	apiRouter.HandleFunc("/messages/{messageId}/receipts", handler.GetMessageReceipts).Methods("GET")
+	apiRouter.HandleFunc("/messages/{messageId}", handler.EditMessage).Methods("PATCH")
+	apiRouter.HandleFunc("/messages/{messageId}", handler.DeleteMessage).Methods("DELETE")
+	apiRouter.HandleFunc("/messages/{messageId}/history", handler.GetMessageHistory).Methods("GET")
+	apiRouter.HandleFunc("/messages/{messageId}/thread", handler.GetThreadMessages).Methods("GET")
+	apiRouter.HandleFunc("/messages/{messageId}/reactions", handler.AddReaction).Methods("POST")
+	apiRouter.HandleFunc("/messages/{messageId}/reactions", handler.RemoveReaction).Methods("DELETE")
+	apiRouter.HandleFunc("/groups", handler.CreateGroup).Methods("POST")
+	apiRouter.HandleFunc("/groups/{groupId}", handler.GetGroup).Methods("GET")
+	apiRouter.HandleFunc("/groups/{groupId}", handler.UpdateGroup).Methods("PATCH")
+	apiRouter.HandleFunc("/groups/{groupId}/members", handler.GetGroupMembers).Methods("GET")
+	apiRouter.HandleFunc("/groups/{groupId}/members", handler.AddGroupMembers).Methods("POST")
+	apiRouter.HandleFunc("/groups/{groupId}/members/{userId}", handler.SetGroupMemberRole).Methods("PATCH")
+	apiRouter.HandleFunc("/groups/{groupId}/members/{userId}", handler.RemoveGroupMember).Methods("DELETE")
+	apiRouter.HandleFunc("/groups/{groupId}/leave", handler.LeaveGroup).Methods("POST")
+	apiRouter.HandleFunc("/groups/{groupId}/settings", handler.UpdateGroupSettings).Methods("PATCH")
+	apiRouter.HandleFunc("/groups/{groupId}/invites", handler.CreateGroupInvite).Methods("POST")
+	apiRouter.HandleFunc("/groups/{groupId}/invites", handler.ListGroupInvites).Methods("GET")
+	apiRouter.HandleFunc("/groups/{groupId}/invites/{inviteId}", handler.RevokeGroupInvite).Methods("DELETE")
+	apiRouter.HandleFunc("/invites/{token}/join", handler.JoinGroupInvite).Methods("POST")
+	apiRouter.HandleFunc("/ws", wsHub.HandleWebSocket).Methods("GET")
 	return router
 }
 
@@ -8,9 +8,12 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/gorilla/websocket"
 )
 
 const baseURL = "http://localhost:8080"
+const wsURL = "ws://localhost:8080/api/v1/ws"
 
 func main() {
 	fmt.Println("==============================================")
@@ -74,6 +77,9 @@ func main() {
 	time.Sleep(100 * time.Millisecond)
 	searchMessages("user2", "hello")
 
+	fmt.Println("\n=== STEP 8: Live Delivery over WebSocket ===")
+	demoWebSocket()
+
 	fmt.Println("\n==============================================")
 	fmt.Println("Demo completed successfully!")
 	fmt.Println("==============================================")
@@ -262,6 +268,76 @@ func searchMessages(userID, query string) {
 	}
 }
 
+// demoWebSocket connects Bob on two devices and Alice on one, then checks
+// multi-device fan-out, ACK frames, sender receipts and connection teardown
+func demoWebSocket() {
+	alice, err := dialWebSocket("user1")
+	if err != nil {
+		fmt.Printf("Error connecting WebSocket: %v\n", err)
+		return
+	}
+	defer alice.Close()
+	bobPhone, err := dialWebSocket("user2")
+	if err != nil {
+		fmt.Printf("Error connecting WebSocket: %v\n", err)
+		return
+	}
+	bobLaptop, err := dialWebSocket("user2")
+	if err != nil {
+		fmt.Printf("Error connecting WebSocket: %v\n", err)
+		return
+	}
+	defer bobLaptop.Close()
+	fmt.Println("  Alice connected on 1 device, Bob connected on 2 devices")
+
+	messageID := sendMessage("user1", "user2", "Are you online?")
+	expectFrame(alice, "Alice", "message", messageID) // Alice's own devices get the message too
+	expectFrame(bobPhone, "Bob (phone)", "message", messageID)
+	expectFrame(bobLaptop, "Bob (laptop)", "message", messageID)
+
+	// Bob acknowledges over the socket instead of /ack/delivered
+	bobPhone.WriteJSON(map[string]string{"type": "ack_delivered", "message_id": messageID})
+	expectFrame(bobPhone, "Bob (phone)", "ack_result", messageID)
+	expectFrame(alice, "Alice", "delivered", messageID)
+
+	// Closing one device must leave the other connected
+	bobPhone.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	bobPhone.Close()
+	fmt.Println("  Bob (phone) disconnected")
+	time.Sleep(100 * time.Millisecond)
+
+	messageID = sendMessage("user1", "user2", "Still there?")
+	expectFrame(alice, "Alice", "message", messageID)
+	expectFrame(bobLaptop, "Bob (laptop)", "message", messageID)
+}
+
+func dialWebSocket(userID string) (*websocket.Conn, error) {
+	header := http.Header{}
+	header.Set("Authorization", getBasicAuth(userID))
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, header)
+	return conn, err
+}
+
+// expectFrame reads the next frame and reports whether it has the expected type and message ID
+func expectFrame(conn *websocket.Conn, who, frameType, messageID string) {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var frame map[string]interface{}
+	if err := conn.ReadJSON(&frame); err != nil {
+		fmt.Printf("  [%s] ✗ expected %s frame, got error: %v\n", who, frameType, err)
+		return
+	}
+
+	gotID, _ := frame["message_id"].(string)
+	if msg, ok := frame["message"].(map[string]interface{}); ok {
+		gotID, _ = msg["id"].(string)
+	}
+	if frame["type"] != frameType || gotID != messageID {
+		fmt.Printf("  [%s] ✗ expected %s frame for %s, got %v\n", who, frameType, messageID, frame)
+		return
+	}
+	fmt.Printf("  [%s] ✓ received %s frame for %s\n", who, frameType, messageID)
+}
+
 func getSenderName(userID string) string {
 	names := map[string]string{
 		"user1": "Alice",
@@ -0,0 +1,50 @@
+// Command hashpassword prints a bcrypt hash to use as an auth client's password_hash.
+// The password is read from the first line of standard input, so it stays out of
+// shell history and process listings.
+//
+// Usage:
+//
+//	go run ./cmd/hashpassword [-cost 10] [-username user1]
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kasasunil/chat_app/internal/pkg/auth"
+)
+
+func main() {
+	cost := flag.Int("cost", auth.DefaultPasswordCost, "bcrypt cost (4-31); each step doubles the time to check a password")
+	username := flag.String("username", "", "print a complete [[auth.clients]] entry for this username")
+	flag.Parse()
+
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		fmt.Fprint(os.Stderr, "Password: ")
+	}
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		fmt.Fprintln(os.Stderr, "no password given on standard input")
+		os.Exit(2)
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if password == "" {
+		fmt.Fprintln(os.Stderr, "password cannot be empty")
+		os.Exit(2)
+	}
+
+	hash, err := auth.HashPassword(password, *cost)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *username == "" {
+		fmt.Println(hash)
+		return
+	}
+	fmt.Printf("[[auth.clients]]\n    username = %q\n    password_hash = %q\n", *username, hash)
+}
@@ -2,12 +2,11 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"time"
 
-	in_memory "github.com/kasasunil/chat_app/database/in-memory"
-
 	"github.com/kasasunil/chat_app/bootstrap"
 	"github.com/kasasunil/chat_app/config"
 	"github.com/kasasunil/chat_app/controller"
@@ -36,18 +35,34 @@ func main() {
 		logger.Warn(logger.TraceLoggerInitFailed, err)
 	}
 
-	store := in_memory.NewStore() // For product use actual db instance.
-	wsManager := websocket.NewMockWebSocketManager()
-	handler := controller.NewHandler(store, wsManager)
+	if cfg.Security.SigningKey == "" {
+		logger.Warn(logger.TraceSigningKeyMissing)
+	}
+	for _, warning := range cfg.DeprecationWarnings() {
+		logger.Warn(logger.TraceConfigDeprecated, warning)
+	}
+
+	store, err := bootstrap.NewRepository(cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize database: %v", err)
+	}
+	wsHub := websocket.NewHub(store)
+	handler := controller.NewHandler(cfg, store, wsHub)
+	// ACK frames received over WebSocket go through the same checks as /ack/*
+	wsHub.SetAckProcessor(handler)
 
 	// Setup demo data
-	bootstrap.SetupDemoData(store, wsManager)
+	bootstrap.SetupDemoData(store)
+	bootstrap.SetupCredentials(cfg, store)
 
 	// Initialize authentication middleware
-	authMiddleware := middleware.NewAuthMiddleware(cfg)
+	authMiddleware := middleware.NewAuthMiddleware(cfg, store)
+
+	// Initialize rate limiting middleware
+	rateLimit := middleware.NewRateLimitMiddleware(cfg)
 
 	// Setup routes
-	router := bootstrap.SetupRouter(handler, authMiddleware)
+	router := bootstrap.SetupRouter(handler, wsHub, authMiddleware, rateLimit)
 
 	// Create HTTP server with timeouts
 	server := &http.Server{
@@ -57,18 +72,58 @@ func main() {
 		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
 		IdleTimeout:  120 * time.Second,
 	}
+	// Hijacked WebSocket connections are not closed by Shutdown
+	server.RegisterOnShutdown(wsHub.Shutdown)
 
 	logger.Info(logger.TraceServerStarting, cfg.Server.Host, cfg.Server.Port)
-	logger.Info("API Endpoints (all require authentication):")
+	logger.Info("Sign-up and Session Endpoints (credentials in the body):")
+	logger.Info("  POST   /api/v1/auth/login")
+	logger.Info("  POST   /api/v1/auth/refresh")
+	logger.Info("  POST   /api/v1/auth/logout")
+	logger.Info("  POST   /api/v1/auth/signup")
+	logger.Info("API Endpoints (all require Basic auth or a Bearer access token):")
+	logger.Info("  GET    /api/v1/users/me")
+	logger.Info("  PATCH  /api/v1/users/me")
+	logger.Info("  PUT    /api/v1/users/me/login-name")
+	logger.Info("  GET    /api/v1/users/{userId}")
 	logger.Info("  POST   /api/v1/sendMessage")
 	logger.Info("  POST   /api/v1/ack/delivered")
 	logger.Info("  POST   /api/v1/ack/read")
 	logger.Info("  GET    /api/v1/conversations/{destinationId}/messages")
+	logger.Info("  POST   /api/v1/conversations/{destinationId}/read")
 	logger.Info("  GET    /api/v1/users/{userId}/conversations")
 	logger.Info("  GET    /api/v1/search/{userId}?query=xxx")
+	logger.Info("  GET    /api/v1/messages/{messageId}/receipts")
+	logger.Info("  PATCH  /api/v1/messages/{messageId}")
+	logger.Info("  DELETE /api/v1/messages/{messageId}?scope=me|everyone")
+	logger.Info("  GET    /api/v1/messages/{messageId}/history")
+	logger.Info("  GET    /api/v1/messages/{messageId}/thread")
+	logger.Info("  POST   /api/v1/messages/{messageId}/reactions")
+	logger.Info("  DELETE /api/v1/messages/{messageId}/reactions")
+	logger.Info("  POST   /api/v1/groups")
+	logger.Info("  GET    /api/v1/groups/{groupId}")
+	logger.Info("  PATCH  /api/v1/groups/{groupId}")
+	logger.Info("  GET    /api/v1/groups/{groupId}/members")
+	logger.Info("  POST   /api/v1/groups/{groupId}/members")
+	logger.Info("  PATCH  /api/v1/groups/{groupId}/members/{userId}")
+	logger.Info("  DELETE /api/v1/groups/{groupId}/members/{userId}")
+	logger.Info("  POST   /api/v1/groups/{groupId}/leave")
+	logger.Info("  PATCH  /api/v1/groups/{groupId}/settings")
+	logger.Info("  POST   /api/v1/groups/{groupId}/invites")
+	logger.Info("  GET    /api/v1/groups/{groupId}/invites")
+	logger.Info("  DELETE /api/v1/groups/{groupId}/invites/{inviteId}")
+	logger.Info("  POST   /api/v1/invites/{token}/join")
+	logger.Info("  GET    /api/v1/ws (WebSocket)")
 	//logger.Info("Authentication: Basic Auth with credentials from conf/config.toml")
 	logger.Info("Run the demo test to see the system in action!")
 
 	// Start server with graceful shutdown
 	bootstrap.StartServerWithGracefulShutdown(server)
+
+	// Release database resources (no-op for the in-memory store)
+	if closer, ok := store.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			logger.Error("Failed to close database: %v", err)
+		}
+	}
 }
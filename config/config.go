@@ -1,19 +1,31 @@
 package config
 
 import (
+	"crypto/rand"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/pkg/auth"
 
 	"github.com/BurntSushi/toml"
 )
 
 // Config holds the complete application configuration
 type Config struct {
-	Server   ServerConfig   `toml:"server"`
-	Auth     AuthConfig     `toml:"auth"`
-	Database DatabaseConfig `toml:"database"`
-	Logging  LoggingConfig  `toml:"logging"`
-	Features FeaturesConfig `toml:"features"`
+	Server    ServerConfig    `toml:"server"`
+	Auth      AuthConfig      `toml:"auth"`
+	Database  DatabaseConfig  `toml:"database"`
+	Logging   LoggingConfig   `toml:"logging"`
+	Features  FeaturesConfig  `toml:"features"`
+	Security  SecurityConfig  `toml:"security"`
+	RateLimit RateLimitConfig `toml:"rate_limit"`
+
+	signingKeyOnce sync.Once
+	signingKey     []byte
 }
 
 // ServerConfig holds server-related configuration
@@ -24,22 +36,46 @@ type ServerConfig struct {
 	WriteTimeout int    `toml:"write_timeout"`
 }
 
-// AuthConfig holds authentication configuration with explicit client fields
+// AuthConfig holds authentication configuration.
+// Clients are listed as [[auth.clients]] entries, as many as needed.
 type AuthConfig struct {
+	Clients         []ClientAuth `toml:"clients"`
+	AccessTokenTTL  int          `toml:"access_token_ttl"`  // Seconds an access token from /auth/login or /auth/refresh is valid
+	RefreshTokenTTL int          `toml:"refresh_token_ttl"` // Seconds a session can go without refreshing before it must log in again
+
+	// Deprecated: the fixed [auth.client1] .. [auth.client3] tables of older configs.
+	// They still load after Clients, with a warning; move them to [[auth.clients]].
 	Client1 ClientAuth `toml:"client1"`
 	Client2 ClientAuth `toml:"client2"`
 	Client3 ClientAuth `toml:"client3"`
 }
 
-// ClientAuth holds client authentication credentials
+// ClientAuth holds client authentication credentials. They are copied into the
+// store at startup, where the user can later change the login name.
 type ClientAuth struct {
-	Username string `toml:"username"`
-	Password string `toml:"password"`
+	UserID       string `toml:"user_id"`       // User the client signs in as; defaults to Username
+	Username     string `toml:"username"`      // Initial login name
+	PasswordHash string `toml:"password_hash"` // bcrypt hash from `go run ./cmd/hashpassword`
+	Password     string `toml:"password"`      // Deprecated: plaintext password, still accepted with a warning
+}
+
+// GetUserID returns the ID of the user the client signs in as
+func (c ClientAuth) GetUserID() string {
+	if c.UserID != "" {
+		return c.UserID
+	}
+	return c.Username
+}
+
+// hasSecret reports whether the client has a password or password hash set
+func (c ClientAuth) hasSecret() bool {
+	return c.PasswordHash != "" || c.Password != ""
 }
 
 // DatabaseConfig holds database-related configuration
 type DatabaseConfig struct {
-	Mode           string `toml:"mode"`
+	Mode           string `toml:"mode"` // memory or sqlite
+	Path           string `toml:"path"` // SQLite database file, used when mode = "sqlite"
 	MaxConnections int    `toml:"max_connections"`
 }
 
@@ -51,10 +87,39 @@ type LoggingConfig struct {
 
 // FeaturesConfig holds feature flags and limits
 type FeaturesConfig struct {
-	EnableSearch     bool `toml:"enable_search"`
-	EnableGroupChat  bool `toml:"enable_group_chat"`
-	MaxMessageLength int  `toml:"max_message_length"`
-	MaxGroupMembers  int  `toml:"max_group_members"`
+	EnableSearch        bool `toml:"enable_search"`
+	EnableGroupChat     bool `toml:"enable_group_chat"`
+	MaxMessageLength    int  `toml:"max_message_length"`
+	MaxGroupMembers     int  `toml:"max_group_members"`
+	MessageEditWindow   int  `toml:"message_edit_window"`   // Seconds after sending during which a message can be edited
+	MessageDeleteWindow int  `toml:"message_delete_window"` // Seconds after sending during which a message can be deleted for everyone
+}
+
+// SecurityConfig holds secrets used to sign tokens handed to clients
+type SecurityConfig struct {
+	SigningKey string `toml:"signing_key"` // HMAC key for invite links and cursors; keep it secret and stable across restarts
+}
+
+// RateLimitConfig holds request rate limits. Each limit is a token bucket per client:
+// Burst requests at once, refilled at RequestsPerMinute.
+type RateLimitConfig struct {
+	Enabled         bool                 `toml:"enabled"`
+	CleanupInterval int                  `toml:"cleanup_interval"` // Seconds between sweeps of idle limiter state
+	ClientIPHeader  string               `toml:"client_ip_header"` // Header a trusted proxy sets to the client IP; empty uses the connection's address
+	Default         RateLimit            `toml:"default"`          // Per user, shared by the routes without their own limit
+	FailedLogins    RateLimit            `toml:"failed_logins"`    // Per client IP, spent by failed Basic auth and /auth/login attempts
+	Routes          map[string]RateLimit `toml:"routes"`           // "METHOD /path/template" -> per user (per client IP on public routes)
+}
+
+// RateLimit is one token bucket. A zero RequestsPerMinute means no limit.
+type RateLimit struct {
+	RequestsPerMinute float64 `toml:"requests_per_minute"`
+	Burst             int     `toml:"burst"`
+}
+
+// IsUnlimited reports whether the limit lets every request through
+func (l RateLimit) IsUnlimited() bool {
+	return l.RequestsPerMinute == 0
 }
 
 // LoadConfig loads configuration from a TOML file
@@ -91,21 +156,18 @@ func NewConfig() *Config {
 			WriteTimeout: DefaultWriteTimeout,
 		},
 		Auth: AuthConfig{
-			Client1: ClientAuth{
-				Username: "user1",
-				Password: "password1",
-			},
-			Client2: ClientAuth{
-				Username: "user2",
-				Password: "password2",
-			},
-			Client3: ClientAuth{
-				Username: "user3",
-				Password: "password3",
+			// Demo clients; the hashes are of password1, password2 and password3
+			Clients: []ClientAuth{
+				{Username: "user1", PasswordHash: "$2a$10$Q7GXTyRe73zYhFqnsQIhNuM8D7CxF6vItKm.goJJJejOuADLcv2oi"},
+				{Username: "user2", PasswordHash: "$2a$10$dF3PSgMFT5aRbiZ1zHbrBOISNfpkW5h5zI7eJ3oFb2ADKUbSWlL4u"},
+				{Username: "user3", PasswordHash: "$2a$10$fqlvFZAzC3FRWn8tYTthuuG0M9r5tTuPdxktBCz1aSf/jxjoKJYn2"},
 			},
+			AccessTokenTTL:  DefaultAccessTokenTTL,
+			RefreshTokenTTL: DefaultRefreshTokenTTL,
 		},
 		Database: DatabaseConfig{
-			Mode:           "memory",
+			Mode:           DatabaseModeMemory,
+			Path:           DefaultDatabasePath,
 			MaxConnections: 100,
 		},
 		Logging: LoggingConfig{
@@ -113,10 +175,22 @@ func NewConfig() *Config {
 			Format: DefaultLogFormat,
 		},
 		Features: FeaturesConfig{
-			EnableSearch:     FeatureSearchEnabled,
-			EnableGroupChat:  FeatureGroupChatEnabled,
-			MaxMessageLength: DefaultMaxMessageLength,
-			MaxGroupMembers:  DefaultMaxGroupMembers,
+			EnableSearch:        FeatureSearchEnabled,
+			EnableGroupChat:     FeatureGroupChatEnabled,
+			MaxMessageLength:    DefaultMaxMessageLength,
+			MaxGroupMembers:     DefaultMaxGroupMembers,
+			MessageEditWindow:   DefaultMessageEditWindow,
+			MessageDeleteWindow: DefaultMessageDeleteWindow,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:         true,
+			CleanupInterval: DefaultRateLimitCleanupInterval,
+			Default:         RateLimit{RequestsPerMinute: DefaultRequestsPerMinute, Burst: DefaultRequestBurst},
+			FailedLogins:    RateLimit{RequestsPerMinute: DefaultFailedLoginsPerMinute, Burst: DefaultFailedLoginBurst},
+			Routes: map[string]RateLimit{
+				"POST /api/v1/sendMessage": {RequestsPerMinute: DefaultSendMessagesPerMinute, Burst: DefaultSendMessageBurst},
+				"POST /api/v1/auth/signup": {RequestsPerMinute: DefaultSignUpsPerMinute, Burst: DefaultSignUpBurst},
+			},
 		},
 	}
 }
@@ -126,50 +200,175 @@ func (c *Config) Validate() error {
 	if c.Server.Port == "" {
 		return fmt.Errorf("server.port is required")
 	}
-	// Validate at least one client has credentials
-	hasClient := false
-	if c.Auth.Client1.Username != "" && c.Auth.Client1.Password != "" {
-		hasClient = true
+	switch c.Database.Mode {
+	case "", DatabaseModeMemory:
+	case DatabaseModeSQLite:
+		if c.Database.Path == "" {
+			return fmt.Errorf("database.path is required when database.mode is %q", DatabaseModeSQLite)
+		}
+	default:
+		return fmt.Errorf("unsupported database.mode: %q", c.Database.Mode)
 	}
-	if c.Auth.Client2.Username != "" && c.Auth.Client2.Password != "" {
-		hasClient = true
+	// Validate the auth clients: at least one, each with a usable secret and a unique username
+	clients := c.GetAuthClients()
+	if len(clients) == 0 {
+		return fmt.Errorf("at least one auth client is required")
 	}
-	if c.Auth.Client3.Username != "" && c.Auth.Client3.Password != "" {
-		hasClient = true
+	seenNames := make(map[string]bool, len(clients))
+	seenUsers := make(map[string]bool, len(clients))
+	for _, client := range clients {
+		if seenNames[client.Username] {
+			return fmt.Errorf("auth client %q is listed more than once", client.Username)
+		}
+		if seenUsers[client.GetUserID()] {
+			return fmt.Errorf("auth client %q signs in as user %q, which another client already does", client.Username, client.GetUserID())
+		}
+		seenNames[client.Username] = true
+		seenUsers[client.GetUserID()] = true
+		if !database.IsValidUserID(client.GetUserID()) {
+			return fmt.Errorf("auth client %q signs in as user %q; user IDs cannot be empty or contain %q", client.Username, client.GetUserID(), database.OneToOneConversationSeparator)
+		}
+		if client.PasswordHash != "" && client.Password != "" {
+			return fmt.Errorf("auth client %q sets both password and password_hash", client.Username)
+		}
+		if client.PasswordHash != "" && !auth.IsPasswordHash(client.PasswordHash) {
+			return fmt.Errorf("auth client %q has an invalid password_hash", client.Username)
+		}
 	}
-	if !hasClient {
-		return fmt.Errorf("at least one auth client is required")
+	if c.RateLimit.Enabled {
+		if err := c.RateLimit.Default.validate("rate_limit.default"); err != nil {
+			return err
+		}
+		if err := c.RateLimit.FailedLogins.validate("rate_limit.failed_logins"); err != nil {
+			return err
+		}
+		for route, limit := range c.RateLimit.Routes {
+			method, path, _ := strings.Cut(route, " ")
+			if !validRouteMethods[method] || !strings.HasPrefix(path, "/") {
+				return fmt.Errorf("rate_limit.routes: %q must look like \"POST /api/v1/sendMessage\"", route)
+			}
+			if err := limit.validate(fmt.Sprintf("rate_limit.routes.%q", route)); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
 
-// GetAuthClients returns all authentication clients as a slice for iteration
-func (c *Config) GetAuthClients() []ClientAuth {
-	clients := []ClientAuth{}
-	if c.Auth.Client1.Username != "" && c.Auth.Client1.Password != "" {
-		clients = append(clients, c.Auth.Client1)
+// validRouteMethods are the methods rate_limit.routes can name
+var validRouteMethods = map[string]bool{"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true}
+
+func (l RateLimit) validate(name string) error {
+	if l.RequestsPerMinute < 0 {
+		return fmt.Errorf("%s.requests_per_minute cannot be negative", name)
 	}
-	if c.Auth.Client2.Username != "" && c.Auth.Client2.Password != "" {
-		clients = append(clients, c.Auth.Client2)
+	if !l.IsUnlimited() && l.Burst < 1 {
+		return fmt.Errorf("%s.burst must be at least 1", name)
 	}
-	if c.Auth.Client3.Username != "" && c.Auth.Client3.Password != "" {
-		clients = append(clients, c.Auth.Client3)
+	return nil
+}
+
+// GetAuthClients returns all authentication clients with a username and a secret:
+// the [[auth.clients]] entries, then any deprecated [auth.clientN] tables
+func (c *Config) GetAuthClients() []ClientAuth {
+	clients := make([]ClientAuth, 0, len(c.Auth.Clients)+3)
+	for _, client := range append(append([]ClientAuth{}, c.Auth.Clients...), c.legacyClients()...) {
+		if client.Username != "" && client.hasSecret() {
+			clients = append(clients, client)
+		}
 	}
 	return clients
 }
 
-// ValidateCredentials checks if the provided username and password match any client
-func (c *Config) ValidateCredentials(username, password string) bool {
-	clients := c.GetAuthClients()
-	for _, client := range clients {
-		if client.Username == username && client.Password == password {
-			return true
+// legacyClients returns the deprecated [auth.client1] .. [auth.client3] tables that are set
+func (c *Config) legacyClients() []ClientAuth {
+	clients := make([]ClientAuth, 0, 3)
+	for _, client := range []ClientAuth{c.Auth.Client1, c.Auth.Client2, c.Auth.Client3} {
+		if client.Username != "" || client.hasSecret() {
+			clients = append(clients, client)
 		}
 	}
-	return false
+	return clients
+}
+
+// DeprecationWarnings describes the settings that still load but should be updated
+func (c *Config) DeprecationWarnings() []string {
+	warnings := make([]string, 0)
+	if len(c.legacyClients()) > 0 {
+		warnings = append(warnings, "auth: [auth.client1] .. [auth.client3] tables are deprecated; list clients as [[auth.clients]] entries")
+	}
+	for _, client := range c.GetAuthClients() {
+		if client.Password != "" {
+			warnings = append(warnings, fmt.Sprintf("auth: client %q has a plaintext password; replace it with a password_hash from `go run ./cmd/hashpassword`", client.Username))
+		}
+	}
+	return warnings
 }
 
 // GetPort returns the server port
 func (c *Config) GetPort() string {
 	return c.Server.Port
 }
+
+// GetMessageEditWindow returns how long after sending a message can be edited
+func (c *Config) GetMessageEditWindow() time.Duration {
+	if c.Features.MessageEditWindow <= 0 {
+		return DefaultMessageEditWindow * time.Second
+	}
+	return time.Duration(c.Features.MessageEditWindow) * time.Second
+}
+
+// GetMessageDeleteWindow returns how long after sending a message can be deleted for everyone
+func (c *Config) GetMessageDeleteWindow() time.Duration {
+	if c.Features.MessageDeleteWindow <= 0 {
+		return DefaultMessageDeleteWindow * time.Second
+	}
+	return time.Duration(c.Features.MessageDeleteWindow) * time.Second
+}
+
+// GetAccessTokenTTL returns how long an access token is valid
+func (c *Config) GetAccessTokenTTL() time.Duration {
+	if c.Auth.AccessTokenTTL <= 0 {
+		return DefaultAccessTokenTTL * time.Second
+	}
+	return time.Duration(c.Auth.AccessTokenTTL) * time.Second
+}
+
+// GetRefreshTokenTTL returns how long a refresh token is valid
+func (c *Config) GetRefreshTokenTTL() time.Duration {
+	if c.Auth.RefreshTokenTTL <= 0 {
+		return DefaultRefreshTokenTTL * time.Second
+	}
+	return time.Duration(c.Auth.RefreshTokenTTL) * time.Second
+}
+
+// GetRateLimitCleanupInterval returns how often idle rate limiter state is swept away
+func (c *Config) GetRateLimitCleanupInterval() time.Duration {
+	if c.RateLimit.CleanupInterval <= 0 {
+		return DefaultRateLimitCleanupInterval * time.Second
+	}
+	return time.Duration(c.RateLimit.CleanupInterval) * time.Second
+}
+
+// GetMaxGroupMembers returns the largest number of members a group can have
+func (c *Config) GetMaxGroupMembers() int {
+	if c.Features.MaxGroupMembers <= 0 {
+		return DefaultMaxGroupMembers
+	}
+	return c.Features.MaxGroupMembers
+}
+
+// GetSigningKey returns the key for signed tokens such as invite links and cursors.
+// Without a configured key a random one is generated on first use, so tokens
+// issued before a restart stop working.
+func (c *Config) GetSigningKey() []byte {
+	c.signingKeyOnce.Do(func() {
+		if c.Security.SigningKey != "" {
+			c.signingKey = []byte(c.Security.SigningKey)
+			return
+		}
+		c.signingKey = make([]byte, DefaultSigningKeyBytes)
+		rand.Read(c.signingKey)
+	})
+	return c.signingKey
+}
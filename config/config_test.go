@@ -0,0 +1,37 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateClientUserIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  ClientAuth
+		wantErr string
+	}{
+		{"plain user ID", ClientAuth{Username: "dave", UserID: "user4"}, ""},
+		{"user ID from the username", ClientAuth{Username: "dave"}, ""},
+		{"separator in the user ID", ClientAuth{Username: "dave", UserID: "user:4"}, `signs in as user "user:4"`},
+		{"separator in the username it defaults to", ClientAuth{Username: "dave:4"}, `signs in as user "dave:4"`},
+		{"separator only in the username", ClientAuth{Username: "dave:4", UserID: "user4"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig()
+			tt.client.Password = "password4"
+			cfg.Auth.Clients = append(cfg.Auth.Clients, tt.client)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("got %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v, want an error containing %s", err, tt.wantErr)
+			}
+		})
+	}
+}
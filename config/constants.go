@@ -11,6 +11,13 @@ const (
 	DefaultConfigPath   = "conf/config.toml"
 )
 
+// Database modes
+const (
+	DatabaseModeMemory  = "memory"
+	DatabaseModeSQLite  = "sqlite"
+	DefaultDatabasePath = "data/chat.db"
+)
+
 // Environment variable names
 const (
 	EnvConfigPath = "CONFIG_PATH"
@@ -24,6 +31,32 @@ const (
 
 // Limits
 const (
-	DefaultMaxMessageLength = 10000
-	DefaultMaxGroupMembers  = 100
+	DefaultMaxMessageLength    = 10000
+	DefaultMaxGroupMembers     = 100
+	DefaultMessageEditWindow   = 15 * 60 // 15 minutes, in seconds
+	DefaultMessageDeleteWindow = 60 * 60 // 1 hour, in seconds
+)
+
+// Security
+const (
+	DefaultSigningKeyBytes = 32 // Size of the random key used when security.signing_key is unset
+)
+
+// Rate limits
+const (
+	DefaultRateLimitCleanupInterval = 5 * 60 // 5 minutes, in seconds
+	DefaultRequestsPerMinute        = 600
+	DefaultRequestBurst             = 100
+	DefaultFailedLoginsPerMinute    = 5
+	DefaultFailedLoginBurst         = 10
+	DefaultSendMessagesPerMinute    = 120
+	DefaultSendMessageBurst         = 30
+	DefaultSignUpsPerMinute         = 2
+	DefaultSignUpBurst              = 5
+)
+
+// Sessions
+const (
+	DefaultAccessTokenTTL  = 15 * 60           // 15 minutes, in seconds
+	DefaultRefreshTokenTTL = 30 * 24 * 60 * 60 // 30 days, in seconds
 )
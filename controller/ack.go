@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/utils"
+)
+
+// AckResult is the outcome of acknowledging one message of a batch
+type AckResult struct {
+	MessageID    string           `json:"message_id"`
+	Acknowledged bool             `json:"acknowledged"`
+	Error        *errors.AppError `json:"error,omitempty"`
+}
+
+// AckBatchResponse represents the response to a batch acknowledgement.
+// Results are in request order; a failed ID does not fail the others.
+type AckBatchResponse struct {
+	Results      []AckResult `json:"results"`
+	Acknowledged int         `json:"acknowledged"`
+	Failed       int         `json:"failed"`
+}
+
+// newAckBatchResponse pairs each requested message ID with its outcome
+func newAckBatchResponse(messageIDs []string, outcomes []*errors.AppError) AckBatchResponse {
+	response := AckBatchResponse{Results: make([]AckResult, len(messageIDs))}
+	for i, messageID := range messageIDs {
+		response.Results[i] = AckResult{MessageID: messageID, Acknowledged: outcomes[i] == nil, Error: outcomes[i]}
+		if outcomes[i] == nil {
+			response.Acknowledged++
+		} else {
+			response.Failed++
+		}
+	}
+	return response
+}
+
+// validateAckBatch checks that a batch acknowledgement holds between 1 and MaxAckBatchSize IDs
+func validateAckBatch(messageIDs []string) *errors.AppError {
+	if len(messageIDs) == 0 {
+		return errors.ErrInvalidRequest
+	}
+	if len(messageIDs) > MaxAckBatchSize {
+		return errors.ErrAckBatchTooLarge
+	}
+	return nil
+}
+
+// ackRecipientMessages applies the recipient check to every message of an acknowledgement.
+// It returns the IDs that passed, once each, and an outcome per requested position:
+// nil when the ID passed, otherwise why it was rejected.
+func (h *Handler) ackRecipientMessages(userID string, messageIDs []string) ([]string, []*errors.AppError) {
+	outcomes := make([]*errors.AppError, len(messageIDs))
+
+	messages, err := h.store.GetMessagesByID(utils.RemoveDuplicates(messageIDs))
+	if err != nil {
+		for i := range outcomes {
+			outcomes[i] = errors.ErrInternalError
+		}
+		return nil, outcomes
+	}
+
+	// Membership is looked up once per group, however many of its messages are acknowledged
+	groups := make(map[string]*ackGroupAccess)
+	accepted := make([]string, 0, len(messages))
+	seen := make(map[string]bool, len(messages))
+	for i, messageID := range messageIDs {
+		message, exists := messages[messageID]
+		if !exists {
+			outcomes[i] = errors.ErrMessageNotFound
+			continue
+		}
+		if appErr := h.ackRecipientError(message, userID, groups); appErr != nil {
+			outcomes[i] = appErr
+			continue
+		}
+		if !seen[messageID] {
+			seen[messageID] = true
+			accepted = append(accepted, messageID)
+		}
+	}
+	return accepted, outcomes
+}
+
+// ackGroupAccess is what the recipient check knows about a user in one group
+type ackGroupAccess struct {
+	member      *database.GroupMember // nil when the user is not a member
+	sinceJoined bool                  // The group hides messages from before member.JoinedAt
+}
+
+// ackRecipientError checks that userID is a recipient of message and can see it, with
+// group access cached across a batch. As in authorizeMessage, messages sent before a
+// member joined a group that only shows history since joining are reported as missing.
+func (h *Handler) ackRecipientError(message *database.Message, userID string, groups map[string]*ackGroupAccess) *errors.AppError {
+	if message.ConversationType == database.ConversationTypeOneToOne {
+		if message.DestinationID != userID {
+			return errors.ErrNotMessageRecipient
+		}
+		return nil
+	}
+
+	access, cached := groups[message.DestinationID]
+	if !cached {
+		access = &ackGroupAccess{}
+		if member, err := h.store.GetGroupMember(message.DestinationID, userID); err == nil {
+			access.member = member
+			group, err := h.store.GetGroup(message.DestinationID)
+			access.sinceJoined = err == nil && group.HistoryVisibility == database.HistoryVisibilitySinceJoined
+		}
+		groups[message.DestinationID] = access
+	}
+	if access.member == nil {
+		return errors.ErrNotMessageRecipient
+	}
+	if access.sinceJoined && message.CreatedAt.Before(access.member.JoinedAt) {
+		return errors.ErrMessageNotFound
+	}
+	return nil
+}
+
+// failAccepted marks every position that passed the recipient check as failed with appErr
+func failAccepted(outcomes []*errors.AppError, appErr *errors.AppError) {
+	for i := range outcomes {
+		if outcomes[i] == nil {
+			outcomes[i] = appErr
+		}
+	}
+}
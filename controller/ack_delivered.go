@@ -4,15 +4,16 @@ import (
 	"encoding/json"
 	"net/http"
 
-	"github.com/kasasunil/chat_app/database"
 	"github.com/kasasunil/chat_app/internal/middleware"
 	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
 )
 
 // AckDeliveredRequest represents the request to acknowledge delivery
 type AckDeliveredRequest struct {
-	UserID    string `json:"user_id"`
-	MessageID string `json:"message_id"`
+	UserID     string   `json:"user_id"`
+	MessageID  string   `json:"message_id"`
+	MessageIDs []string `json:"message_ids"` // Batch form; replaces message_id
 }
 
 // AckDeliveredResponse represents the response after acknowledging delivery
@@ -45,31 +46,27 @@ func (h *Handler) AckDelivered(w http.ResponseWriter, r *http.Request) {
 		userID = req.UserID
 	}
 
-	message, err := h.store.GetMessage(req.MessageID)
-	if err != nil {
-		respondWithError(w, errors.ErrMessageNotFound)
-		return
-	}
-
-	// Verify user is the recipient
-	isRecipient := false
-	if message.ConversationType == database.ConversationTypeOneToOne {
-		isRecipient = message.DestinationID == userID
-	} else {
-		isRecipient = h.store.IsGroupMember(message.DestinationID, userID)
-	}
+	// A batch reports per-ID results; one bad ID does not fail the others
+	if req.MessageIDs != nil {
+		if req.MessageID != "" {
+			respondWithError(w, errors.ErrInvalidRequest)
+			return
+		}
+		outcomes, appErr := h.ProcessAckDeliveredBatch(userID, req.MessageIDs)
+		if appErr != nil {
+			respondWithError(w, appErr)
+			return
+		}
 
-	if !isRecipient {
-		respondWithError(w, errors.ErrNotMessageRecipient)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK) // 200 OK
+		json.NewEncoder(w).Encode(newAckBatchResponse(req.MessageIDs, outcomes))
 		return
 	}
 
-	// Update message status to DELIVERED
-	if message.Status == database.StatusSent {
-		if err := h.store.UpdateMessageStatus(req.MessageID, database.StatusDelivered); err != nil {
-			respondWithError(w, errors.ErrInternalError)
-			return
-		}
+	if appErr := h.ProcessAckDelivered(userID, req.MessageID); appErr != nil {
+		respondWithError(w, appErr)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -78,3 +75,41 @@ func (h *Handler) AckDelivered(w http.ResponseWriter, r *http.Request) {
 		Message: "Message delivered",
 	})
 }
+
+// ProcessAckDelivered marks a message delivered to userID after verifying the
+// user is a recipient, then notifies the sender's open connections.
+// Shared by the HTTP handler and the WebSocket transport.
+func (h *Handler) ProcessAckDelivered(userID, messageID string) *errors.AppError {
+	outcomes, appErr := h.ProcessAckDeliveredBatch(userID, []string{messageID})
+	if appErr != nil {
+		return appErr
+	}
+	return outcomes[0]
+}
+
+// ProcessAckDeliveredBatch marks several messages delivered to userID in one store
+// operation. Each ID is checked on its own; the returned outcomes are in request order,
+// nil for each acknowledged ID. The second result rejects the batch as a whole.
+func (h *Handler) ProcessAckDeliveredBatch(userID string, messageIDs []string) ([]*errors.AppError, *errors.AppError) {
+	if appErr := validateAckBatch(messageIDs); appErr != nil {
+		return nil, appErr
+	}
+
+	accepted, outcomes := h.ackRecipientMessages(userID, messageIDs)
+	if len(accepted) == 0 {
+		return outcomes, nil
+	}
+
+	// Record delivery for this recipient; the store moves each message to
+	// DELIVERED once every recipient has it
+	if err := h.store.CreateMessageDeliveries(accepted, userID); err != nil {
+		failAccepted(outcomes, errors.ErrInternalError)
+		return outcomes, nil
+	}
+
+	for _, messageID := range accepted {
+		logger.Info(logger.TraceMessageDelivered, messageID, userID)
+		h.wsManager.AckDelivered(userID, messageID)
+	}
+	return outcomes, nil
+}
@@ -3,8 +3,8 @@ package controller
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 
-	"github.com/kasasunil/chat_app/database"
 	"github.com/kasasunil/chat_app/internal/middleware"
 	"github.com/kasasunil/chat_app/internal/pkg/errors"
 	"github.com/kasasunil/chat_app/internal/pkg/logger"
@@ -12,8 +12,9 @@ import (
 
 // AckReadRequest represents the request to acknowledge read
 type AckReadRequest struct {
-	UserID    string `json:"user_id"`
-	MessageID string `json:"message_id"`
+	UserID     string   `json:"user_id"`
+	MessageID  string   `json:"message_id"`
+	MessageIDs []string `json:"message_ids"` // Batch form; replaces message_id
 }
 
 // AckReadResponse represents the response after acknowledging read
@@ -46,36 +47,70 @@ func (h *Handler) AckRead(w http.ResponseWriter, r *http.Request) {
 		userID = req.UserID
 	}
 
-	message, err := h.store.GetMessage(req.MessageID)
-	if err != nil {
-		respondWithError(w, errors.ErrMessageNotFound)
-		return
-	}
-
-	// Verify user is the recipient
-	isRecipient := false
-	if message.ConversationType == database.ConversationTypeOneToOne {
-		isRecipient = message.DestinationID == userID
-	} else {
-		isRecipient = h.store.IsGroupMember(message.DestinationID, userID)
-	}
+	// A batch reports per-ID results; one bad ID does not fail the others
+	if req.MessageIDs != nil {
+		if req.MessageID != "" {
+			respondWithError(w, errors.ErrInvalidRequest)
+			return
+		}
+		outcomes, appErr := h.ProcessAckReadBatch(userID, req.MessageIDs)
+		if appErr != nil {
+			respondWithError(w, appErr)
+			return
+		}
 
-	if !isRecipient {
-		respondWithError(w, errors.ErrNotMessageRecipient)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK) // 200 OK
+		json.NewEncoder(w).Encode(newAckBatchResponse(req.MessageIDs, outcomes))
 		return
 	}
 
-	// Create read receipt
-	if err := h.store.CreateMessageRead(req.MessageID, userID); err != nil {
-		logger.Error(logger.TraceMessageReadFailed, req.MessageID, userID, err)
-		respondWithError(w, errors.ErrInternalError)
+	if appErr := h.ProcessAckRead(userID, req.MessageID); appErr != nil {
+		respondWithError(w, appErr)
 		return
 	}
 
-	logger.Info(logger.TraceMessageRead, req.MessageID, userID)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(AckReadResponse{
 		Message: "Message read",
 	})
 }
+
+// ProcessAckRead records a read receipt for userID after verifying the user
+// is a recipient, then notifies the sender's open connections.
+// Shared by the HTTP handler and the WebSocket transport.
+func (h *Handler) ProcessAckRead(userID, messageID string) *errors.AppError {
+	outcomes, appErr := h.ProcessAckReadBatch(userID, []string{messageID})
+	if appErr != nil {
+		return appErr
+	}
+	return outcomes[0]
+}
+
+// ProcessAckReadBatch records read receipts for several messages in one store
+// operation. Each ID is checked on its own; the returned outcomes are in request order,
+// nil for each acknowledged ID. The second result rejects the batch as a whole.
+func (h *Handler) ProcessAckReadBatch(userID string, messageIDs []string) ([]*errors.AppError, *errors.AppError) {
+	if appErr := validateAckBatch(messageIDs); appErr != nil {
+		return nil, appErr
+	}
+
+	accepted, outcomes := h.ackRecipientMessages(userID, messageIDs)
+	if len(accepted) == 0 {
+		return outcomes, nil
+	}
+
+	// Create read receipts
+	if err := h.store.CreateMessageReads(accepted, userID); err != nil {
+		logger.Error(logger.TraceMessageReadFailed, strings.Join(accepted, ","), userID, err)
+		failAccepted(outcomes, errors.ErrInternalError)
+		return outcomes, nil
+	}
+
+	for _, messageID := range accepted {
+		logger.Info(logger.TraceMessageRead, messageID, userID)
+		h.wsManager.AckRead(userID, messageID)
+	}
+	return outcomes, nil
+}
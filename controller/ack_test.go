@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+)
+
+func TestAckHidesMessagesFromBeforeJoining(t *testing.T) {
+	s := newTestServer(t)
+	before, after := newLateJoinerGroup(t, s)
+	authorization := basicAuthFor("user3")
+
+	s.do(t, MethodPOST, EndpointAckDelivered, authorization, AckDeliveredRequest{MessageID: before}, nil, errors.ErrMessageNotFound)
+	s.do(t, MethodPOST, EndpointAckRead, authorization, AckReadRequest{MessageID: before}, nil, errors.ErrMessageNotFound)
+
+	var response AckBatchResponse
+	s.do(t, MethodPOST, EndpointAckRead, authorization, AckReadRequest{MessageIDs: []string{before, after}}, &response, nil)
+	if len(response.Results) != 2 || response.Results[0].Error == nil || response.Results[0].Error.Code != errors.ErrMessageNotFound.Code || !response.Results[1].Acknowledged {
+		t.Errorf("got results %+v, want the earlier message not found and the later one read", response.Results)
+	}
+	if reads := s.store.GetMessageReads(before); len(reads) != 0 {
+		t.Errorf("the hidden message has %d read receipts, want none", len(reads))
+	}
+
+	// Members who were there when it was sent still acknowledge it
+	s.do(t, MethodPOST, EndpointAckRead, basicAuthFor("user2"), AckReadRequest{MessageID: before}, nil, nil)
+}
+
+func TestAckReadBatchReportsEachPosition(t *testing.T) {
+	s := newTestServer(t)
+	good := s.send(t, basicAuthFor("user1"), "user2", "for bob")
+	foreign := s.send(t, basicAuthFor("user1"), "user3", "for carol")
+
+	messageIDs := []string{good, "missing", foreign, good}
+	wantErrors := []*errors.AppError{nil, errors.ErrMessageNotFound, errors.ErrNotMessageRecipient, nil}
+
+	var response AckBatchResponse
+	s.do(t, MethodPOST, EndpointAckRead, basicAuthFor("user2"), AckReadRequest{MessageIDs: messageIDs}, &response, nil)
+	if len(response.Results) != len(messageIDs) {
+		t.Fatalf("got %d results, want %d", len(response.Results), len(messageIDs))
+	}
+	for i, want := range wantErrors {
+		result := response.Results[i]
+		if result.MessageID != messageIDs[i] {
+			t.Errorf("result %d is for %q, want %q", i, result.MessageID, messageIDs[i])
+		}
+		switch {
+		case want == nil && (!result.Acknowledged || result.Error != nil):
+			t.Errorf("result %d is %+v, want acknowledged", i, result)
+		case want != nil && (result.Acknowledged || result.Error == nil || result.Error.Code != want.Code):
+			t.Errorf("result %d is %+v, want %s", i, result, want.Code)
+		}
+	}
+	if response.Acknowledged != 2 || response.Failed != 2 {
+		t.Errorf("got %d acknowledged and %d failed, want 2 and 2", response.Acknowledged, response.Failed)
+	}
+	if reads := s.store.GetMessageReads(good); len(reads) != 1 {
+		t.Errorf("the repeated message has %d read receipts, want 1", len(reads))
+	}
+	if reads := s.store.GetMessageReads(foreign); len(reads) != 0 {
+		t.Errorf("the foreign message has %d read receipts, want none", len(reads))
+	}
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+	"github.com/kasasunil/chat_app/internal/pkg/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// AddGroupMembersRequest represents the request to add members to a group
+type AddGroupMembersRequest struct {
+	UserIDs []string `json:"user_ids"`
+}
+
+// AddGroupMembers handles POST /groups/{groupId}/members
+// Only admins can add members; new members get the member role.
+// Users who are already members are skipped.
+func (h *Handler) AddGroupMembers(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user from context
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	if !h.config.Features.EnableGroupChat {
+		respondWithError(w, errors.ErrGroupChatDisabled)
+		return
+	}
+
+	var req AddGroupMembersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.UserIDs) == 0 {
+		respondWithError(w, errors.ErrInvalidRequest)
+		return
+	}
+
+	vars := mux.Vars(r)
+	group, _, appErr := h.adminGroup(vars["groupId"], authenticatedUserID)
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	userIDs := utils.RemoveDuplicates(req.UserIDs)
+	if appErr := h.requireUsers(userIDs); appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	// The store skips existing members and enforces the limit for the whole batch at once
+	added, err := h.store.AddGroupMembers(group.ID, userIDs, database.GroupRoleMember, h.config.GetMaxGroupMembers())
+	if err != nil {
+		switch err.Error() {
+		case database.ErrGroupFull:
+			respondWithError(w, errors.ErrGroupMemberLimit)
+		case database.ErrGroupNotFound:
+			respondWithError(w, errors.ErrGroupNotFound)
+		default:
+			respondWithError(w, errors.ErrInternalError)
+		}
+		return
+	}
+	for _, userID := range added {
+		logger.Info(logger.TraceGroupMemberAdded, group.ID, userID)
+	}
+
+	if len(added) > 0 {
+		h.postSystemMessage(group.ID, database.SystemMemberAdded, &database.SystemPayload{
+			ActorID: authenticatedUserID,
+			UserIDs: added,
+		}, fmt.Sprintf(SysMsgMemberAdded, authenticatedUserID, strings.Join(added, ", ")))
+	}
+
+	response, appErr := h.groupResponse(group)
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(response)
+}
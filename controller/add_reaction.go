@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+
+	"github.com/gorilla/mux"
+)
+
+// AddReaction handles POST /messages/{messageId}/reactions
+// Adds the caller's reaction; adding the same emoji twice is a no-op.
+func (h *Handler) AddReaction(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user from context
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	var req ReactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, errors.ErrInvalidRequest)
+		return
+	}
+
+	if !isValidReaction(req.Emoji) {
+		logger.Warn(logger.TraceValidationFailed, FieldEmoji, "invalid")
+		respondWithError(w, errors.ErrInvalidReaction)
+		return
+	}
+
+	vars := mux.Vars(r)
+	message, err := h.store.GetMessage(vars["messageId"])
+	if err != nil {
+		respondWithError(w, errors.ErrMessageNotFound)
+		return
+	}
+
+	// Only participants of the message's conversation may react
+	if appErr := h.authorizeMessage(authenticatedUserID, message); appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	// The store checks again, as the message may be deleted for everyone in between
+	if message.DeletedAt != nil {
+		respondWithError(w, errors.ErrMessageDeleted)
+		return
+	}
+
+	if err := h.store.AddReaction(message.ID, authenticatedUserID, req.Emoji); err != nil {
+		switch err.Error() {
+		case database.ErrMessageDeleted:
+			respondWithError(w, errors.ErrMessageDeleted)
+		case database.ErrMessageNotFound:
+			respondWithError(w, errors.ErrMessageNotFound)
+		default:
+			logger.Error("Failed to add reaction: message=%s, error=%v", message.ID, err)
+			respondWithError(w, errors.ErrInternalError)
+		}
+		return
+	}
+
+	logger.Info(logger.TraceReactionAdded, message.ID, authenticatedUserID, req.Emoji)
+
+	reactions, err := h.reactionSummary(message.ID, authenticatedUserID)
+	if err != nil {
+		respondWithError(w, errors.ErrInternalError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK - repeated adds are no-ops, so nothing new may have been created
+	json.NewEncoder(w).Encode(ReactionResponse{
+		MessageID: message.ID,
+		Reactions: reactions,
+	})
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kasasunil/chat_app/config"
+	in_memory "github.com/kasasunil/chat_app/database/in-memory"
+	"github.com/kasasunil/chat_app/database/repositorytest"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+	"github.com/kasasunil/chat_app/internal/services/websocket"
+)
+
+// benchUser receives every message; benchConversations one-to-one chats are seeded for them
+const (
+	benchUser          = "user1"
+	benchConversations = 10
+)
+
+// Messages per conversation in the chat list
+var benchHistories = []int{100, 1000, 10000}
+
+// BenchmarkChatList serves GET /users/{userId}/conversations as every conversation
+// in it grows; the cost should not depend on the history length
+func BenchmarkChatList(b *testing.B) {
+	logger.InitLogger("error", "")
+	for _, length := range benchHistories {
+		store := in_memory.NewStore()
+		repositorytest.SeedInbox(b, store, benchUser, benchConversations, length, length/2)
+		handler := NewHandler(config.NewConfig(), store, websocket.NewHub(store))
+
+		serve := func() *httptest.ResponseRecorder {
+			r := httptest.NewRequest(MethodGET, "/api/v1/users/"+benchUser+"/conversations", nil)
+			r = r.WithContext(context.WithValue(r.Context(), middleware.UserIDKey, benchUser))
+			w := httptest.NewRecorder()
+			handler.GetUserConversations(w, mux.SetURLVars(r, map[string]string{"userId": benchUser}))
+			return w
+		}
+
+		var response GetUserConversationsResponse
+		if w := serve(); w.Code != http.StatusOK {
+			b.Fatalf("chat list returned %d: %s", w.Code, w.Body.String())
+		} else {
+			json.NewDecoder(w.Body).Decode(&response)
+		}
+		unread := 0
+		for _, conversation := range response.Conversations {
+			unread += conversation.UnreadCount
+		}
+		if want := benchConversations * (length - length/2); unread != want {
+			b.Fatalf("unread count is %d, want %d", unread, want)
+		}
+
+		b.Run(fmt.Sprintf("history=%d", length), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				serve()
+			}
+		})
+	}
+}
@@ -2,12 +2,36 @@ package controller
 
 // API endpoint paths
 const (
+	EndpointLogin                = "/api/v1/auth/login"
+	EndpointRefreshToken         = "/api/v1/auth/refresh"
+	EndpointLogout               = "/api/v1/auth/logout"
+	EndpointSignUp               = "/api/v1/auth/signup"
+	EndpointMe                   = "/api/v1/users/me"
+	EndpointGetUserProfile       = "/api/v1/users/{userId}"
+	EndpointRenameLogin          = "/api/v1/users/me/login-name"
 	EndpointSendMessage          = "/api/v1/sendMessage"
 	EndpointAckDelivered         = "/api/v1/ack/delivered"
 	EndpointAckRead              = "/api/v1/ack/read"
 	EndpointGetMessages          = "/api/v1/conversations/{destinationId}/messages"
+	EndpointMarkConversationRead = "/api/v1/conversations/{destinationId}/read"
 	EndpointGetUserConversations = "/api/v1/users/{userId}/conversations"
 	EndpointSearchMessages       = "/api/v1/search/{userId}"
+	EndpointGetMessageReceipts   = "/api/v1/messages/{messageId}/receipts"
+	EndpointEditMessage          = "/api/v1/messages/{messageId}"
+	EndpointDeleteMessage        = "/api/v1/messages/{messageId}"
+	EndpointGetMessageHistory    = "/api/v1/messages/{messageId}/history"
+	EndpointGetThreadMessages    = "/api/v1/messages/{messageId}/thread"
+	EndpointMessageReactions     = "/api/v1/messages/{messageId}/reactions"
+	EndpointCreateGroup          = "/api/v1/groups"
+	EndpointGroup                = "/api/v1/groups/{groupId}"
+	EndpointGroupMembers         = "/api/v1/groups/{groupId}/members"
+	EndpointGroupMember          = "/api/v1/groups/{groupId}/members/{userId}"
+	EndpointLeaveGroup           = "/api/v1/groups/{groupId}/leave"
+	EndpointGroupSettings        = "/api/v1/groups/{groupId}/settings"
+	EndpointGroupInvites         = "/api/v1/groups/{groupId}/invites"
+	EndpointGroupInvite          = "/api/v1/groups/{groupId}/invites/{inviteId}"
+	EndpointJoinInvite           = "/api/v1/invites/{token}/join"
+	EndpointWebSocket            = "/api/v1/ws"
 	EndpointHealth               = "/health"
 )
 
@@ -26,6 +50,7 @@ const (
 	MethodGET    = "GET"
 	MethodPOST   = "POST"
 	MethodPUT    = "PUT"
+	MethodPATCH  = "PATCH"
 	MethodDELETE = "DELETE"
 )
 
@@ -35,6 +60,18 @@ const (
 	MaxMessageLimit          = 100
 	DefaultConversationLimit = 50
 	MaxConversationLimit     = 100
+	MaxReactionLength        = 32 // Bytes; room for multi-codepoint emoji such as flags and skin tones
+	MaxGroupNameLength       = 100
+	MaxGroupDescLength       = 500
+	MaxAckBatchSize          = 500 // Message IDs per batch acknowledgement
+	MinLoginNameLength       = 3
+	MaxLoginNameLength       = 32
+	MinPasswordLength        = 8
+	MaxPasswordLength        = 72 // Bytes; bcrypt ignores the rest
+	MaxEmailLength           = 254
+	MaxDisplayNameLength     = 64  // Characters
+	MaxStatusTextLength      = 140 // Characters
+	MaxAvatarRefLength       = 512
 )
 
 // Request field names
@@ -47,11 +84,46 @@ const (
 	FieldQuery         = "query"
 	FieldCursor        = "cursor"
 	FieldLimit         = "limit"
+	FieldScope         = "scope"
+	FieldEmoji         = "emoji"
+	FieldGroupName     = "name"
+	FieldRole          = "role"
+	FieldExpiresAt     = "expires_at"
+	FieldMaxUses       = "max_uses"
+	FieldVisibility    = "history_visibility"
+	FieldLoginName     = "login_name"
+	FieldPassword      = "password"
+	FieldEmail         = "email"
+	FieldName          = "name"
+	FieldStatusText    = "status_text"
+	FieldAvatarRef     = "avatar_ref"
+)
+
+// Message deletion scopes
+const (
+	DeleteScopeMe       = "me"       // Hide the message from the caller only
+	DeleteScopeEveryone = "everyone" // Replace the message with a tombstone for all participants
 )
 
 // Response messages
 const (
 	MsgDeliveryAcknowledged = "Delivery acknowledged"
 	MsgReadAcknowledged     = "Read acknowledged"
+	MsgMessageDeleted       = "Message deleted"
+	MsgGroupMemberRemoved   = "Member removed"
+	MsgGroupLeft            = "Left group"
 	MsgHealthOK             = "OK"
 )
+
+// System message texts for group events
+const (
+	SysMsgMemberAdded         = "%s added %s"
+	SysMsgMemberJoined        = "%s joined using an invite link"
+	SysMsgMemberLeft          = "%s left"
+	SysMsgMemberRemoved       = "%s removed %s"
+	SysMsgGroupRenamed        = "%s renamed the group to \"%s\""
+	SysMsgAnnouncementOnlyOn  = "%s allowed only admins to send messages"
+	SysMsgAnnouncementOnlyOff = "%s allowed all members to send messages"
+	SysMsgHistoryFull         = "%s let new members see earlier messages"
+	SysMsgHistorySinceJoined  = "%s hid earlier messages from new members"
+)
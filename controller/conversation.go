@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/utils"
+)
+
+// resolveConversationID maps a conversation path value to the stored conversation key.
+// It accepts a group ID, a canonical one-to-one key ("dm:<a>:<b>"), or - for existing
+// clients - the other participant's user ID, which is paired with the caller.
+func (h *Handler) resolveConversationID(userID, id string) string {
+	if database.IsOneToOneConversationID(id) {
+		return id
+	}
+	if _, err := h.store.GetGroup(id); err == nil {
+		return id
+	}
+	return database.OneToOneConversationID(userID, id)
+}
+
+// authorizeConversation verifies that userID is a participant of conversationID.
+// Group conversations require membership, one-to-one conversations require
+// the user to be one of the two parties. Every read path that takes a
+// conversation ID must call this before touching the store.
+func (h *Handler) authorizeConversation(userID, conversationID string) *errors.AppError {
+	if userA, userB, ok := database.ParseOneToOneConversationID(conversationID); ok {
+		if userID != userA && userID != userB {
+			return errors.ErrForbiddenAccessDenied
+		}
+		return nil
+	}
+
+	if !h.store.IsGroupMember(conversationID, userID) {
+		return errors.ErrNotGroupMember
+	}
+	return nil
+}
+
+// authorizeMessage verifies that userID can see message: they must be a participant of
+// its conversation, and in groups that only show history since joining, messages from
+// before they joined are reported as missing.
+func (h *Handler) authorizeMessage(userID string, message *database.Message) *errors.AppError {
+	if appErr := h.authorizeConversation(userID, message.ConversationID); appErr != nil {
+		return appErr
+	}
+	if message.ConversationType != database.ConversationTypeGroup {
+		return nil
+	}
+
+	group, err := h.store.GetGroup(message.ConversationID)
+	if err != nil || group.HistoryVisibility != database.HistoryVisibilitySinceJoined {
+		return nil
+	}
+	member, err := h.store.GetGroupMember(group.ID, userID)
+	if err == nil && message.CreatedAt.Before(member.JoinedAt) {
+		return errors.ErrMessageNotFound
+	}
+	return nil
+}
+
+// parentMessage loads a message that userID's new message replies to or threads under.
+// The parent must exist in conversationID, be visible to userID and not be deleted for
+// everyone. Parents hidden by history visibility are reported like missing ones.
+func (h *Handler) parentMessage(userID, conversationID, messageID string) (*database.Message, *errors.AppError) {
+	parent, err := h.store.GetMessage(messageID)
+	if err != nil || parent.ConversationID != conversationID || h.authorizeMessage(userID, parent) != nil {
+		return nil, errors.ErrInvalidParent
+	}
+	if parent.DeletedAt != nil {
+		return nil, errors.ErrMessageDeleted
+	}
+	return parent, nil
+}
+
+// pushMessage sends a new message to every open connection of each participant,
+// including the sender's other devices, and of any extra users
+func (h *Handler) pushMessage(message *database.Message, extra ...string) {
+	recipients := append([]string{message.SenderID}, extra...)
+	if message.ConversationType == database.ConversationTypeOneToOne {
+		recipients = append(recipients, message.DestinationID)
+	} else if members, err := h.store.ListGroupMembers(message.DestinationID); err == nil {
+		recipients = append(recipients, database.GroupMemberIDs(members)...)
+	}
+	for _, userID := range utils.RemoveDuplicates(recipients) {
+		h.wsManager.SendMessage(userID, message)
+	}
+}
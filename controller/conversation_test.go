@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+)
+
+func TestConversationReadsRequireParticipation(t *testing.T) {
+	s := newTestServer(t)
+	directID := database.OneToOneConversationID("user1", "user2")
+	direct := s.send(t, basicAuthFor("user1"), "user2", "hi Bob")
+
+	// user3 was in the group and left it
+	group := newTestGroup(t, s)
+	if err := s.store.AddGroupMember(group.ID, "user3", database.GroupRoleMember); err != nil {
+		t.Fatal(err)
+	}
+	inGroup := s.send(t, basicAuthFor("user1"), group.ID, "hi team")
+	s.do(t, MethodPOST, "/api/v1/groups/"+group.ID+"/leave", basicAuthFor("user3"), nil, nil, nil)
+
+	now := time.Now()
+	tests := []struct {
+		name    string
+		userID  string
+		method  string
+		path    string
+		body    interface{}
+		wantErr *errors.AppError
+	}{
+		{"one-to-one history by a third user", "user3", MethodGET, "/api/v1/conversations/" + directID + "/messages", nil, errors.ErrForbiddenAccessDenied},
+		{"one-to-one read marker by a third user", "user3", MethodPOST, "/api/v1/conversations/" + directID + "/read", MarkConversationReadRequest{UpTo: &now}, errors.ErrForbiddenAccessDenied},
+		{"one-to-one thread by a third user", "user3", MethodGET, "/api/v1/messages/" + direct + "/thread", nil, errors.ErrForbiddenAccessDenied},
+		{"group history after leaving", "user3", MethodGET, "/api/v1/conversations/" + group.ID + "/messages", nil, errors.ErrNotGroupMember},
+		{"group read marker after leaving", "user3", MethodPOST, "/api/v1/conversations/" + group.ID + "/read", MarkConversationReadRequest{UpTo: &now}, errors.ErrNotGroupMember},
+		{"group thread after leaving", "user3", MethodGET, "/api/v1/messages/" + inGroup + "/thread", nil, errors.ErrNotGroupMember},
+		{"one-to-one history by a participant", "user2", MethodGET, "/api/v1/conversations/" + directID + "/messages", nil, nil},
+		{"one-to-one thread by a participant", "user2", MethodGET, "/api/v1/messages/" + direct + "/thread", nil, nil},
+		{"group history by a member", "user2", MethodGET, "/api/v1/conversations/" + group.ID + "/messages", nil, nil},
+		{"group read marker by a member", "user2", MethodPOST, "/api/v1/conversations/" + group.ID + "/read", MarkConversationReadRequest{UpTo: &now}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.do(t, tt.method, tt.path, basicAuthFor(tt.userID), tt.body, nil, tt.wantErr)
+		})
+	}
+}
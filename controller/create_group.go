@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+	"github.com/kasasunil/chat_app/internal/pkg/utils"
+)
+
+// CreateGroupRequest represents the request to create a group
+type CreateGroupRequest struct {
+	Name              string                     `json:"name"`
+	Description       string                     `json:"description"`
+	MemberIDs         []string                   `json:"member_ids"`
+	HistoryVisibility database.HistoryVisibility `json:"history_visibility"` // Defaults to full history
+}
+
+// CreateGroup handles POST /groups
+// The caller creates the group and becomes its owner; everyone else joins as a member.
+func (h *Handler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user from context
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	if !h.config.Features.EnableGroupChat {
+		respondWithError(w, errors.ErrGroupChatDisabled)
+		return
+	}
+
+	var req CreateGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, errors.ErrInvalidRequest)
+		return
+	}
+
+	if utils.IsEmpty(req.Name) {
+		logger.Warn(logger.TraceValidationFailed, FieldGroupName, "empty")
+		respondWithError(w, errors.ErrGroupNameRequired)
+		return
+	}
+
+	if req.HistoryVisibility == "" {
+		req.HistoryVisibility = database.HistoryVisibilityFull
+	}
+	if !req.HistoryVisibility.IsValid() {
+		logger.Warn(logger.TraceValidationFailed, FieldVisibility, "unknown value")
+		respondWithError(w, errors.ErrInvalidVisibility)
+		return
+	}
+
+	members := []string{authenticatedUserID}
+	added, appErr := h.newGroupMembers(members, req.MemberIDs)
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+	members = append(members, added...)
+
+	group := &database.Group{
+		ID:                utils.GenerateID(),
+		Name:              utils.SanitizeString(req.Name, MaxGroupNameLength),
+		Description:       utils.SanitizeString(req.Description, MaxGroupDescLength),
+		CreatedBy:         authenticatedUserID,
+		HistoryVisibility: req.HistoryVisibility,
+	}
+	groupMembers := make([]*database.GroupMember, len(members))
+	for i, userID := range members {
+		role := database.GroupRoleMember
+		if userID == authenticatedUserID {
+			role = database.GroupRoleOwner
+		}
+		groupMembers[i] = &database.GroupMember{UserID: userID, Role: role}
+	}
+
+	// The group and its members are stored together, so a failure leaves no
+	// half-filled or ownerless group behind
+	if err := h.store.CreateGroupWithMembers(group, groupMembers); err != nil {
+		if err.Error() == database.ErrGroupAlreadyExists {
+			respondWithError(w, errors.ErrGroupAlreadyExists)
+			return
+		}
+		respondWithError(w, errors.ErrInternalError)
+		return
+	}
+	logger.Info(logger.TraceGroupCreated, group.ID, group.Name)
+	for _, userID := range members {
+		logger.Info(logger.TraceGroupMemberAdded, group.ID, userID)
+	}
+
+	response, appErr := h.groupResponse(group)
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated) // 201 Created - new resource created
+	json.NewEncoder(w).Encode(response)
+}
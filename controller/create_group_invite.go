@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+	"github.com/kasasunil/chat_app/internal/pkg/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// CreateGroupInviteRequest represents the request to create an invite link.
+// Both limits are optional; an empty body creates an invite that never expires.
+type CreateGroupInviteRequest struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+	MaxUses   int        `json:"max_uses"` // 0 for unlimited uses
+}
+
+// CreateGroupInvite handles POST /groups/{groupId}/invites
+// Only admins can create invites.
+func (h *Handler) CreateGroupInvite(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user from context
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	if !h.config.Features.EnableGroupChat {
+		respondWithError(w, errors.ErrGroupChatDisabled)
+		return
+	}
+
+	var req CreateGroupInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		respondWithError(w, errors.ErrInvalidRequest)
+		return
+	}
+
+	if req.MaxUses < 0 {
+		logger.Warn(logger.TraceValidationFailed, FieldMaxUses, "negative")
+		respondWithError(w, errors.ErrInvalidInvite)
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		logger.Warn(logger.TraceValidationFailed, FieldExpiresAt, "in the past")
+		respondWithError(w, errors.ErrInvalidInvite)
+		return
+	}
+
+	vars := mux.Vars(r)
+	group, _, appErr := h.adminGroup(vars["groupId"], authenticatedUserID)
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	invite := &database.GroupInvite{
+		ID:        utils.GenerateID(),
+		GroupID:   group.ID,
+		CreatedBy: authenticatedUserID,
+		ExpiresAt: req.ExpiresAt,
+		MaxUses:   req.MaxUses,
+	}
+	if err := h.store.CreateGroupInvite(invite); err != nil {
+		respondWithError(w, errors.ErrInternalError)
+		return
+	}
+	logger.Info(logger.TraceInviteCreated, group.ID, invite.ID, authenticatedUserID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated) // 201 Created - new resource created
+	json.NewEncoder(w).Encode(h.inviteResponse(invite))
+}
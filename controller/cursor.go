@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/signing"
+)
+
+// messageCursor is what a pagination cursor carries. Cursors are signed with the
+// server's key, so clients can neither forge a position nor replay a cursor in
+// another conversation or thread.
+type messageCursor struct {
+	ConversationID string                 `json:"c"`
+	ThreadRootID   string                 `json:"t,omitempty"`
+	Direction      database.PageDirection `json:"d"`
+	Position       string                 `json:"p"` // Message the next page is read next to
+}
+
+// encodeCursor signs a cursor for the client to send back
+func (h *Handler) encodeCursor(cursor messageCursor) string {
+	payload, _ := json.Marshal(cursor)
+	return h.signer.Sign(signing.PurposeCursor, string(payload))
+}
+
+// pageQuery reads the limit and cursor parameters of a request for a page of
+// conversationID, or of the thread rooted at threadRootID when it is set.
+// Limits above MaxMessageLimit are lowered to it.
+func (h *Handler) pageQuery(r *http.Request, conversationID, threadRootID string) (database.PageQuery, *errors.AppError) {
+	query := database.PageQuery{Limit: DefaultMessageLimit, Direction: database.PageBefore}
+	if limitStr := r.URL.Query().Get(FieldLimit); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			query.Limit = min(l, MaxMessageLimit)
+		}
+	}
+
+	token := r.URL.Query().Get(FieldCursor)
+	if token == "" {
+		return query, nil
+	}
+	payload, err := h.signer.Verify(signing.PurposeCursor, token)
+	if err != nil {
+		return query, errors.ErrInvalidCursor
+	}
+	var cursor messageCursor
+	if err := json.Unmarshal([]byte(payload), &cursor); err != nil || !cursor.Direction.IsValid() || cursor.Position == "" {
+		return query, errors.ErrInvalidCursor
+	}
+	if cursor.ConversationID != conversationID || cursor.ThreadRootID != threadRootID {
+		return query, errors.ErrInvalidCursor
+	}
+
+	// A signed cursor can still outlive its message, e.g. across an in-memory store restart
+	if message, err := h.store.GetMessage(cursor.Position); err != nil || message.ConversationID != conversationID {
+		return query, errors.ErrInvalidCursor
+	}
+	query.Direction = cursor.Direction
+	query.Position = cursor.Position
+	return query, nil
+}
+
+// pageCursors returns the cursors either side of a page: next reads the older
+// messages and is empty once there are none; prev reads newer messages and is
+// issued for every non-empty page, so clients can catch up from it later.
+func (h *Handler) pageCursors(page *database.MessagePage, conversationID, threadRootID string) (string, string) {
+	if len(page.Messages) == 0 {
+		return "", ""
+	}
+
+	next := ""
+	if page.HasOlder {
+		next = h.encodeCursor(messageCursor{
+			ConversationID: conversationID,
+			ThreadRootID:   threadRootID,
+			Direction:      database.PageBefore,
+			Position:       page.Messages[len(page.Messages)-1].ID,
+		})
+	}
+	prev := h.encodeCursor(messageCursor{
+		ConversationID: conversationID,
+		ThreadRootID:   threadRootID,
+		Direction:      database.PageAfter,
+		Position:       page.Messages[0].ID,
+	})
+	return next, prev
+}
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+
+	"github.com/gorilla/mux"
+)
+
+// DeleteMessageResponse represents the response after deleting a message
+type DeleteMessageResponse struct {
+	MessageID string `json:"message_id"`
+	Scope     string `json:"scope"`
+	Message   string `json:"message"`
+}
+
+// DeleteMessage handles DELETE /messages/{messageId}?scope=me|everyone
+// scope=me (the default) hides the message from the caller's own history, unread count and search.
+// scope=everyone replaces the message with a tombstone for all participants; only the sender
+// can do this, within the configured delete window.
+func (h *Handler) DeleteMessage(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user from context
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	scope := r.URL.Query().Get(FieldScope)
+	if scope == "" {
+		scope = DeleteScopeMe
+	}
+	if scope != DeleteScopeMe && scope != DeleteScopeEveryone {
+		respondWithError(w, errors.ErrInvalidDeleteScope)
+		return
+	}
+
+	vars := mux.Vars(r)
+	message, err := h.store.GetMessage(vars["messageId"])
+	if err != nil {
+		respondWithError(w, errors.ErrMessageNotFound)
+		return
+	}
+
+	// Only participants of the message's conversation may delete it
+	if appErr := h.authorizeMessage(authenticatedUserID, message); appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	if scope == DeleteScopeEveryone {
+		if message.Kind == database.MessageKindSystem {
+			respondWithError(w, errors.ErrSystemMessage)
+			return
+		}
+		if message.SenderID != authenticatedUserID {
+			respondWithError(w, errors.ErrNotMessageSender)
+			return
+		}
+		if message.DeletedAt == nil && time.Since(message.CreatedAt) > h.config.GetMessageDeleteWindow() {
+			respondWithError(w, errors.ErrDeleteWindowExpired)
+			return
+		}
+		if _, err := h.store.DeleteMessageForEveryone(message.ID); err != nil {
+			respondWithError(w, errors.ErrInternalError)
+			return
+		}
+	} else {
+		if err := h.store.DeleteMessageForUser(message.ID, authenticatedUserID); err != nil {
+			respondWithError(w, errors.ErrInternalError)
+			return
+		}
+	}
+
+	logger.Info(logger.TraceMessageDeleted, message.ID, authenticatedUserID, scope)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(DeleteMessageResponse{
+		MessageID: message.ID,
+		Scope:     scope,
+		Message:   MsgMessageDeleted,
+	})
+}
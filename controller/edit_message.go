@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+	"github.com/kasasunil/chat_app/internal/pkg/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// EditMessageRequest represents the request to edit a message
+type EditMessageRequest struct {
+	Message string `json:"message"`
+}
+
+// EditMessage handles PATCH /messages/{messageId}
+// Only the sender can edit, and only within the configured edit window.
+// The previous text is kept as a revision (see GetMessageHistory).
+func (h *Handler) EditMessage(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user from context
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	var req EditMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("Invalid request body in EditMessage: %v", err)
+		respondWithError(w, errors.ErrInvalidRequest)
+		return
+	}
+
+	if utils.IsEmpty(req.Message) {
+		logger.Warn(logger.TraceValidationFailed, FieldMessage, "empty")
+		respondWithError(w, errors.ErrMessageEmpty)
+		return
+	}
+
+	vars := mux.Vars(r)
+	message, err := h.store.GetMessage(vars["messageId"])
+	if err != nil {
+		respondWithError(w, errors.ErrMessageNotFound)
+		return
+	}
+
+	if message.Kind == database.MessageKindSystem {
+		respondWithError(w, errors.ErrSystemMessage)
+		return
+	}
+
+	if message.SenderID != authenticatedUserID {
+		respondWithError(w, errors.ErrNotMessageSender)
+		return
+	}
+
+	if message.DeletedAt != nil {
+		respondWithError(w, errors.ErrMessageDeleted)
+		return
+	}
+
+	if time.Since(message.CreatedAt) > h.config.GetMessageEditWindow() {
+		respondWithError(w, errors.ErrEditWindowExpired)
+		return
+	}
+
+	// The store checks again, as the message may have been deleted since it was read
+	updated, err := h.store.UpdateMessage(message.ID, utils.SanitizeString(req.Message, 10000))
+	if err != nil {
+		switch err.Error() {
+		case database.ErrMessageDeleted:
+			respondWithError(w, errors.ErrMessageDeleted)
+		case database.ErrMessageNotFound:
+			respondWithError(w, errors.ErrMessageNotFound)
+		default:
+			logger.Error("Failed to edit message: message=%s, error=%v", message.ID, err)
+			respondWithError(w, errors.ErrInternalError)
+		}
+		return
+	}
+
+	logger.Info(logger.TraceMessageEdited, updated.ID, authenticatedUserID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(updated)
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+
+	"github.com/gorilla/mux"
+)
+
+// GetGroup handles GET /groups/{groupId}
+// Only members can see a group.
+func (h *Handler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user from context
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	if !h.config.Features.EnableGroupChat {
+		respondWithError(w, errors.ErrGroupChatDisabled)
+		return
+	}
+
+	vars := mux.Vars(r)
+	group, _, appErr := h.memberGroup(vars["groupId"], authenticatedUserID)
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	response, appErr := h.groupResponse(group)
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+
+	"github.com/gorilla/mux"
+)
+
+// GetGroupMembersResponse represents the response for listing group members
+type GetGroupMembersResponse struct {
+	GroupID string                  `json:"group_id"`
+	Members []*database.GroupMember `json:"members"`
+}
+
+// GetGroupMembers handles GET /groups/{groupId}/members
+func (h *Handler) GetGroupMembers(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user from context
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	if !h.config.Features.EnableGroupChat {
+		respondWithError(w, errors.ErrGroupChatDisabled)
+		return
+	}
+
+	vars := mux.Vars(r)
+	group, _, appErr := h.memberGroup(vars["groupId"], authenticatedUserID)
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	members, err := h.store.ListGroupMembers(group.ID)
+	if err != nil {
+		respondWithError(w, errors.ErrInternalError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(GetGroupMembersResponse{
+		GroupID: group.ID,
+		Members: members,
+	})
+}
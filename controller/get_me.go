@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+)
+
+// GetMe handles GET /users/me
+// Returns the caller's own user record, including their email and login name.
+func (h *Handler) GetMe(w http.ResponseWriter, r *http.Request) {
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	user, err := h.store.GetUser(authenticatedUserID)
+	if err != nil {
+		logger.Warn(logger.TraceUserNotFound, authenticatedUserID)
+		respondWithError(w, errors.ErrUserNotFound)
+		return
+	}
+	logger.Debug(logger.TraceUserRetrieved, user.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(h.meResponse(user))
+}
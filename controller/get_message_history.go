@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+
+	"github.com/gorilla/mux"
+)
+
+// GetMessageHistoryResponse represents the response for fetching a message's edit history
+type GetMessageHistoryResponse struct {
+	Message   *database.Message           `json:"message"`
+	Revisions []*database.MessageRevision `json:"revisions"`
+}
+
+// GetMessageHistory handles GET /messages/{messageId}/history
+// Returns the current message and every earlier revision, oldest first.
+func (h *Handler) GetMessageHistory(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user from context
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	vars := mux.Vars(r)
+	message, err := h.store.GetMessage(vars["messageId"])
+	if err != nil {
+		respondWithError(w, errors.ErrMessageNotFound)
+		return
+	}
+
+	// Only participants of the message's conversation may see its history
+	if appErr := h.authorizeMessage(authenticatedUserID, message); appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	revisions, err := h.store.GetMessageRevisions(message.ID)
+	if err != nil {
+		respondWithError(w, errors.ErrInternalError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(GetMessageHistoryResponse{
+		Message:   message,
+		Revisions: revisions,
+	})
+}
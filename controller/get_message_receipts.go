@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+
+	"github.com/gorilla/mux"
+)
+
+// RecipientReceipt represents one recipient's delivery and read state
+type RecipientReceipt struct {
+	UserID      string     `json:"user_id"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+	ReadAt      *time.Time `json:"read_at,omitempty"`
+}
+
+// GetMessageReceiptsResponse represents the response for fetching message receipts
+type GetMessageReceiptsResponse struct {
+	MessageID  string                 `json:"message_id"`
+	Status     database.MessageStatus `json:"status"`
+	Recipients []RecipientReceipt     `json:"recipients"`
+}
+
+// GetMessageReceipts handles GET /messages/{messageId}/receipts
+// Lists every recipient of the message with when they received and read it.
+// Recipients that have not received the message yet are listed without timestamps.
+func (h *Handler) GetMessageReceipts(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user from context
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	vars := mux.Vars(r)
+	message, err := h.store.GetMessage(vars["messageId"])
+	if err != nil {
+		respondWithError(w, errors.ErrMessageNotFound)
+		return
+	}
+
+	// Only participants of the message's conversation may see its receipts
+	if appErr := h.authorizeMessage(authenticatedUserID, message); appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	members := []string{}
+	if message.ConversationType == database.ConversationTypeGroup {
+		groupMembers, err := h.store.ListGroupMembers(message.DestinationID)
+		if err != nil {
+			respondWithError(w, errors.ErrInternalError)
+			return
+		}
+		// Members who joined after the message was sent never receive it
+		members = database.GroupMemberIDsJoinedBy(groupMembers, message.CreatedAt)
+	}
+
+	receipts := make(map[string]*RecipientReceipt)
+	for _, userID := range database.MessageRecipients(message, members) {
+		receipts[userID] = &RecipientReceipt{UserID: userID}
+	}
+	// Receipts from former members are still listed
+	for _, md := range h.store.GetMessageDeliveries(message.ID) {
+		if receipts[md.UserID] == nil {
+			receipts[md.UserID] = &RecipientReceipt{UserID: md.UserID}
+		}
+		deliveredAt := md.CreatedAt
+		receipts[md.UserID].DeliveredAt = &deliveredAt
+	}
+	for _, mr := range h.store.GetMessageReads(message.ID) {
+		if receipts[mr.UserID] == nil {
+			receipts[mr.UserID] = &RecipientReceipt{UserID: mr.UserID}
+		}
+		readAt := mr.CreatedAt
+		receipts[mr.UserID].ReadAt = &readAt
+	}
+
+	recipients := make([]RecipientReceipt, 0, len(receipts))
+	for _, receipt := range receipts {
+		recipients = append(recipients, *receipt)
+	}
+	sort.Slice(recipients, func(i, j int) bool {
+		return recipients[i].UserID < recipients[j].UserID
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(GetMessageReceiptsResponse{
+		MessageID:  message.ID,
+		Status:     message.Status,
+		Recipients: recipients,
+	})
+}
@@ -3,9 +3,9 @@ package controller
 import (
 	"encoding/json"
 	"net/http"
-	"strconv"
 
 	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/middleware"
 	"github.com/kasasunil/chat_app/internal/pkg/errors"
 
 	"github.com/gorilla/mux"
@@ -14,38 +14,61 @@ import (
 // GetMessagesResponse represents the response for fetching messages
 type GetMessagesResponse struct {
 	Messages   []*database.Message `json:"messages"`
-	NextCursor string              `json:"next_cursor,omitempty"`
-	HasMore    bool                `json:"has_more"`
+	NextCursor string              `json:"next_cursor,omitempty"` // Reads older messages
+	PrevCursor string              `json:"prev_cursor,omitempty"` // Reads newer messages
+	HasMore    bool                `json:"has_more"`              // Older messages remain
+	HasNewer   bool                `json:"has_newer"`             // Newer messages remain
 }
 
 // GetMessages handles GET /conversations/{destinationId}/messages
 // This route fetches conversations of a single one-one messages / grp messages.
+// destinationId may be a group ID, a canonical one-to-one conversation ID,
+// or the other user's ID (the chat between that user and the caller).
+// Pages are newest first; cursors from a previous page read older or newer messages.
 func (h *Handler) GetMessages(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user from context
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
 	vars := mux.Vars(r)
-	destinationID := vars["destinationId"]
-
-	cursor := r.URL.Query().Get("cursor")
-	limitStr := r.URL.Query().Get("limit")
-	limit := 50 // default
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
-		}
+	conversationID := h.resolveConversationID(authenticatedUserID, vars["destinationId"])
+
+	// Only participants may read a conversation's history
+	if appErr := h.authorizeConversation(authenticatedUserID, conversationID); appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	query, appErr := h.pageQuery(r, conversationID, "")
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
 	}
 
-	messages, nextCursor, err := h.store.GetMessages(destinationID, limit, cursor)
+	page, err := h.store.GetMessages(conversationID, authenticatedUserID, query)
 	if err != nil {
 		respondWithError(w, errors.ErrInternalError)
 		return
 	}
 
-	hasMore := nextCursor != ""
+	// Attach reaction counts as seen by the caller
+	if err := h.attachReactions(page.Messages, authenticatedUserID); err != nil {
+		respondWithError(w, errors.ErrInternalError)
+		return
+	}
+
+	nextCursor, prevCursor := h.pageCursors(page, conversationID, "")
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK) // 200 OK
 	json.NewEncoder(w).Encode(GetMessagesResponse{
-		Messages:   messages,
+		Messages:   page.Messages,
 		NextCursor: nextCursor,
-		HasMore:    hasMore,
+		PrevCursor: prevCursor,
+		HasMore:    nextCursor != "",
+		HasNewer:   page.HasNewer,
 	})
 }
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/signing"
+)
+
+func TestGetMessagesRejectsBadCursors(t *testing.T) {
+	s := newTestServer(t)
+	authorization := basicAuthFor("user1")
+	withBob := database.OneToOneConversationID("user1", "user2")
+	withCarol := database.OneToOneConversationID("user1", "user3")
+	bobMessages := make([]string, 3)
+	for i := range bobMessages {
+		bobMessages[i] = s.send(t, authorization, "user2", fmt.Sprintf("to Bob %d", i))
+	}
+	for i := 0; i < 2; i++ {
+		s.send(t, authorization, "user3", fmt.Sprintf("to Carol %d", i))
+	}
+	root := bobMessages[0]
+	for i := 0; i < 2; i++ {
+		s.do(t, MethodPOST, EndpointSendMessage, authorization, SendMessageRequest{DestinationID: "user2", Message: "in the thread", ThreadRootID: root}, nil, nil)
+	}
+
+	// firstPage reads one message from path and returns the cursor to the older ones
+	firstPage := func(path string) string {
+		t.Helper()
+		var page GetMessagesResponse
+		s.do(t, MethodGET, path+"?limit=1", authorization, nil, &page, nil)
+		if page.NextCursor == "" {
+			t.Fatalf("%s returned no cursor", path)
+		}
+		return page.NextCursor
+	}
+	bobCursor := firstPage("/api/v1/conversations/" + withBob + "/messages")
+	carolCursor := firstPage("/api/v1/conversations/" + withCarol + "/messages")
+	threadCursor := firstPage("/api/v1/messages/" + root + "/thread")
+
+	// The cursor with its payload pointed at another message, keeping the signature
+	encoded, signature, _ := strings.Cut(bobCursor, ".")
+	payload, _ := base64.RawURLEncoding.DecodeString(encoded)
+	tampered := strings.Replace(string(payload), bobMessages[2], bobMessages[0], 1)
+	if tampered == string(payload) {
+		t.Fatalf("cursor payload %s does not hold the newest message", payload)
+	}
+	tamperedCursor := base64.RawURLEncoding.EncodeToString([]byte(tampered)) + "." + signature
+
+	tests := []struct {
+		name    string
+		cursor  string
+		wantErr *errors.AppError
+	}{
+		{"issued for the conversation", bobCursor, nil},
+		{"malformed", "not-a-cursor", errors.ErrInvalidCursor},
+		{"bad signature encoding", encoded + ".%%%", errors.ErrInvalidCursor},
+		{"tampered payload", tamperedCursor, errors.ErrInvalidCursor},
+		{"signed for another conversation", carolCursor, errors.ErrInvalidCursor},
+		{"signed for a thread", threadCursor, errors.ErrInvalidCursor},
+		{"signed for another purpose", signing.NewSigner(s.config.GetSigningKey()).Sign(signing.PurposeInvite, string(payload)), errors.ErrInvalidCursor},
+		{"signed with another key", signing.NewSigner([]byte("another key")).Sign(signing.PurposeCursor, string(payload)), errors.ErrInvalidCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := "/api/v1/conversations/" + withBob + "/messages?limit=1&cursor=" + url.QueryEscape(tt.cursor)
+			s.do(t, MethodGET, path, authorization, nil, nil, tt.wantErr)
+		})
+	}
+}
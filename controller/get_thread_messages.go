@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+
+	"github.com/gorilla/mux"
+)
+
+// GetThreadMessagesResponse represents the response for fetching a thread
+type GetThreadMessagesResponse struct {
+	Root       *database.Message   `json:"root"`
+	Messages   []*database.Message `json:"messages"`
+	NextCursor string              `json:"next_cursor,omitempty"` // Reads older replies
+	PrevCursor string              `json:"prev_cursor,omitempty"` // Reads newer replies
+	HasMore    bool                `json:"has_more"`              // Older replies remain
+	HasNewer   bool                `json:"has_newer"`             // Newer replies remain
+}
+
+// GetThreadMessages handles GET /messages/{messageId}/thread
+// Pages the replies in the thread rooted at messageId, newest first,
+// with the same cursor scheme as GetMessages.
+func (h *Handler) GetThreadMessages(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user from context
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	vars := mux.Vars(r)
+	root, err := h.store.GetMessage(vars["messageId"])
+	if err != nil {
+		respondWithError(w, errors.ErrMessageNotFound)
+		return
+	}
+
+	// Only participants of the root's conversation may read the thread
+	if appErr := h.authorizeMessage(authenticatedUserID, root); appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	// Thread cursors only page the thread they were issued for
+	query, appErr := h.pageQuery(r, root.ConversationID, root.ID)
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	page, err := h.store.GetThreadMessages(root.ID, authenticatedUserID, query)
+	if err != nil {
+		respondWithError(w, errors.ErrInternalError)
+		return
+	}
+
+	// Attach reaction counts as seen by the caller, to the root and its replies
+	if err := h.attachReactions(append([]*database.Message{root}, page.Messages...), authenticatedUserID); err != nil {
+		respondWithError(w, errors.ErrInternalError)
+		return
+	}
+
+	nextCursor, prevCursor := h.pageCursors(page, root.ConversationID, root.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(GetThreadMessagesResponse{
+		Root:       root,
+		Messages:   page.Messages,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+		HasMore:    nextCursor != "",
+		HasNewer:   page.HasNewer,
+	})
+}
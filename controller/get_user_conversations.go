@@ -19,6 +19,7 @@ type ConversationListItem struct {
 	ConversationType database.ConversationType `json:"conversation_type"`
 	LastMessage      *database.Message         `json:"last_message,omitempty"`
 	UnreadCount      int                       `json:"unread_count"`
+	ReadUpTo         *time.Time                `json:"read_up_to,omitempty"`
 	UpdatedAt        time.Time                 `json:"updated_at"`
 }
 
@@ -53,46 +54,26 @@ func (h *Handler) GetUserConversations(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Unread counts are maintained by the store against each read watermark,
+	// so the list costs the same however long each conversation's history is
 	items := make([]ConversationListItem, 0, len(conversations))
 	for _, conv := range conversations {
-		// Get last message for this conversation
-		messages, _, _ := h.store.GetMessages(conv.DestinationID, 1, "")
+		// Get last message for this conversation (a tombstone if it was deleted for everyone).
+		// Entries outlive group membership, so removed and departed members get no preview.
 		var lastMessage *database.Message
-		if len(messages) > 0 {
-			lastMessage = messages[0]
-		}
-
-		// Calculate unread count (messages sent to user that haven't been read)
-		// A message is unread if:
-		// 1. User is not the sender
-		// 2. There's no MessageRead entry for this user and message
-		unreadCount := 0
-		allMessages, _, _ := h.store.GetMessages(conv.DestinationID, 1000, "")
-		for _, msg := range allMessages {
-			// Only count messages not sent by the user
-			if msg.SenderID != userID {
-				reads := h.store.GetMessageReads(msg.ID)
-				hasRead := false
-				// Check if this user has read this message
-				for _, read := range reads {
-					if read.UserID == userID {
-						hasRead = true
-						break
-					}
-				}
-				// If no read receipt exists for this user, it's unread
-				if !hasRead {
-					unreadCount++
-				}
+		if h.authorizeConversation(userID, conv.ConversationID) == nil {
+			if page, err := h.store.GetMessages(conv.ConversationID, userID, database.PageQuery{Limit: 1}); err == nil && len(page.Messages) > 0 {
+				lastMessage = page.Messages[0]
 			}
 		}
 
 		items = append(items, ConversationListItem{
-			ConversationID:   conv.ID,
+			ConversationID:   conv.ConversationID,
 			DestinationID:    conv.DestinationID,
 			ConversationType: conv.ConversationType,
 			LastMessage:      lastMessage,
-			UnreadCount:      unreadCount,
+			UnreadCount:      conv.UnreadCount,
+			ReadUpTo:         conv.ReadUpTo,
 			UpdatedAt:        conv.UpdatedAt,
 		})
 	}
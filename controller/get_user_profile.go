@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+
+	"github.com/gorilla/mux"
+)
+
+// GetUserProfile handles GET /users/{userId}
+// Returns the public profile of any user: name, status text and avatar, but not their email.
+func (h *Handler) GetUserProfile(w http.ResponseWriter, r *http.Request) {
+	if middleware.GetUserID(r) == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	userID := mux.Vars(r)["userId"]
+	user, err := h.store.GetUser(userID)
+	if err != nil {
+		logger.Warn(logger.TraceUserNotFound, userID)
+		respondWithError(w, errors.ErrUserNotFound)
+		return
+	}
+	logger.Debug(logger.TraceUserRetrieved, user.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(publicProfile(user))
+}
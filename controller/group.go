@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"sort"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+	"github.com/kasasunil/chat_app/internal/pkg/utils"
+)
+
+// GroupResponse represents a group together with its members
+type GroupResponse struct {
+	*database.Group
+	Members []*database.GroupMember `json:"members"`
+}
+
+// memberGroup loads a group and the membership of userID in it
+func (h *Handler) memberGroup(groupID, userID string) (*database.Group, *database.GroupMember, *errors.AppError) {
+	group, err := h.store.GetGroup(groupID)
+	if err != nil {
+		logger.Warn(logger.TraceGroupNotFound, groupID)
+		return nil, nil, errors.ErrGroupNotFound
+	}
+
+	logger.Debug(logger.TraceGroupMemberCheck, groupID, userID)
+	member, err := h.store.GetGroupMember(groupID, userID)
+	if err != nil {
+		return nil, nil, errors.ErrNotGroupMember
+	}
+	return group, member, nil
+}
+
+// adminGroup loads a group that userID can manage (owner or admin)
+func (h *Handler) adminGroup(groupID, userID string) (*database.Group, *database.GroupMember, *errors.AppError) {
+	group, member, appErr := h.memberGroup(groupID, userID)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+	if !member.Role.IsAdmin() {
+		return nil, nil, errors.ErrNotGroupAdmin
+	}
+	return group, member, nil
+}
+
+// newGroupMembers returns the users in userIDs that are not yet in memberIDs.
+// Every user must exist, and the group must stay within max_group_members.
+func (h *Handler) newGroupMembers(memberIDs, userIDs []string) ([]string, *errors.AppError) {
+	added := make([]string, 0, len(userIDs))
+	for _, userID := range utils.RemoveDuplicates(userIDs) {
+		if !utils.Contains(memberIDs, userID) {
+			added = append(added, userID)
+		}
+	}
+	if appErr := h.requireUsers(added); appErr != nil {
+		return nil, appErr
+	}
+
+	if len(memberIDs)+len(added) > h.config.GetMaxGroupMembers() {
+		return nil, errors.ErrGroupMemberLimit
+	}
+	return added, nil
+}
+
+// requireUsers checks that every user in userIDs exists
+func (h *Handler) requireUsers(userIDs []string) *errors.AppError {
+	for _, userID := range userIDs {
+		if _, err := h.store.GetUser(userID); err != nil {
+			logger.Warn(logger.TraceUserNotFound, userID)
+			return errors.ErrUserNotFound
+		}
+	}
+	return nil
+}
+
+// nextGroupOwner picks who inherits a group when its owner leaves:
+// the longest-standing admin, or the longest-standing member if there are no admins.
+// Returns nil when nobody else is left.
+func nextGroupOwner(members []*database.GroupMember, ownerID string) *database.GroupMember {
+	candidates := make([]*database.GroupMember, 0, len(members))
+	for _, member := range members {
+		if member.UserID != ownerID {
+			candidates = append(candidates, member)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Role.IsAdmin() != candidates[j].Role.IsAdmin() {
+			return candidates[i].Role.IsAdmin()
+		}
+		return candidates[i].JoinedAt.Before(candidates[j].JoinedAt)
+	})
+
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[0]
+}
+
+// ownershipTransferError maps a failed TransferGroupOwnership to its API error.
+// The caller or the new owner may have lost their role or left in the meantime.
+func ownershipTransferError(err error) *errors.AppError {
+	switch err.Error() {
+	case database.ErrNotGroupOwner:
+		return errors.ErrNotGroupOwner
+	case database.ErrGroupMemberNotFound:
+		return errors.ErrNotGroupMember
+	default:
+		return errors.ErrInternalError
+	}
+}
+
+// groupResponse builds the response for a group with its current members
+func (h *Handler) groupResponse(group *database.Group) (*GroupResponse, *errors.AppError) {
+	members, err := h.store.ListGroupMembers(group.ID)
+	if err != nil {
+		return nil, errors.ErrInternalError
+	}
+	return &GroupResponse{Group: group, Members: members}, nil
+}
+
+// postSystemMessage records a group event in the group's history on behalf of payload.ActorID
+// and pushes it to the members and to the users the event is about (who may have just left).
+// text is a readable rendering for clients that do not understand the event type.
+// Failures are logged; the event itself has already happened.
+func (h *Handler) postSystemMessage(groupID string, systemType database.SystemMessageType, payload *database.SystemPayload, text string) {
+	message := &database.Message{
+		ID:               utils.GenerateID(),
+		SenderID:         payload.ActorID,
+		DestinationID:    groupID,
+		MessageText:      text,
+		Kind:             database.MessageKindSystem,
+		SystemType:       systemType,
+		SystemPayload:    payload,
+		ConversationType: database.ConversationTypeGroup,
+	}
+	if err := h.store.CreateMessage(message); err != nil {
+		logger.Error("Failed to post system message: group=%s, type=%s, error=%v", groupID, systemType, err)
+		return
+	}
+	logger.Info(logger.TraceSystemMessage, groupID, systemType, message.ID)
+	h.pushMessage(message, payload.UserIDs...)
+}
@@ -0,0 +1,295 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+)
+
+// newTestGroup stores a group owned by user1 with user2 as a member
+func newTestGroup(t *testing.T, s *testServer) *database.Group {
+	t.Helper()
+	group := &database.Group{ID: "group1", Name: "Team", CreatedBy: "user1"}
+	if err := s.store.CreateGroup(group); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.store.AddGroupMember(group.ID, "user1", database.GroupRoleOwner); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.store.AddGroupMember(group.ID, "user2", database.GroupRoleMember); err != nil {
+		t.Fatal(err)
+	}
+	return group
+}
+
+// newLateJoinerGroup stores newTestGroup showing history since joining, with a message
+// user1 sent before user3 joined and one sent after. It returns the two message IDs.
+func newLateJoinerGroup(t *testing.T, s *testServer) (before, after string) {
+	t.Helper()
+	group := newTestGroup(t, s)
+	visibility := database.HistoryVisibilitySinceJoined
+	if _, err := s.store.UpdateGroup(group.ID, database.GroupUpdate{HistoryVisibility: &visibility}); err != nil {
+		t.Fatal(err)
+	}
+	before = s.send(t, basicAuthFor("user1"), group.ID, "sent before user3 joined")
+	if err := s.store.AddGroupMember(group.ID, "user3", database.GroupRoleMember); err != nil {
+		t.Fatal(err)
+	}
+	after = s.send(t, basicAuthFor("user1"), group.ID, "sent after user3 joined")
+	return before, after
+}
+
+func TestConcurrentGroupUpdatesKeepEachChange(t *testing.T) {
+	const rounds = 10
+	s := newTestServer(t)
+	group := newTestGroup(t, s)
+	authorization := bearer(s.login(t, "user1", "password1").AccessToken)
+
+	name := "Renamed"
+	announcementOnly := true
+	requests := []struct {
+		path string
+		body interface{}
+	}{
+		{"/api/v1/groups/" + group.ID, UpdateGroupRequest{Name: &name}},
+		{"/api/v1/groups/" + group.ID + "/settings", UpdateGroupSettingsRequest{AnnouncementOnly: &announcementOnly}},
+	}
+
+	// Requests run in goroutines, so failures are reported after they finish
+	statuses := make(chan int, rounds*len(requests))
+	var wg sync.WaitGroup
+	for i := 0; i < rounds; i++ {
+		for _, request := range requests {
+			wg.Add(1)
+			go func(path string, body interface{}) {
+				defer wg.Done()
+				payload, _ := json.Marshal(body)
+				req, _ := http.NewRequest(MethodPATCH, s.URL+path, bytes.NewReader(payload))
+				req.Header.Set(middleware.HeaderAuthorization, authorization)
+				resp, err := http.DefaultClient.Do(req)
+				if err != nil {
+					statuses <- 0
+					return
+				}
+				resp.Body.Close()
+				statuses <- resp.StatusCode
+			}(request.path, request.body)
+		}
+	}
+	wg.Wait()
+	close(statuses)
+	for status := range statuses {
+		if status != http.StatusOK {
+			t.Fatalf("update returned %d, want 200", status)
+		}
+	}
+
+	saved, err := s.store.GetGroup(group.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Name != name || !saved.AnnouncementOnly {
+		t.Errorf("group saved as name=%q announcement_only=%t, want both updates kept", saved.Name, saved.AnnouncementOnly)
+	}
+}
+
+func TestConcurrentOwnershipTransfersLeaveOneOwner(t *testing.T) {
+	s := newTestServer(t)
+	group := newTestGroup(t, s)
+	if err := s.store.AddGroupMember(group.ID, "user3", database.GroupRoleMember); err != nil {
+		t.Fatal(err)
+	}
+	authorization := bearer(s.login(t, "user1", "password1").AccessToken)
+
+	// The owner hands the group to two members at once; only one can take it
+	statuses := make(chan int, 2)
+	var wg sync.WaitGroup
+	for _, userID := range []string{"user2", "user3"} {
+		wg.Add(1)
+		go func(userID string) {
+			defer wg.Done()
+			payload, _ := json.Marshal(SetGroupMemberRoleRequest{Role: database.GroupRoleOwner})
+			req, _ := http.NewRequest(MethodPATCH, s.URL+"/api/v1/groups/"+group.ID+"/members/"+userID, bytes.NewReader(payload))
+			req.Header.Set(middleware.HeaderAuthorization, authorization)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				statuses <- 0
+				return
+			}
+			resp.Body.Close()
+			statuses <- resp.StatusCode
+		}(userID)
+	}
+	wg.Wait()
+	close(statuses)
+	counts := make(map[int]int)
+	for status := range statuses {
+		counts[status]++
+	}
+	if counts[http.StatusOK] != 1 || counts[http.StatusForbidden] != 1 {
+		t.Errorf("got statuses %v, want one 200 and one 403", counts)
+	}
+
+	members, err := s.store.ListGroupMembers(group.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roles := make(map[database.GroupRole]int)
+	for _, member := range members {
+		roles[member.Role]++
+	}
+	if roles[database.GroupRoleOwner] != 1 || roles[database.GroupRoleAdmin] != 1 {
+		t.Errorf("group has roles %v, want one owner and the previous owner as admin", roles)
+	}
+}
+
+func TestOwnerLeavingHandsOverTheGroup(t *testing.T) {
+	s := newTestServer(t)
+	group := newTestGroup(t, s)
+	authorization := bearer(s.login(t, "user1", "password1").AccessToken)
+
+	s.do(t, MethodPOST, "/api/v1/groups/"+group.ID+"/leave", authorization, nil, nil, nil)
+
+	members, err := s.store.ListGroupMembers(group.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 1 || members[0].UserID != "user2" || members[0].Role != database.GroupRoleOwner {
+		t.Errorf("group left with %+v, want user2 as the only member and owner", members)
+	}
+}
+
+func TestAddGroupMembersIsAllOrNothing(t *testing.T) {
+	s := newTestServer(t)
+	group := newTestGroup(t, s)
+	s.config.Features.MaxGroupMembers = 3
+	authorization := basicAuthFor("user1")
+	path := "/api/v1/groups/" + group.ID + "/members"
+
+	s.do(t, MethodPOST, path, authorization, AddGroupMembersRequest{UserIDs: []string{"user3", "nobody"}}, nil, errors.ErrUserNotFound)
+	if s.store.IsGroupMember(group.ID, "user3") {
+		t.Fatal("a batch naming an unknown user added the others")
+	}
+
+	var response GroupResponse
+	s.do(t, MethodPOST, path, authorization, AddGroupMembersRequest{UserIDs: []string{"user2", "user3"}}, &response, nil)
+	if len(response.Members) != 3 {
+		t.Errorf("group has %d members, want 3", len(response.Members))
+	}
+
+	// Full now: even re-adding a member alongside someone new is refused whole
+	if err := s.store.CreateUser(&database.User{ID: "user4", Name: "Dave"}); err != nil {
+		t.Fatal(err)
+	}
+	s.do(t, MethodPOST, path, authorization, AddGroupMembersRequest{UserIDs: []string{"user2", "user4"}}, nil, errors.ErrGroupMemberLimit)
+	if s.store.IsGroupMember(group.ID, "user4") {
+		t.Error("a batch over the member limit was partly added")
+	}
+}
+
+func TestFormerMembersGetNoConversationPreview(t *testing.T) {
+	s := newTestServer(t)
+	group := newTestGroup(t, s)
+	if err := s.store.AddGroupMember(group.ID, "user3", database.GroupRoleMember); err != nil {
+		t.Fatal(err)
+	}
+	owner := basicAuthFor("user1")
+	s.send(t, owner, group.ID, "before they went")
+
+	s.do(t, MethodDELETE, "/api/v1/groups/"+group.ID+"/members/user2", owner, nil, nil, nil)
+	s.do(t, MethodPOST, "/api/v1/groups/"+group.ID+"/leave", basicAuthFor("user3"), nil, nil, nil)
+	latest := s.send(t, owner, group.ID, "after they went")
+
+	tests := []struct {
+		userID      string
+		wantPreview string // Empty when the entry must have no preview
+	}{
+		{"user1", latest},
+		{"user2", ""}, // Removed
+		{"user3", ""}, // Left
+	}
+	for _, tt := range tests {
+		t.Run(tt.userID, func(t *testing.T) {
+			var list GetUserConversationsResponse
+			s.do(t, MethodGET, "/api/v1/users/"+tt.userID+"/conversations", basicAuthFor(tt.userID), nil, &list, nil)
+			for _, item := range list.Conversations {
+				if item.ConversationID != group.ID {
+					continue
+				}
+				switch {
+				case tt.wantPreview == "" && item.LastMessage != nil:
+					t.Errorf("former member sees %q as the preview", item.LastMessage.MessageText)
+				case tt.wantPreview != "" && (item.LastMessage == nil || item.LastMessage.ID != tt.wantPreview):
+					t.Errorf("preview is %+v, want %s", item.LastMessage, tt.wantPreview)
+				}
+			}
+		})
+	}
+}
+
+func TestSinceJoinedHidesEarlierHistory(t *testing.T) {
+	s := newTestServer(t)
+	before, after := newLateJoinerGroup(t, s)
+
+	tests := []struct {
+		userID     string
+		wantIDs    []string // Newest first
+		wantUnread int
+	}{
+		{"user3", []string{after}, 1},
+		{"user2", []string{after, before}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.userID, func(t *testing.T) {
+			authorization := basicAuthFor(tt.userID)
+
+			var page GetMessagesResponse
+			s.do(t, MethodGET, "/api/v1/conversations/group1/messages", authorization, nil, &page, nil)
+			if got := messageIDs(page.Messages); fmt.Sprint(got) != fmt.Sprint(tt.wantIDs) {
+				t.Errorf("history holds %v, want %v", got, tt.wantIDs)
+			}
+
+			var search SearchMessagesResponse
+			s.do(t, MethodGET, "/api/v1/search/"+tt.userID+"?query=joined", authorization, nil, &search, nil)
+			if got := messageIDs(search.Results); len(got) != len(tt.wantIDs) || !containsAll(got, tt.wantIDs) {
+				t.Errorf("search found %v, want %v", got, tt.wantIDs)
+			}
+
+			var list GetUserConversationsResponse
+			s.do(t, MethodGET, "/api/v1/users/"+tt.userID+"/conversations", authorization, nil, &list, nil)
+			if len(list.Conversations) != 1 || list.Conversations[0].UnreadCount != tt.wantUnread {
+				t.Errorf("chat list is %+v, want group1 with %d unread", list.Conversations, tt.wantUnread)
+			}
+		})
+	}
+}
+
+// messageIDs returns the IDs of messages, in order
+func messageIDs(messages []*database.Message) []string {
+	ids := make([]string, len(messages))
+	for i, message := range messages {
+		ids[i] = message.ID
+	}
+	return ids
+}
+
+// containsAll reports whether got holds every ID in want
+func containsAll(got, want []string) bool {
+	for _, id := range want {
+		found := false
+		for _, g := range got {
+			found = found || g == id
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
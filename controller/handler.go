@@ -1,23 +1,35 @@
 package controller
 
 import (
+	"github.com/kasasunil/chat_app/config"
 	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/pkg/auth"
+	"github.com/kasasunil/chat_app/internal/pkg/signing"
 	"github.com/kasasunil/chat_app/internal/services/search"
 	"github.com/kasasunil/chat_app/internal/services/websocket"
 )
 
 // Handler contains all HTTP handlers
 type Handler struct {
+	config        *config.Config
 	store         database.Repository
 	wsManager     websocket.WebSocketManager
 	searchService *search.SearchService
+	signer        *signing.Signer
+	tokens        *auth.Tokens
+	credentials   *auth.Credentials
 }
 
 // NewHandler creates a new handler instance
-func NewHandler(store database.Repository, wsManager websocket.WebSocketManager) *Handler {
+func NewHandler(cfg *config.Config, store database.Repository, wsManager websocket.WebSocketManager) *Handler {
+	signer := signing.NewSigner(cfg.GetSigningKey())
 	return &Handler{
+		config:        cfg,
 		store:         store,
 		wsManager:     wsManager,
 		searchService: search.NewSearchService(store),
+		signer:        signer,
+		tokens:        auth.NewTokens(signer),
+		credentials:   auth.NewCredentials(store),
 	}
 }
@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/kasasunil/chat_app/config"
+	"github.com/kasasunil/chat_app/database"
+	in_memory "github.com/kasasunil/chat_app/database/in-memory"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/auth"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/services/websocket"
+)
+
+// testUsers sign in with login name userN and password passwordN
+var testUsers = []*database.User{
+	{ID: "user1", Name: "Alice", Email: "alice@example.com"},
+	{ID: "user2", Name: "Bob", Email: "bob@example.com"},
+	{ID: "user3", Name: "Carol", Email: "carol@example.com"},
+}
+
+// testServer is an in-process server for the handlers under test
+type testServer struct {
+	*httptest.Server
+	config *config.Config
+	store  database.Repository
+}
+
+// newTestServer serves the session, profile, messaging and group management routes over an in-memory store holding testUsers
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	cfg := config.NewConfig()
+	cfg.RateLimit.Enabled = false
+	store := in_memory.NewStore()
+	for i, testUser := range testUsers {
+		user := *testUser
+		hash, err := auth.HashPassword(fmt.Sprintf("password%d", i+1), bcrypt.MinCost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		credential := &database.Credential{UserID: user.ID, LoginName: user.ID, PasswordHash: hash}
+		if err := store.CreateUserWithCredential(&user, credential); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	handler := NewHandler(cfg, store, websocket.NewMockWebSocketManager())
+	router := mux.NewRouter()
+	router.HandleFunc(EndpointSignUp, handler.SignUp).Methods(MethodPOST)
+	router.HandleFunc(EndpointLogin, handler.Login).Methods(MethodPOST)
+	router.HandleFunc(EndpointRefreshToken, handler.RefreshToken).Methods(MethodPOST)
+	router.HandleFunc(EndpointLogout, handler.Logout).Methods(MethodPOST)
+	api := router.PathPrefix("/api/v1").Subrouter()
+	api.Use(middleware.NewAuthMiddleware(cfg, store).Authenticate)
+	api.HandleFunc("/users/me", handler.GetMe).Methods(MethodGET)
+	api.HandleFunc("/users/me/login-name", handler.RenameLogin).Methods(MethodPUT)
+	api.HandleFunc("/sendMessage", handler.SendMessage).Methods(MethodPOST)
+	api.HandleFunc("/ack/delivered", handler.AckDelivered).Methods(MethodPOST)
+	api.HandleFunc("/ack/read", handler.AckRead).Methods(MethodPOST)
+	api.HandleFunc("/conversations/{destinationId}/messages", handler.GetMessages).Methods(MethodGET)
+	api.HandleFunc("/conversations/{destinationId}/read", handler.MarkConversationRead).Methods(MethodPOST)
+	api.HandleFunc("/messages/{messageId}/thread", handler.GetThreadMessages).Methods(MethodGET)
+	api.HandleFunc("/users/{userId}/conversations", handler.GetUserConversations).Methods(MethodGET)
+	api.HandleFunc("/search/{userId}", handler.SearchMessages).Methods(MethodGET)
+	api.HandleFunc("/groups/{groupId}", handler.UpdateGroup).Methods(MethodPATCH)
+	api.HandleFunc("/groups/{groupId}/settings", handler.UpdateGroupSettings).Methods(MethodPATCH)
+	api.HandleFunc("/groups/{groupId}/members", handler.AddGroupMembers).Methods(MethodPOST)
+	api.HandleFunc("/groups/{groupId}/members/{userId}", handler.SetGroupMemberRole).Methods(MethodPATCH)
+	api.HandleFunc("/groups/{groupId}/members/{userId}", handler.RemoveGroupMember).Methods(MethodDELETE)
+	api.HandleFunc("/groups/{groupId}/leave", handler.LeaveGroup).Methods(MethodPOST)
+	api.HandleFunc("/groups/{groupId}/invites", handler.CreateGroupInvite).Methods(MethodPOST)
+	api.HandleFunc("/groups/{groupId}/invites/{inviteId}", handler.RevokeGroupInvite).Methods(MethodDELETE)
+	api.HandleFunc("/invites/{token}/join", handler.JoinGroupInvite).Methods(MethodPOST)
+
+	server := &testServer{Server: httptest.NewServer(router), config: cfg, store: store}
+	t.Cleanup(server.Close)
+	return server
+}
+
+// do sends a request with an optional Authorization header and JSON body. A 2xx
+// reply is decoded into into; otherwise the error must match wantErr.
+func (s *testServer) do(t *testing.T, method, path, authorization string, body, into interface{}, wantErr *errors.AppError) {
+	t.Helper()
+	var payload bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&payload).Encode(body)
+	}
+	req, _ := http.NewRequest(method, s.URL+path, &payload)
+	if authorization != "" {
+		req.Header.Set(middleware.HeaderAuthorization, authorization)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		if wantErr != nil {
+			t.Fatalf("%s %s: got %d, want %d %s", method, path, resp.StatusCode, wantErr.HTTPStatus, wantErr.Code)
+		}
+		if into != nil {
+			json.NewDecoder(resp.Body).Decode(into)
+		}
+		return
+	}
+	var reply struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	json.NewDecoder(resp.Body).Decode(&reply)
+	if wantErr == nil {
+		t.Fatalf("%s %s: got %d %s, want success", method, path, resp.StatusCode, reply.Error.Code)
+	}
+	if resp.StatusCode != wantErr.HTTPStatus || reply.Error.Code != string(wantErr.Code) {
+		t.Fatalf("%s %s: got %d %s, want %d %s", method, path, resp.StatusCode, reply.Error.Code, wantErr.HTTPStatus, wantErr.Code)
+	}
+}
+
+// login starts a session, failing the test if it cannot
+func (s *testServer) login(t *testing.T, username, password string) *TokenResponse {
+	t.Helper()
+	var tokens TokenResponse
+	s.do(t, MethodPOST, EndpointLogin, "", LoginRequest{Username: username, Password: password}, &tokens, nil)
+	return &tokens
+}
+
+// send posts a message as the user signed in with authorization and returns its ID
+func (s *testServer) send(t *testing.T, authorization, destinationID, text string) string {
+	t.Helper()
+	var response SendMessageResponse
+	s.do(t, MethodPOST, EndpointSendMessage, authorization, SendMessageRequest{DestinationID: destinationID, Message: text}, &response, nil)
+	return response.MessageID
+}
+
+// basicAuthFor returns the Basic credentials of one of testUsers
+func basicAuthFor(userID string) string {
+	for i, user := range testUsers {
+		if user.ID == userID {
+			return basicAuth(user.ID, fmt.Sprintf("password%d", i+1))
+		}
+	}
+	panic("unknown test user " + userID)
+}
+
+func bearer(accessToken string) string {
+	return middleware.AuthSchemeBearer + " " + accessToken
+}
+
+func basicAuth(login, password string) string {
+	req, _ := http.NewRequest(MethodGET, "/", nil)
+	req.SetBasicAuth(login, password)
+	return req.Header.Get(middleware.HeaderAuthorization)
+}
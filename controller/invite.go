@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/signing"
+)
+
+// InviteResponse represents an invite together with the token to share.
+// The token is the invite ID signed with the server's key, so it cannot be guessed
+// from other invites and can be rebuilt whenever admins list invites.
+type InviteResponse struct {
+	*database.GroupInvite
+	Token string `json:"token"`
+}
+
+// inviteResponse attaches the shareable token to an invite
+func (h *Handler) inviteResponse(invite *database.GroupInvite) *InviteResponse {
+	return &InviteResponse{
+		GroupInvite: invite,
+		Token:       h.signer.Sign(signing.PurposeInvite, invite.ID),
+	}
+}
+
+// inviteFromToken resolves a shared token to its invite.
+// Tokens that were tampered with, or signed with another key, are treated as unknown.
+func (h *Handler) inviteFromToken(token string) (*database.GroupInvite, *errors.AppError) {
+	inviteID, err := h.signer.Verify(signing.PurposeInvite, token)
+	if err != nil {
+		return nil, errors.ErrInviteNotFound
+	}
+	invite, err := h.store.GetGroupInvite(inviteID)
+	if err != nil {
+		return nil, errors.ErrInviteNotFound
+	}
+	return invite, nil
+}
+
+// inviteJoinError maps a store error from joining with an invite to the error to respond with
+func inviteJoinError(err error) *errors.AppError {
+	switch err.Error() {
+	case database.ErrInviteNotFound:
+		return errors.ErrInviteNotFound
+	case database.ErrInviteRevoked:
+		return errors.ErrInviteRevoked
+	case database.ErrInviteExpired:
+		return errors.ErrInviteExpired
+	case database.ErrInviteExhausted:
+		return errors.ErrInviteExhausted
+	case database.ErrGroupFull:
+		return errors.ErrGroupMemberLimit
+	}
+	return errors.ErrInternalError
+}
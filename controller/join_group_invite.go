@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+
+	"github.com/gorilla/mux"
+)
+
+// JoinGroupInvite handles POST /invites/{token}/join
+// Adds the caller to the invite's group as a member. Callers who are already
+// members get the group back without using up the invite.
+func (h *Handler) JoinGroupInvite(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user from context
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	if !h.config.Features.EnableGroupChat {
+		respondWithError(w, errors.ErrGroupChatDisabled)
+		return
+	}
+
+	vars := mux.Vars(r)
+	invite, appErr := h.inviteFromToken(vars["token"])
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	group, err := h.store.GetGroup(invite.GroupID)
+	if err != nil {
+		logger.Warn(logger.TraceGroupNotFound, invite.GroupID)
+		respondWithError(w, errors.ErrGroupNotFound)
+		return
+	}
+
+	// The store checks the invite and the member limit, counts the use and adds the
+	// caller in one operation, so concurrent joins cannot overrun either limit
+	_, err = h.store.JoinGroupWithInvite(invite.ID, authenticatedUserID, h.config.GetMaxGroupMembers())
+	switch {
+	case err == nil:
+		logger.Info(logger.TraceInviteUsed, group.ID, invite.ID, authenticatedUserID)
+		h.postSystemMessage(group.ID, database.SystemMemberJoined, &database.SystemPayload{
+			ActorID: authenticatedUserID,
+			UserIDs: []string{authenticatedUserID},
+		}, fmt.Sprintf(SysMsgMemberJoined, authenticatedUserID))
+	case err.Error() != database.ErrAlreadyGroupMember:
+		respondWithError(w, inviteJoinError(err))
+		return
+	}
+
+	response, appErr := h.groupResponse(group)
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/signing"
+)
+
+func TestJoinGroupInvite(t *testing.T) {
+	s := newTestServer(t)
+	owner := basicAuthFor("user1")
+	group := &database.Group{ID: "group1", Name: "Team", CreatedBy: "user1"}
+	if err := s.store.CreateGroupWithMembers(group, []*database.GroupMember{{UserID: "user1", Role: database.GroupRoleOwner}}); err != nil {
+		t.Fatal(err)
+	}
+	// Room for user2 only, so user3's joins fail on the invite when it is unusable
+	// and on the member limit when it is not
+	s.config.Features.MaxGroupMembers = 2
+
+	createInvite := func(request CreateGroupInviteRequest) *InviteResponse {
+		t.Helper()
+		var invite InviteResponse
+		s.do(t, MethodPOST, "/api/v1/groups/"+group.ID+"/invites", owner, request, &invite, nil)
+		return &invite
+	}
+	single := createInvite(CreateGroupInviteRequest{MaxUses: 1})
+	open := createInvite(CreateGroupInviteRequest{})
+	revoked := createInvite(CreateGroupInviteRequest{})
+	s.do(t, MethodDELETE, "/api/v1/groups/"+group.ID+"/invites/"+revoked.ID, owner, nil, nil, nil)
+
+	// Invites cannot be created already expired, so this one is stored directly
+	past := time.Now().Add(-time.Hour)
+	expired := &database.GroupInvite{ID: "expired", GroupID: group.ID, CreatedBy: "user1", ExpiresAt: &past}
+	if err := s.store.CreateGroupInvite(expired); err != nil {
+		t.Fatal(err)
+	}
+	signer := signing.NewSigner(s.config.GetSigningKey())
+
+	tests := []struct {
+		name    string
+		userID  string
+		token   string
+		wantErr *errors.AppError
+	}{
+		{"first use", "user2", single.Token, nil},
+		{"already a member", "user2", single.Token, nil},
+		{"used up", "user3", single.Token, errors.ErrInviteExhausted},
+		{"revoked", "user3", revoked.Token, errors.ErrInviteRevoked},
+		{"expired", "user3", signer.Sign(signing.PurposeInvite, expired.ID), errors.ErrInviteExpired},
+		{"unknown", "user3", signer.Sign(signing.PurposeInvite, "missing"), errors.ErrInviteNotFound},
+		{"tampered", "user3", single.Token + "x", errors.ErrInviteNotFound},
+		{"group full", "user3", open.Token, errors.ErrGroupMemberLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.do(t, MethodPOST, "/api/v1/invites/"+tt.token+"/join", basicAuthFor(tt.userID), nil, nil, tt.wantErr)
+		})
+	}
+
+	if invite, err := s.store.GetGroupInvite(single.ID); err != nil || invite.UseCount != 1 {
+		t.Errorf("got %v, %v, want the member's second join to leave the invite used once", invite, err)
+	}
+	if s.store.IsGroupMember(group.ID, "user3") {
+		t.Error("a refused join added user3")
+	}
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+
+	"github.com/gorilla/mux"
+)
+
+// LeaveGroup handles POST /groups/{groupId}/leave
+// When the owner leaves, ownership passes to the longest-standing admin,
+// or to the longest-standing member if there are no admins.
+func (h *Handler) LeaveGroup(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user from context
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	if !h.config.Features.EnableGroupChat {
+		respondWithError(w, errors.ErrGroupChatDisabled)
+		return
+	}
+
+	vars := mux.Vars(r)
+	group, member, appErr := h.memberGroup(vars["groupId"], authenticatedUserID)
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	if member.Role == database.GroupRoleOwner {
+		members, err := h.store.ListGroupMembers(group.ID)
+		if err != nil {
+			respondWithError(w, errors.ErrInternalError)
+			return
+		}
+		if successor := nextGroupOwner(members, authenticatedUserID); successor != nil {
+			if err := h.store.TransferGroupOwnership(group.ID, authenticatedUserID, successor.UserID); err != nil {
+				respondWithError(w, ownershipTransferError(err))
+				return
+			}
+			logger.Info(logger.TraceGroupRoleChanged, group.ID, successor.UserID, database.GroupRoleOwner)
+		}
+	}
+
+	if err := h.store.RemoveGroupMember(group.ID, authenticatedUserID); err != nil {
+		respondWithError(w, errors.ErrInternalError)
+		return
+	}
+	logger.Info(logger.TraceGroupMemberRemoved, group.ID, authenticatedUserID)
+
+	h.postSystemMessage(group.ID, database.SystemMemberLeft, &database.SystemPayload{
+		ActorID: authenticatedUserID,
+		UserIDs: []string{authenticatedUserID},
+	}, fmt.Sprintf(SysMsgMemberLeft, authenticatedUserID))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(GroupMembershipResponse{
+		GroupID: group.ID,
+		UserID:  authenticatedUserID,
+		Message: MsgGroupLeft,
+	})
+}
@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+
+	"github.com/gorilla/mux"
+)
+
+// ListGroupInvitesResponse represents the response for listing a group's invites
+type ListGroupInvitesResponse struct {
+	GroupID string            `json:"group_id"`
+	Invites []*InviteResponse `json:"invites"`
+}
+
+// ListGroupInvites handles GET /groups/{groupId}/invites
+// Only admins can list invites. Revoked, expired and used up invites are included.
+func (h *Handler) ListGroupInvites(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user from context
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	if !h.config.Features.EnableGroupChat {
+		respondWithError(w, errors.ErrGroupChatDisabled)
+		return
+	}
+
+	vars := mux.Vars(r)
+	group, _, appErr := h.adminGroup(vars["groupId"], authenticatedUserID)
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	invites, err := h.store.ListGroupInvites(group.ID)
+	if err != nil {
+		respondWithError(w, errors.ErrInternalError)
+		return
+	}
+
+	items := make([]*InviteResponse, 0, len(invites))
+	for _, invite := range invites {
+		items = append(items, h.inviteResponse(invite))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(ListGroupInvitesResponse{
+		GroupID: group.ID,
+		Invites: items,
+	})
+}
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+	"github.com/kasasunil/chat_app/internal/pkg/utils"
+)
+
+// LoginRequest represents the request to sign in. Username is the login name or the user's email.
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Login handles POST /auth/login
+// Checks the same credentials as Basic auth and starts a session for the device,
+// returning a short-lived access token and a refresh token. Needs no Authorization header.
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	var req LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, errors.ErrInvalidRequest)
+		return
+	}
+
+	user, appErr := h.credentials.Authenticate(req.Username, req.Password)
+	if appErr != nil {
+		logger.Warn(logger.TraceAuthFailed, req.Username)
+		respondWithError(w, appErr)
+		return
+	}
+
+	now := time.Now()
+	session := &database.Session{
+		ID:        utils.GenerateID(),
+		UserID:    user.ID,
+		ExpiresAt: now.Add(h.config.GetRefreshTokenTTL()),
+	}
+	refreshToken, refreshHash := h.tokens.IssueRefresh(session.ID)
+	session.RefreshHash = refreshHash
+	if err := h.store.CreateSession(session); err != nil {
+		logger.Error("Failed to create session: user=%s, error=%v", session.UserID, err)
+		respondWithError(w, errors.ErrInternalError)
+		return
+	}
+	logger.Info(logger.TraceLogin, session.UserID, session.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(h.tokenResponse(session, refreshToken, now))
+}
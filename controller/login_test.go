@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/auth"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/signing"
+)
+
+func TestLogin(t *testing.T) {
+	server := newTestServer(t)
+
+	tests := []struct {
+		name     string
+		request  interface{}
+		wantErr  *errors.AppError
+		wantUser string
+	}{
+		{"login name", LoginRequest{Username: "user1", Password: "password1"}, nil, "user1"},
+		{"email", LoginRequest{Username: "bob@example.com", Password: "password2"}, nil, "user2"},
+		{"email in another case", LoginRequest{Username: "Bob@Example.COM", Password: "password2"}, nil, "user2"},
+		{"wrong password", LoginRequest{Username: "user1", Password: "wrong"}, errors.ErrInvalidCredentials, ""},
+		{"another user's password", LoginRequest{Username: "bob@example.com", Password: "password1"}, errors.ErrInvalidCredentials, ""},
+		{"unknown login", LoginRequest{Username: "nobody", Password: "password1"}, errors.ErrInvalidCredentials, ""},
+		{"malformed body", "not an object", errors.ErrInvalidRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tokens TokenResponse
+			server.do(t, MethodPOST, EndpointLogin, "", tt.request, &tokens, tt.wantErr)
+			if tt.wantErr != nil {
+				return
+			}
+			if tokens.UserID != tt.wantUser {
+				t.Errorf("signed in as %q, want %q", tokens.UserID, tt.wantUser)
+			}
+			if tokens.AccessToken == "" || tokens.RefreshToken == "" || tokens.TokenType != middleware.AuthSchemeBearer {
+				t.Errorf("incomplete token response %+v", tokens)
+			}
+			server.do(t, MethodGET, EndpointMe, bearer(tokens.AccessToken), nil, nil, nil)
+			server.do(t, MethodGET, EndpointMe, basicAuth(tt.request.(LoginRequest).Username, tt.request.(LoginRequest).Password), nil, nil, nil)
+		})
+	}
+}
+
+func TestRefreshRotatesTheRefreshToken(t *testing.T) {
+	server := newTestServer(t)
+	first := server.login(t, "user1", "password1")
+
+	var rotated TokenResponse
+	server.do(t, MethodPOST, EndpointRefreshToken, "", RefreshTokenRequest{RefreshToken: first.RefreshToken}, &rotated, nil)
+	if rotated.SessionID != first.SessionID || rotated.RefreshToken == first.RefreshToken {
+		t.Fatalf("refresh gave session %s with the same token: %t", rotated.SessionID, rotated.RefreshToken == first.RefreshToken)
+	}
+	server.do(t, MethodGET, EndpointMe, bearer(rotated.AccessToken), nil, nil, nil)
+
+	// Presenting a traded refresh token again means it leaked: the whole session ends
+	server.do(t, MethodPOST, EndpointRefreshToken, "", RefreshTokenRequest{RefreshToken: first.RefreshToken}, nil, errors.ErrSessionRevoked)
+	server.do(t, MethodGET, EndpointMe, bearer(rotated.AccessToken), nil, nil, errors.ErrSessionRevoked)
+	server.do(t, MethodPOST, EndpointRefreshToken, "", RefreshTokenRequest{RefreshToken: rotated.RefreshToken}, nil, errors.ErrSessionRevoked)
+}
+
+func TestRefreshRejects(t *testing.T) {
+	server := newTestServer(t)
+	tokens := auth.NewTokens(signing.NewSigner(server.config.GetSigningKey()))
+	signedIn := server.login(t, "user1", "password1")
+
+	expired, expiredHash := tokens.IssueRefresh("session-expired")
+	if err := server.store.CreateSession(&database.Session{ID: "session-expired", UserID: "user1", RefreshHash: expiredHash, ExpiresAt: time.Now().Add(-time.Second)}); err != nil {
+		t.Fatal(err)
+	}
+	orphaned, orphanedHash := tokens.IssueRefresh("session-orphaned")
+	if err := server.store.CreateSession(&database.Session{ID: "session-orphaned", UserID: "user9", RefreshHash: orphanedHash, ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	unknown, _ := tokens.IssueRefresh("session-unknown")
+
+	tests := []struct {
+		name    string
+		request interface{}
+		wantErr *errors.AppError
+	}{
+		{"expired session", RefreshTokenRequest{RefreshToken: expired}, errors.ErrTokenExpired},
+		{"user no longer exists", RefreshTokenRequest{RefreshToken: orphaned}, errors.ErrAccountNotFound},
+		{"unknown session", RefreshTokenRequest{RefreshToken: unknown}, errors.ErrInvalidToken},
+		{"tampered token", RefreshTokenRequest{RefreshToken: signedIn.RefreshToken + "x"}, errors.ErrInvalidToken},
+		{"access token", RefreshTokenRequest{RefreshToken: signedIn.AccessToken}, errors.ErrInvalidToken},
+		{"missing token", RefreshTokenRequest{}, errors.ErrInvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.do(t, MethodPOST, EndpointRefreshToken, "", tt.request, nil, tt.wantErr)
+		})
+	}
+}
+
+func TestLogoutEndsOneSession(t *testing.T) {
+	server := newTestServer(t)
+	phone := server.login(t, "user1", "password1")
+	laptop := server.login(t, "user1", "password1")
+
+	server.do(t, MethodPOST, EndpointLogout, "", LogoutRequest{RefreshToken: phone.RefreshToken}, nil, nil)
+	server.do(t, MethodGET, EndpointMe, bearer(phone.AccessToken), nil, nil, errors.ErrSessionRevoked)
+	server.do(t, MethodPOST, EndpointRefreshToken, "", RefreshTokenRequest{RefreshToken: phone.RefreshToken}, nil, errors.ErrSessionRevoked)
+	// Logging out twice is allowed
+	server.do(t, MethodPOST, EndpointLogout, "", LogoutRequest{RefreshToken: phone.RefreshToken}, nil, nil)
+
+	server.do(t, MethodGET, EndpointMe, bearer(laptop.AccessToken), nil, nil, nil)
+	server.do(t, MethodPOST, EndpointRefreshToken, "", RefreshTokenRequest{RefreshToken: laptop.RefreshToken}, nil, nil)
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+)
+
+// LogoutRequest represents the request to sign a session out
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// LogoutResponse represents the response after signing out
+type LogoutResponse struct {
+	Message string `json:"message"`
+}
+
+// Logout handles POST /auth/logout
+// Signs out the session the refresh token belongs to. Its access tokens stop working
+// immediately, while the user's other devices stay signed in. Logging out twice is allowed.
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req LogoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		respondWithError(w, errors.ErrInvalidRequest)
+		return
+	}
+
+	session, _, appErr := h.sessionFromRefreshToken(req.RefreshToken)
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	if err := h.store.RevokeSession(session.ID); err != nil {
+		respondWithError(w, errors.ErrInternalError)
+		return
+	}
+	logger.Info(logger.TraceLogout, session.UserID, session.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(LogoutResponse{
+		Message: "Logged out",
+	})
+}
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+
+	"github.com/gorilla/mux"
+)
+
+// MarkConversationReadRequest represents the request to mark a conversation read.
+// Exactly one of UpToMessageID and UpTo must be set.
+type MarkConversationReadRequest struct {
+	UpToMessageID string     `json:"up_to_message_id,omitempty"` // Read everything up to and including this message
+	UpTo          *time.Time `json:"up_to,omitempty"`            // Read everything sent at or before this time
+}
+
+// MarkConversationReadResponse represents the response after marking a conversation read
+type MarkConversationReadResponse struct {
+	ConversationID string    `json:"conversation_id"`
+	ReadUpTo       time.Time `json:"read_up_to"`
+	MarkedRead     int       `json:"marked_read"` // Messages that left the unread count
+	UnreadCount    int       `json:"unread_count"`
+}
+
+// MarkConversationRead handles POST /conversations/{destinationId}/read
+// Moves the caller's read watermark forward: every message they received up to it counts
+// as read, and the conversation's participants get one read_up_to frame for all of them.
+// destinationId accepts the same values as GetMessages.
+func (h *Handler) MarkConversationRead(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user from context
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	var req MarkConversationReadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, errors.ErrInvalidRequest)
+		return
+	}
+	if (req.UpToMessageID == "") == (req.UpTo == nil) {
+		respondWithError(w, errors.ErrInvalidReadMarker)
+		return
+	}
+
+	vars := mux.Vars(r)
+	conversationID := h.resolveConversationID(authenticatedUserID, vars["destinationId"])
+
+	if appErr := h.authorizeConversation(authenticatedUserID, conversationID); appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	// A message marker must be a message of this conversation the caller can see.
+	// A time marker is capped at now, so messages sent later still arrive unread.
+	upTo := time.Now()
+	if req.UpToMessageID != "" {
+		message, err := h.store.GetMessage(req.UpToMessageID)
+		if err != nil || message.ConversationID != conversationID {
+			respondWithError(w, errors.ErrMessageNotFound)
+			return
+		}
+		if appErr := h.authorizeMessage(authenticatedUserID, message); appErr != nil {
+			respondWithError(w, appErr)
+			return
+		}
+		upTo = message.CreatedAt
+	} else if req.UpTo.Before(upTo) {
+		upTo = *req.UpTo
+	}
+
+	conversation, marked, err := h.store.MarkConversationRead(conversationID, authenticatedUserID, upTo)
+	if err != nil {
+		logger.Error("Failed to mark conversation read: conversation=%s, user=%s, error=%v", conversationID, authenticatedUserID, err)
+		respondWithError(w, errors.ErrInternalError)
+		return
+	}
+	logger.Info(logger.TraceConversationRead, conversationID, authenticatedUserID, upTo.Format(time.RFC3339Nano), marked)
+
+	response := MarkConversationReadResponse{
+		ConversationID: conversationID,
+		ReadUpTo:       upTo,
+		MarkedRead:     marked,
+	}
+	if conversation != nil {
+		response.UnreadCount = conversation.UnreadCount
+		if conversation.ReadUpTo != nil {
+			response.ReadUpTo = *conversation.ReadUpTo
+		}
+	}
+
+	// Senders learn of the read from the watermark; an older marker moved nothing
+	if response.ReadUpTo.Equal(upTo) {
+		h.wsManager.AckConversationRead(authenticatedUserID, conversationID, upTo)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(response)
+}
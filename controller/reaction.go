@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"github.com/kasasunil/chat_app/database"
+)
+
+// ReactionRequest represents the request to add or remove a reaction
+type ReactionRequest struct {
+	Emoji string `json:"emoji"`
+}
+
+// ReactionResponse represents a message's reactions after a change
+type ReactionResponse struct {
+	MessageID string                     `json:"message_id"`
+	Reactions []database.ReactionSummary `json:"reactions"`
+}
+
+// isValidReaction reports whether emoji is acceptable as a reaction:
+// a single emoji, no longer than MaxReactionLength bytes
+func isValidReaction(emoji string) bool {
+	return len(emoji) <= MaxReactionLength && isSingleEmoji(emoji)
+}
+
+// attachReactions sets the reaction summaries of messages as seen by userID.
+// The store hands out its own copies of messages, so they can be changed in place.
+func (h *Handler) attachReactions(messages []*database.Message, userID string) error {
+	messageIDs := make([]string, len(messages))
+	for i, msg := range messages {
+		messageIDs[i] = msg.ID
+	}
+	reactions, err := h.store.GetMessageReactions(messageIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range messages {
+		if len(reactions[msg.ID]) > 0 {
+			msg.Reactions = database.SummarizeReactions(reactions[msg.ID], userID)
+		}
+	}
+	return nil
+}
+
+// reactionSummary returns the reaction summary of a single message as seen by userID
+func (h *Handler) reactionSummary(messageID, userID string) ([]database.ReactionSummary, error) {
+	reactions, err := h.store.GetMessageReactions([]string{messageID})
+	if err != nil {
+		return nil, err
+	}
+	return database.SummarizeReactions(reactions[messageID], userID), nil
+}
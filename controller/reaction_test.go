@@ -0,0 +1,44 @@
+package controller
+
+import "testing"
+
+func TestIsValidReaction(t *testing.T) {
+	tests := []struct {
+		name  string
+		emoji string
+		want  bool
+	}{
+		{"pictograph", "👍", true},
+		{"text presentation", "❤", true},
+		{"emoji presentation", "❤️", true},
+		{"skin tone", "👍🏽", true},
+		{"zwj sequence", "👩‍💻", true},
+		{"family", "👨‍👩‍👧‍👦", true},
+		{"zwj with skin tone and vs16", "🏃🏽‍♀️", true},
+		{"flag", "🇳🇱", true},
+		{"tag sequence flag", "🏴\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F", true},
+		{"keycap", "1️⃣", true},
+		{"empty", "", false},
+		{"angle brackets", "<>", false},
+		{"dollars", "$$$", false},
+		{"exclamation marks", "!!!!", false},
+		{"letters", "ok", false},
+		{"bare digit", "1", false},
+		{"two emoji", "👍👍", false},
+		{"emoji and text", "👍a", false},
+		{"lone skin tone", "🏽", false},
+		{"lone regional indicator", "🇳", false},
+		{"three regional indicators", "🇳🇱🇳", false},
+		{"trailing joiner", "👩‍", false},
+		{"leading joiner", "‍👩", false},
+		{"unterminated tag sequence", "🏴\U000E0067\U000E0062", false},
+		{"too long", "👨‍👩‍👧‍👦‍👦‍👦", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isValidReaction(tt.emoji); got != tt.want {
+				t.Errorf("isValidReaction(%q) = %t, want %t", tt.emoji, got, tt.want)
+			}
+		})
+	}
+}
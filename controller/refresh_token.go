@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+)
+
+// RefreshTokenRequest represents the request to renew a session's tokens
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshToken handles POST /auth/refresh
+// Trades a refresh token for a new access token and a new refresh token; the old
+// refresh token stops working. Presenting a refresh token that was already traded
+// means it leaked, so the whole session is signed out.
+func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var req RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		respondWithError(w, errors.ErrInvalidRequest)
+		return
+	}
+
+	session, refreshHash, appErr := h.sessionFromRefreshToken(req.RefreshToken)
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	now := time.Now()
+	if appErr := sessionUseError(session, now); appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+	if _, err := h.store.GetUser(session.UserID); err != nil {
+		respondWithError(w, errors.ErrAccountNotFound)
+		return
+	}
+
+	newRefreshToken, newRefreshHash := h.tokens.IssueRefresh(session.ID)
+	rotated, err := h.store.RotateSession(session.ID, refreshHash, newRefreshHash, now.Add(h.config.GetRefreshTokenTTL()))
+	if err != nil {
+		// Another refresh may have won the race, or the session ended since it was loaded
+		current, getErr := h.store.GetSession(session.ID)
+		if getErr != nil {
+			respondWithError(w, errors.ErrInternalError)
+			return
+		}
+		if appErr := sessionUseError(current, time.Now()); appErr != nil {
+			respondWithError(w, appErr)
+			return
+		}
+		if current.RefreshHash != refreshHash {
+			if err := h.store.RevokeSession(session.ID); err != nil {
+				respondWithError(w, errors.ErrInternalError)
+				return
+			}
+			logger.Warn(logger.TraceRefreshReused, session.UserID, session.ID)
+			respondWithError(w, errors.ErrSessionRevoked)
+			return
+		}
+		respondWithError(w, errors.ErrInternalError)
+		return
+	}
+	logger.Info(logger.TraceTokenRefreshed, rotated.UserID, rotated.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(h.tokenResponse(rotated, newRefreshToken, now))
+}
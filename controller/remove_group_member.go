@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+
+	"github.com/gorilla/mux"
+)
+
+// GroupMembershipResponse represents the response after a member leaves or is removed
+type GroupMembershipResponse struct {
+	GroupID string `json:"group_id"`
+	UserID  string `json:"user_id"`
+	Message string `json:"message"`
+}
+
+// RemoveGroupMember handles DELETE /groups/{groupId}/members/{userId}
+// Admins can remove members; only the owner can remove admins, and the owner
+// cannot be removed at all (they leave instead, which hands ownership on).
+func (h *Handler) RemoveGroupMember(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user from context
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	if !h.config.Features.EnableGroupChat {
+		respondWithError(w, errors.ErrGroupChatDisabled)
+		return
+	}
+
+	vars := mux.Vars(r)
+	group, caller, appErr := h.adminGroup(vars["groupId"], authenticatedUserID)
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	userID := vars["userId"]
+	target, err := h.store.GetGroupMember(group.ID, userID)
+	if err != nil {
+		respondWithError(w, errors.ErrNotGroupMember)
+		return
+	}
+
+	switch {
+	case target.Role == database.GroupRoleOwner:
+		respondWithError(w, errors.ErrGroupOwnerProtected)
+		return
+	case target.Role == database.GroupRoleAdmin && caller.Role != database.GroupRoleOwner && target.UserID != caller.UserID:
+		respondWithError(w, errors.ErrNotGroupOwner)
+		return
+	}
+
+	if err := h.store.RemoveGroupMember(group.ID, userID); err != nil {
+		respondWithError(w, errors.ErrInternalError)
+		return
+	}
+	logger.Info(logger.TraceGroupMemberRemoved, group.ID, userID)
+
+	h.postSystemMessage(group.ID, database.SystemMemberRemoved, &database.SystemPayload{
+		ActorID: authenticatedUserID,
+		UserIDs: []string{userID},
+	}, fmt.Sprintf(SysMsgMemberRemoved, authenticatedUserID, userID))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(GroupMembershipResponse{
+		GroupID: group.ID,
+		UserID:  userID,
+		Message: MsgGroupMemberRemoved,
+	})
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+
+	"github.com/gorilla/mux"
+)
+
+// RemoveReaction handles DELETE /messages/{messageId}/reactions
+// Removes the caller's reaction; removing a missing reaction is a no-op.
+func (h *Handler) RemoveReaction(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user from context
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	var req ReactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, errors.ErrInvalidRequest)
+		return
+	}
+
+	if !isValidReaction(req.Emoji) {
+		logger.Warn(logger.TraceValidationFailed, FieldEmoji, "invalid")
+		respondWithError(w, errors.ErrInvalidReaction)
+		return
+	}
+
+	vars := mux.Vars(r)
+	message, err := h.store.GetMessage(vars["messageId"])
+	if err != nil {
+		respondWithError(w, errors.ErrMessageNotFound)
+		return
+	}
+
+	// Only participants of the message's conversation may react
+	if appErr := h.authorizeMessage(authenticatedUserID, message); appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	if err := h.store.RemoveReaction(message.ID, authenticatedUserID, req.Emoji); err != nil {
+		respondWithError(w, errors.ErrInternalError)
+		return
+	}
+
+	logger.Info(logger.TraceReactionRemoved, message.ID, authenticatedUserID, req.Emoji)
+
+	reactions, err := h.reactionSummary(message.ID, authenticatedUserID)
+	if err != nil {
+		respondWithError(w, errors.ErrInternalError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(ReactionResponse{
+		MessageID: message.ID,
+		Reactions: reactions,
+	})
+}
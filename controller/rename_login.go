@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+)
+
+// RenameLoginRequest represents the request to change the caller's login name
+type RenameLoginRequest struct {
+	LoginName string `json:"login_name"`
+}
+
+// isValidLoginName reports whether name can be a login name. Names never contain
+// '@', so they cannot be mistaken for an email when signing in.
+func isValidLoginName(name string) bool {
+	if len(name) < MinLoginNameLength || len(name) > MaxLoginNameLength {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// RenameLogin handles PUT /users/me/login-name
+// Changes the name the caller signs in with. The user ID stays the same, so
+// conversations, groups and signed-in sessions are unaffected; Basic auth clients
+// must switch to the new name.
+func (h *Handler) RenameLogin(w http.ResponseWriter, r *http.Request) {
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	var req RenameLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, errors.ErrInvalidRequest)
+		return
+	}
+	if !isValidLoginName(req.LoginName) {
+		respondWithError(w, errors.ErrInvalidLoginName)
+		return
+	}
+
+	credential, err := h.store.RenameLogin(authenticatedUserID, req.LoginName)
+	if err != nil {
+		switch err.Error() {
+		case database.ErrLoginNameTaken:
+			respondWithError(w, errors.ErrLoginNameTaken)
+		case database.ErrCredentialNotFound:
+			respondWithError(w, errors.ErrNotFound)
+		default:
+			logger.Error("Failed to rename login: user=%s, error=%v", authenticatedUserID, err)
+			respondWithError(w, errors.ErrInternalError)
+		}
+		return
+	}
+	logger.Info(logger.TraceLoginRenamed, credential.UserID, credential.LoginName)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(credential)
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+)
+
+func TestRenameLogin(t *testing.T) {
+	server := newTestServer(t)
+	session := server.login(t, "bob@example.com", "password2")
+
+	tests := []struct {
+		name      string
+		loginName string
+		wantErr   *errors.AppError
+	}{
+		{"too short", "bo", errors.ErrInvalidLoginName},
+		{"email-like", "b@d", errors.ErrInvalidLoginName},
+		{"space", "bob by", errors.ErrInvalidLoginName},
+		{"another user's login name", "user1", errors.ErrLoginNameTaken},
+		{"new name", "bobby", nil},
+		{"same name again", "bobby", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.do(t, MethodPUT, EndpointRenameLogin, bearer(session.AccessToken), RenameLoginRequest{LoginName: tt.loginName}, nil, tt.wantErr)
+		})
+	}
+
+	// The user ID and its sessions stay; only the name used to sign in changes
+	server.do(t, MethodGET, EndpointMe, basicAuth("user2", "password2"), nil, nil, errors.ErrInvalidCredentials)
+	var me MeResponse
+	server.do(t, MethodGET, EndpointMe, basicAuth("bobby", "password2"), nil, &me, nil)
+	if me.User == nil || me.ID != "user2" || me.LoginName != "bobby" {
+		t.Errorf("signed in as %+v, want user2 with login name bobby", me)
+	}
+	server.do(t, MethodGET, EndpointMe, bearer(session.AccessToken), nil, nil, nil)
+	server.login(t, "bob@example.com", "password2")
+}
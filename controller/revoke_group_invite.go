@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+
+	"github.com/gorilla/mux"
+)
+
+// RevokeGroupInvite handles DELETE /groups/{groupId}/invites/{inviteId}
+// Only admins can revoke invites. Revoking an already revoked invite succeeds.
+func (h *Handler) RevokeGroupInvite(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user from context
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	if !h.config.Features.EnableGroupChat {
+		respondWithError(w, errors.ErrGroupChatDisabled)
+		return
+	}
+
+	vars := mux.Vars(r)
+	group, _, appErr := h.adminGroup(vars["groupId"], authenticatedUserID)
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	// Invites of other groups are reported as missing
+	invite, err := h.store.GetGroupInvite(vars["inviteId"])
+	if err != nil || invite.GroupID != group.ID {
+		respondWithError(w, errors.ErrInviteNotFound)
+		return
+	}
+
+	if err := h.store.RevokeGroupInvite(invite.ID); err != nil {
+		respondWithError(w, errors.ErrInternalError)
+		return
+	}
+	logger.Info(logger.TraceInviteRevoked, group.ID, invite.ID, authenticatedUserID)
+
+	revoked, err := h.store.GetGroupInvite(invite.ID)
+	if err != nil {
+		respondWithError(w, errors.ErrInternalError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(h.inviteResponse(revoked))
+}
@@ -13,9 +13,11 @@ import (
 
 // SendMessageRequest represents the request to send a message
 type SendMessageRequest struct {
-	SenderID      string `json:"sender_id"`
-	DestinationID string `json:"destination_id"`
-	Message       string `json:"message"`
+	SenderID         string `json:"sender_id"`
+	DestinationID    string `json:"destination_id"`
+	Message          string `json:"message"`
+	ReplyToMessageID string `json:"reply_to_message_id,omitempty"` // Quote another message inline
+	ThreadRootID     string `json:"thread_root_id,omitempty"`      // Post as a reply in this message's thread
 }
 
 // SendMessageResponse represents the response after sending a message
@@ -67,15 +69,25 @@ func (h *Handler) SendMessage(w http.ResponseWriter, r *http.Request) {
 
 	// Determine conversation type
 	convType := database.ConversationTypeOneToOne
-	_, err = h.store.GetGroup(req.DestinationID)
+	group, err := h.store.GetGroup(req.DestinationID)
 	if err == nil {
 		// Destination is a group
+		if !h.config.Features.EnableGroupChat {
+			respondWithError(w, errors.ErrGroupChatDisabled)
+			return
+		}
 		convType = database.ConversationTypeGroup
 		// Verify sender is a member
-		if !h.store.IsGroupMember(req.DestinationID, senderID) {
+		member, err := h.store.GetGroupMember(req.DestinationID, senderID)
+		if err != nil {
 			respondWithError(w, errors.ErrNotGroupMember)
 			return
 		}
+		// Announcement-only groups accept messages from admins alone
+		if group.AnnouncementOnly && !member.Role.IsAdmin() {
+			respondWithError(w, errors.ErrAnnouncementOnly)
+			return
+		}
 	} else {
 		// Verify destination user exists
 		_, err = h.store.GetUser(req.DestinationID)
@@ -93,6 +105,28 @@ func (h *Handler) SendMessage(w http.ResponseWriter, r *http.Request) {
 		MessageText:      utils.SanitizeString(req.Message, 10000),
 		Status:           database.StatusSent,
 		ConversationType: convType,
+		ReplyToMessageID: req.ReplyToMessageID,
+	}
+	message.ConversationID = database.ConversationIDForMessage(message)
+
+	// Quoted messages and thread roots must belong to the same conversation
+	if req.ReplyToMessageID != "" {
+		if _, appErr := h.parentMessage(senderID, message.ConversationID, req.ReplyToMessageID); appErr != nil {
+			respondWithError(w, appErr)
+			return
+		}
+	}
+	if req.ThreadRootID != "" {
+		root, appErr := h.parentMessage(senderID, message.ConversationID, req.ThreadRootID)
+		if appErr != nil {
+			respondWithError(w, appErr)
+			return
+		}
+		// Threads are one level deep: replying inside a thread joins its root's thread
+		message.ThreadRootID = root.ID
+		if root.ThreadRootID != "" {
+			message.ThreadRootID = root.ThreadRootID
+		}
 	}
 
 	logger.Info(logger.TraceMessageSent, senderID, req.DestinationID, convType, message.ID)
@@ -102,18 +136,7 @@ func (h *Handler) SendMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Simulate sending message via WebSocket
-	if convType == database.ConversationTypeOneToOne {
-		h.wsManager.SendMessage(req.DestinationID, message)
-	} else {
-		// For group messages, send to all members
-		group, _ := h.store.GetGroup(req.DestinationID)
-		if group != nil {
-			// In real implementation, would iterate through group members
-			// For now, we just simulate the send
-			h.wsManager.SendMessage(req.DestinationID, message)
-		}
-	}
+	h.pushMessage(message)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated) // 201 Created - new resource created
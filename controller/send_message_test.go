@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+)
+
+func TestAnnouncementOnlyGroupsAcceptAdminsAlone(t *testing.T) {
+	s := newTestServer(t)
+	group := newTestGroup(t, s)
+	owner, member := basicAuthFor("user1"), basicAuthFor("user2")
+	announcementOnly := true
+	s.do(t, MethodPATCH, "/api/v1/groups/"+group.ID+"/settings", owner, UpdateGroupSettingsRequest{AnnouncementOnly: &announcementOnly}, nil, nil)
+
+	send := func(authorization string, wantErr *errors.AppError) {
+		t.Helper()
+		s.do(t, MethodPOST, EndpointSendMessage, authorization, SendMessageRequest{DestinationID: group.ID, Message: "news"}, nil, wantErr)
+	}
+	send(member, errors.ErrAnnouncementOnly)
+	announcement := s.send(t, owner, group.ID, "news from the owner")
+
+	// Members still read and acknowledge what admins post
+	s.do(t, MethodGET, "/api/v1/conversations/"+group.ID+"/messages", member, nil, nil, nil)
+	s.do(t, MethodPOST, EndpointAckRead, member, AckReadRequest{MessageID: announcement}, nil, nil)
+
+	// Promoted members can post
+	s.do(t, MethodPATCH, "/api/v1/groups/"+group.ID+"/members/user2", owner, SetGroupMemberRoleRequest{Role: database.GroupRoleAdmin}, nil, nil)
+	send(member, nil)
+}
+
+func TestLateJoinersCannotReplyToHiddenMessages(t *testing.T) {
+	s := newTestServer(t)
+	before, after := newLateJoinerGroup(t, s)
+
+	tests := []struct {
+		name    string
+		request SendMessageRequest
+		wantErr *errors.AppError
+	}{
+		{"quote a hidden message", SendMessageRequest{DestinationID: "group1", Message: "re", ReplyToMessageID: before}, errors.ErrInvalidParent},
+		{"thread under a hidden message", SendMessageRequest{DestinationID: "group1", Message: "re", ThreadRootID: before}, errors.ErrInvalidParent},
+		{"quote a visible message", SendMessageRequest{DestinationID: "group1", Message: "re", ReplyToMessageID: after}, nil},
+		{"thread under a visible message", SendMessageRequest{DestinationID: "group1", Message: "re", ThreadRootID: after}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.do(t, MethodPOST, EndpointSendMessage, basicAuthFor("user3"), tt.request, nil, tt.wantErr)
+		})
+	}
+
+	// Members who were there when it was sent can still reply to it
+	s.do(t, MethodPOST, EndpointSendMessage, basicAuthFor("user2"), SendMessageRequest{DestinationID: "group1", Message: "re", ThreadRootID: before}, nil, nil)
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+)
+
+// TokenResponse carries the tokens of a session. Send the access token as
+// "Authorization: Bearer <access_token>"; trade the refresh token at /auth/refresh
+// for a new pair before the access token expires.
+type TokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int    `json:"expires_in"` // Seconds until the access token expires
+	RefreshToken     string `json:"refresh_token"`
+	RefreshExpiresIn int    `json:"refresh_expires_in"` // Seconds until the refresh token expires
+	SessionID        string `json:"session_id"`
+	UserID           string `json:"user_id"`
+}
+
+// tokenResponse issues an access token for session, paired with its current refresh token
+func (h *Handler) tokenResponse(session *database.Session, refreshToken string, now time.Time) TokenResponse {
+	accessTTL := h.config.GetAccessTokenTTL()
+	return TokenResponse{
+		AccessToken:      h.tokens.IssueAccess(session.ID, session.UserID, now.Add(accessTTL)),
+		TokenType:        middleware.AuthSchemeBearer,
+		ExpiresIn:        int(accessTTL.Seconds()),
+		RefreshToken:     refreshToken,
+		RefreshExpiresIn: int(session.ExpiresAt.Sub(now).Seconds()),
+		SessionID:        session.ID,
+		UserID:           session.UserID,
+	}
+}
+
+// sessionFromRefreshToken resolves a refresh token to its session and the hash the
+// store must hold for it. Tokens that were tampered with are treated as unknown.
+func (h *Handler) sessionFromRefreshToken(token string) (*database.Session, string, *errors.AppError) {
+	sessionID, refreshHash, err := h.tokens.VerifyRefresh(token)
+	if err != nil {
+		return nil, "", errors.ErrInvalidToken
+	}
+	session, err := h.store.GetSession(sessionID)
+	if err != nil {
+		return nil, "", errors.ErrInvalidToken
+	}
+	return session, refreshHash, nil
+}
+
+// sessionUseError explains why a session cannot be refreshed at now, or returns nil
+func sessionUseError(session *database.Session, now time.Time) *errors.AppError {
+	switch {
+	case session.RevokedAt != nil:
+		return errors.ErrSessionRevoked
+	case !session.IsActive(now):
+		return errors.ErrTokenExpired
+	}
+	return nil
+}
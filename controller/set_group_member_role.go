@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+
+	"github.com/gorilla/mux"
+)
+
+// SetGroupMemberRoleRequest represents the request to change a member's role
+type SetGroupMemberRoleRequest struct {
+	Role database.GroupRole `json:"role"`
+}
+
+// SetGroupMemberRole handles PATCH /groups/{groupId}/members/{userId}
+// Admins can promote members to admin. Only the owner can demote admins or
+// hand over ownership; assigning "owner" makes the previous owner an admin.
+func (h *Handler) SetGroupMemberRole(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user from context
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	if !h.config.Features.EnableGroupChat {
+		respondWithError(w, errors.ErrGroupChatDisabled)
+		return
+	}
+
+	var req SetGroupMemberRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, errors.ErrInvalidRequest)
+		return
+	}
+
+	switch req.Role {
+	case database.GroupRoleOwner, database.GroupRoleAdmin, database.GroupRoleMember:
+	default:
+		logger.Warn(logger.TraceValidationFailed, FieldRole, "invalid")
+		respondWithError(w, errors.ErrInvalidGroupRole)
+		return
+	}
+
+	vars := mux.Vars(r)
+	group, caller, appErr := h.adminGroup(vars["groupId"], authenticatedUserID)
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	target, err := h.store.GetGroupMember(group.ID, vars["userId"])
+	if err != nil {
+		respondWithError(w, errors.ErrNotGroupMember)
+		return
+	}
+
+	switch {
+	case target.Role == req.Role:
+		// Nothing to change
+	case target.Role == database.GroupRoleOwner:
+		respondWithError(w, errors.ErrGroupOwnerProtected)
+		return
+	case req.Role == database.GroupRoleOwner:
+		if caller.Role != database.GroupRoleOwner {
+			respondWithError(w, errors.ErrNotGroupOwner)
+			return
+		}
+		// Hand over ownership; the previous owner stays on as an admin
+		if err := h.store.TransferGroupOwnership(group.ID, caller.UserID, target.UserID); err != nil {
+			respondWithError(w, ownershipTransferError(err))
+			return
+		}
+		logger.Info(logger.TraceGroupRoleChanged, group.ID, target.UserID, database.GroupRoleOwner)
+		logger.Info(logger.TraceGroupRoleChanged, group.ID, caller.UserID, database.GroupRoleAdmin)
+	default:
+		if target.Role == database.GroupRoleAdmin && caller.Role != database.GroupRoleOwner {
+			respondWithError(w, errors.ErrNotGroupOwner)
+			return
+		}
+		if err := h.store.SetGroupMemberRole(group.ID, target.UserID, req.Role); err != nil {
+			respondWithError(w, errors.ErrInternalError)
+			return
+		}
+		logger.Info(logger.TraceGroupRoleChanged, group.ID, target.UserID, req.Role)
+	}
+
+	response, appErr := h.groupResponse(group)
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/pkg/auth"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+	"github.com/kasasunil/chat_app/internal/pkg/utils"
+)
+
+// SignUpRequest represents the request to register a new user
+type SignUpRequest struct {
+	LoginName  string `json:"login_name"`
+	Password   string `json:"password"`
+	Email      string `json:"email"`
+	Name       string `json:"name"`
+	StatusText string `json:"status_text"`
+	AvatarRef  string `json:"avatar_ref"`
+}
+
+// SignUp handles POST /auth/signup
+// Creates a user and the credential they sign in with. Needs no Authorization
+// header; the new user then signs in through /auth/login or Basic auth.
+func (h *Handler) SignUp(w http.ResponseWriter, r *http.Request) {
+	var req SignUpRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, errors.ErrInvalidRequest)
+		return
+	}
+
+	if !isValidLoginName(req.LoginName) {
+		logger.Warn(logger.TraceValidationFailed, FieldLoginName, "invalid")
+		respondWithError(w, errors.ErrInvalidLoginName)
+		return
+	}
+	if len(req.Password) < MinPasswordLength || len(req.Password) > MaxPasswordLength {
+		logger.Warn(logger.TraceValidationFailed, FieldPassword, "invalid length")
+		respondWithError(w, errors.ErrInvalidPassword)
+		return
+	}
+	user := &database.User{ID: utils.GenerateID()}
+	var appErr *errors.AppError
+	if user.Email, appErr = validateEmail(req.Email); appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+	if user.Name, appErr = validateName(req.Name); appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+	if user.StatusText, appErr = validateStatusText(req.StatusText); appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+	if user.AvatarRef, appErr = validateAvatarRef(req.AvatarRef); appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	passwordHash, err := auth.HashPassword(req.Password, auth.DefaultPasswordCost)
+	if err != nil {
+		logger.Error("Failed to hash password: %v", err)
+		respondWithError(w, errors.ErrInternalError)
+		return
+	}
+	credential := &database.Credential{LoginName: req.LoginName, PasswordHash: passwordHash}
+	if err := h.store.CreateUserWithCredential(user, credential); err != nil {
+		respondWithError(w, userStoreError(err))
+		return
+	}
+	logger.Info(logger.TraceUserCreated, user.ID, user.Name)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated) // 201 Created - new resource created
+	json.NewEncoder(w).Encode(MeResponse{User: user, LoginName: credential.LoginName})
+}
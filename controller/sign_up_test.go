@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+)
+
+func TestSignUp(t *testing.T) {
+	server := newTestServer(t)
+
+	tests := []struct {
+		name    string
+		request interface{}
+		wantErr *errors.AppError
+	}{
+		{"new user", SignUpRequest{LoginName: "dave", Password: "password4", Email: "dave@example.com", Name: "Dave"}, nil},
+		{"taken email", SignUpRequest{LoginName: "bobby", Password: "password4", Email: "bob@example.com", Name: "Bobby"}, errors.ErrUserAlreadyExists},
+		{"taken email in another case", SignUpRequest{LoginName: "bobby", Password: "password4", Email: "BOB@Example.com", Name: "Bobby"}, errors.ErrUserAlreadyExists},
+		{"taken login name", SignUpRequest{LoginName: "user1", Password: "password4", Email: "eve@example.com", Name: "Eve"}, errors.ErrLoginNameTaken},
+		{"invalid email", SignUpRequest{LoginName: "eve", Password: "password4", Email: "not-an-email", Name: "Eve"}, errors.ErrInvalidEmail},
+		{"weak password", SignUpRequest{LoginName: "eve", Password: "short", Email: "eve@example.com", Name: "Eve"}, errors.ErrInvalidPassword},
+		{"malformed body", "not an object", errors.ErrInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var me MeResponse
+			server.do(t, MethodPOST, EndpointSignUp, "", tt.request, &me, tt.wantErr)
+			if tt.wantErr != nil {
+				return
+			}
+			request := tt.request.(SignUpRequest)
+			if me.User == nil || me.User.Email != request.Email || me.LoginName != request.LoginName {
+				t.Errorf("signed up as %+v, want %s with login name %s", me, request.Email, request.LoginName)
+			}
+			server.login(t, request.LoginName, request.Password)
+		})
+	}
+}
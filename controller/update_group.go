@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+	"github.com/kasasunil/chat_app/internal/pkg/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// UpdateGroupRequest represents the request to rename a group or change its description.
+// Omitted fields are left unchanged.
+type UpdateGroupRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+// UpdateGroup handles PATCH /groups/{groupId}
+// Only admins can change group details.
+func (h *Handler) UpdateGroup(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user from context
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	if !h.config.Features.EnableGroupChat {
+		respondWithError(w, errors.ErrGroupChatDisabled)
+		return
+	}
+
+	var req UpdateGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, errors.ErrInvalidRequest)
+		return
+	}
+
+	vars := mux.Vars(r)
+	group, _, appErr := h.adminGroup(vars["groupId"], authenticatedUserID)
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	// Only the fields in the request are saved, so a concurrent settings change is kept
+	var update database.GroupUpdate
+	if req.Name != nil {
+		if utils.IsEmpty(*req.Name) {
+			logger.Warn(logger.TraceValidationFailed, FieldGroupName, "empty")
+			respondWithError(w, errors.ErrGroupNameRequired)
+			return
+		}
+		name := utils.SanitizeString(*req.Name, MaxGroupNameLength)
+		update.Name = &name
+	}
+	if req.Description != nil {
+		description := utils.SanitizeString(*req.Description, MaxGroupDescLength)
+		update.Description = &description
+	}
+
+	updated, err := h.store.UpdateGroup(group.ID, update)
+	if err != nil {
+		respondWithError(w, errors.ErrInternalError)
+		return
+	}
+
+	if update.Name != nil && *update.Name != group.Name {
+		h.postSystemMessage(updated.ID, database.SystemGroupRenamed, &database.SystemPayload{
+			ActorID:      authenticatedUserID,
+			Name:         *update.Name,
+			PreviousName: group.Name,
+		}, fmt.Sprintf(SysMsgGroupRenamed, authenticatedUserID, *update.Name))
+	}
+
+	response, appErr := h.groupResponse(updated)
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+
+	"github.com/gorilla/mux"
+)
+
+// UpdateGroupSettingsRequest represents the request to change group settings.
+// Omitted fields are left unchanged.
+type UpdateGroupSettingsRequest struct {
+	AnnouncementOnly  *bool                       `json:"announcement_only"`
+	HistoryVisibility *database.HistoryVisibility `json:"history_visibility"`
+}
+
+// settingChange is a changed setting and the system message that records it
+type settingChange struct {
+	payload *database.SystemPayload
+	text    string
+}
+
+// UpdateGroupSettings handles PATCH /groups/{groupId}/settings
+// Only admins can change settings; each change is recorded as a system message.
+func (h *Handler) UpdateGroupSettings(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user from context
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	if !h.config.Features.EnableGroupChat {
+		respondWithError(w, errors.ErrGroupChatDisabled)
+		return
+	}
+
+	var req UpdateGroupSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, errors.ErrInvalidRequest)
+		return
+	}
+
+	if req.HistoryVisibility != nil && !req.HistoryVisibility.IsValid() {
+		logger.Warn(logger.TraceValidationFailed, FieldVisibility, "unknown value")
+		respondWithError(w, errors.ErrInvalidVisibility)
+		return
+	}
+
+	vars := mux.Vars(r)
+	group, _, appErr := h.adminGroup(vars["groupId"], authenticatedUserID)
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	// Only changed settings are saved, so a concurrent rename is kept.
+	// Each changed setting is recorded as its own system message.
+	var update database.GroupUpdate
+	changes := make([]settingChange, 0, 2)
+	if req.AnnouncementOnly != nil && *req.AnnouncementOnly != group.AnnouncementOnly {
+		update.AnnouncementOnly = req.AnnouncementOnly
+		text := SysMsgAnnouncementOnlyOff
+		if *req.AnnouncementOnly {
+			text = SysMsgAnnouncementOnlyOn
+		}
+		changes = append(changes, settingChange{
+			payload: &database.SystemPayload{ActorID: authenticatedUserID, AnnouncementOnly: req.AnnouncementOnly},
+			text:    fmt.Sprintf(text, authenticatedUserID),
+		})
+	}
+	if req.HistoryVisibility != nil && *req.HistoryVisibility != group.HistoryVisibility {
+		update.HistoryVisibility = req.HistoryVisibility
+		text := SysMsgHistoryFull
+		if *req.HistoryVisibility == database.HistoryVisibilitySinceJoined {
+			text = SysMsgHistorySinceJoined
+		}
+		changes = append(changes, settingChange{
+			payload: &database.SystemPayload{ActorID: authenticatedUserID, HistoryVisibility: *req.HistoryVisibility},
+			text:    fmt.Sprintf(text, authenticatedUserID),
+		})
+	}
+
+	if len(changes) > 0 {
+		updated, err := h.store.UpdateGroup(group.ID, update)
+		if err != nil {
+			respondWithError(w, errors.ErrInternalError)
+			return
+		}
+		logger.Info(logger.TraceGroupSettings, updated.ID, authenticatedUserID, updated.AnnouncementOnly, updated.HistoryVisibility)
+		group = updated
+
+		for _, change := range changes {
+			h.postSystemMessage(group.ID, database.SystemSettingsChanged, change.payload, change.text)
+		}
+	}
+
+	response, appErr := h.groupResponse(group)
+	if appErr != nil {
+		respondWithError(w, appErr)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(response)
+}
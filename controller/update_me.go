@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+)
+
+// UpdateMeRequest represents the request to change the caller's profile.
+// Omitted fields are left unchanged; an empty status_text or avatar_ref clears it.
+type UpdateMeRequest struct {
+	Name       *string `json:"name"`
+	Email      *string `json:"email"`
+	StatusText *string `json:"status_text"`
+	AvatarRef  *string `json:"avatar_ref"`
+}
+
+// UpdateMe handles PATCH /users/me
+// The email must stay unique; the login name is changed through /users/me/login-name.
+func (h *Handler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	authenticatedUserID := middleware.GetUserID(r)
+	if authenticatedUserID == "" {
+		respondWithError(w, errors.ErrAuthRequired)
+		return
+	}
+
+	var req UpdateMeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, errors.ErrInvalidRequest)
+		return
+	}
+
+	user, err := h.store.GetUser(authenticatedUserID)
+	if err != nil {
+		logger.Warn(logger.TraceUserNotFound, authenticatedUserID)
+		respondWithError(w, errors.ErrUserNotFound)
+		return
+	}
+
+	// Work on a copy; the store may hand out its own record, which the update overwrites
+	updated := *user
+	var appErr *errors.AppError
+	if req.Name != nil {
+		if updated.Name, appErr = validateName(*req.Name); appErr != nil {
+			respondWithError(w, appErr)
+			return
+		}
+	}
+	if req.Email != nil {
+		if updated.Email, appErr = validateEmail(*req.Email); appErr != nil {
+			respondWithError(w, appErr)
+			return
+		}
+	}
+	if req.StatusText != nil {
+		if updated.StatusText, appErr = validateStatusText(*req.StatusText); appErr != nil {
+			respondWithError(w, appErr)
+			return
+		}
+	}
+	if req.AvatarRef != nil {
+		if updated.AvatarRef, appErr = validateAvatarRef(*req.AvatarRef); appErr != nil {
+			respondWithError(w, appErr)
+			return
+		}
+	}
+
+	if err := h.store.UpdateUser(&updated); err != nil {
+		respondWithError(w, userStoreError(err))
+		return
+	}
+	logger.Info(logger.TraceUserUpdated, updated.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK
+	json.NewEncoder(w).Encode(h.meResponse(&updated))
+}
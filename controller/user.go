@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"net/mail"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+)
+
+// UserProfile is what any signed-in user can see of another user
+type UserProfile struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	StatusText string `json:"status_text"`
+	AvatarRef  string `json:"avatar_ref"`
+}
+
+// MeResponse is the caller's own user record, with the login name they sign in with
+type MeResponse struct {
+	*database.User
+	LoginName string `json:"login_name,omitempty"`
+}
+
+func publicProfile(user *database.User) UserProfile {
+	return UserProfile{
+		ID:         user.ID,
+		Name:       user.Name,
+		StatusText: user.StatusText,
+		AvatarRef:  user.AvatarRef,
+	}
+}
+
+// meResponse pairs user with their login name; users without a credential have none
+func (h *Handler) meResponse(user *database.User) MeResponse {
+	response := MeResponse{User: user}
+	if credential, err := h.store.GetCredential(user.ID); err == nil {
+		response.LoginName = credential.LoginName
+	}
+	return response
+}
+
+// userStoreError maps the store's errors for creating or updating a user to API errors
+func userStoreError(err error) *errors.AppError {
+	switch err.Error() {
+	case database.ErrEmailTaken, database.ErrUserAlreadyExists:
+		return errors.ErrUserAlreadyExists
+	case database.ErrLoginNameTaken:
+		return errors.ErrLoginNameTaken
+	case database.ErrUserNotFound:
+		return errors.ErrUserNotFound
+	}
+	logger.Error("Failed to save user: %v", err)
+	return errors.ErrInternalError
+}
+
+// hasControlCharacters reports whether s contains control characters such as newlines
+func hasControlCharacters(s string) bool {
+	return strings.IndexFunc(s, unicode.IsControl) != -1
+}
+
+// validateName returns the display name to store for name
+func validateName(name string) (string, *errors.AppError) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxDisplayNameLength || hasControlCharacters(name) {
+		logger.Warn(logger.TraceValidationFailed, FieldName, "invalid")
+		return "", errors.ErrInvalidName
+	}
+	return name, nil
+}
+
+// validateEmail returns the email to store for email. Only plain addresses are
+// accepted, not forms such as "Alice <alice@example.com>".
+func validateEmail(email string) (string, *errors.AppError) {
+	email = strings.TrimSpace(email)
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email || len(email) > MaxEmailLength {
+		logger.Warn(logger.TraceValidationFailed, FieldEmail, "invalid")
+		return "", errors.ErrInvalidEmail
+	}
+	return email, nil
+}
+
+// validateStatusText returns the status text to store for text; empty clears it
+func validateStatusText(text string) (string, *errors.AppError) {
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) > MaxStatusTextLength || hasControlCharacters(text) {
+		logger.Warn(logger.TraceValidationFailed, FieldStatusText, "invalid")
+		return "", errors.ErrInvalidStatusText
+	}
+	return text, nil
+}
+
+// validateAvatarRef returns the avatar reference to store for ref; empty clears it.
+// A reference is an http(s) URL or an opaque media ID.
+func validateAvatarRef(ref string) (string, *errors.AppError) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", nil
+	}
+	parsed, err := url.Parse(ref)
+	valid := err == nil && len(ref) <= MaxAvatarRefLength &&
+		strings.IndexFunc(ref, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }) == -1
+	if valid && parsed.Scheme != "" {
+		valid = (parsed.Scheme == "https" || parsed.Scheme == "http") && parsed.Host != ""
+	}
+	if !valid {
+		logger.Warn(logger.TraceValidationFailed, FieldAvatarRef, "invalid")
+		return "", errors.ErrInvalidAvatarRef
+	}
+	return ref, nil
+}
@@ -13,6 +13,12 @@ const (
 	ConversationTypeGroupString    = "group"
 )
 
+// Canonical one-to-one conversation IDs look like "dm:<userA>:<userB>" with the pair sorted
+const (
+	OneToOneConversationPrefix    = "dm:"
+	OneToOneConversationSeparator = ":"
+)
+
 // Default values
 const (
 	DefaultMessageLimit      = 50
@@ -25,28 +31,76 @@ const (
 
 // Database operation names
 const (
-	OpCreateUser           = "CreateUser"
-	OpGetUser              = "GetUser"
-	OpCreateGroup          = "CreateGroup"
-	OpGetGroup             = "GetGroup"
-	OpAddGroupMember       = "AddGroupMember"
-	OpIsGroupMember        = "IsGroupMember"
-	OpCreateMessage        = "CreateMessage"
-	OpGetMessage           = "GetMessage"
-	OpGetMessages          = "GetMessages"
-	OpUpdateMessageStatus  = "UpdateMessageStatus"
-	OpCreateMessageRead    = "CreateMessageRead"
-	OpGetMessageReads      = "GetMessageReads"
-	OpGetUserConversations = "GetUserConversations"
-	OpSearchMessages       = "SearchMessages"
+	OpCreateUser               = "CreateUser"
+	OpGetUser                  = "GetUser"
+	OpUpdateUser               = "UpdateUser"
+	OpCreateUserWithCredential = "CreateUserWithCredential"
+	OpCreateCredential         = "CreateCredential"
+	OpGetCredential            = "GetCredential"
+	OpGetCredentialByLogin     = "GetCredentialByLogin"
+	OpSetCredentialPassword    = "SetCredentialPassword"
+	OpRenameLogin              = "RenameLogin"
+	OpCreateGroup              = "CreateGroup"
+	OpCreateGroupWithMembers   = "CreateGroupWithMembers"
+	OpGetGroup                 = "GetGroup"
+	OpUpdateGroup              = "UpdateGroup"
+	OpAddGroupMember           = "AddGroupMember"
+	OpAddGroupMembers          = "AddGroupMembers"
+	OpRemoveGroupMember        = "RemoveGroupMember"
+	OpIsGroupMember            = "IsGroupMember"
+	OpGetGroupMember           = "GetGroupMember"
+	OpSetGroupMemberRole       = "SetGroupMemberRole"
+	OpTransferGroupOwnership   = "TransferGroupOwnership"
+	OpListGroupMembers         = "ListGroupMembers"
+	OpCreateGroupInvite        = "CreateGroupInvite"
+	OpGetGroupInvite           = "GetGroupInvite"
+	OpListGroupInvites         = "ListGroupInvites"
+	OpRevokeGroupInvite        = "RevokeGroupInvite"
+	OpJoinGroupWithInvite      = "JoinGroupWithInvite"
+	OpCreateSession            = "CreateSession"
+	OpGetSession               = "GetSession"
+	OpRotateSession            = "RotateSession"
+	OpRevokeSession            = "RevokeSession"
+	OpCreateMessage            = "CreateMessage"
+	OpGetMessage               = "GetMessage"
+	OpGetMessages              = "GetMessages"
+	OpGetThreadMessages        = "GetThreadMessages"
+	OpUpdateMessageStatus      = "UpdateMessageStatus"
+	OpUpdateMessage            = "UpdateMessage"
+	OpGetMessageRevisions      = "GetMessageRevisions"
+	OpDeleteMessageForEveryone = "DeleteMessageForEveryone"
+	OpDeleteMessageForUser     = "DeleteMessageForUser"
+	OpAddReaction              = "AddReaction"
+	OpRemoveReaction           = "RemoveReaction"
+	OpGetMessageReactions      = "GetMessageReactions"
+	OpCreateMessageDelivery    = "CreateMessageDelivery"
+	OpGetMessageDeliveries     = "GetMessageDeliveries"
+	OpCreateMessageRead        = "CreateMessageRead"
+	OpGetMessageReads          = "GetMessageReads"
+	OpGetUserConversations     = "GetUserConversations"
+	OpSearchMessages           = "SearchMessages"
 )
 
 // Error messages
 const (
-	ErrUserAlreadyExists  = "user already exists"
-	ErrUserNotFound       = "user not found"
-	ErrGroupAlreadyExists = "group already exists"
-	ErrGroupNotFound      = "group not found"
-	ErrMessageNotFound    = "message not found"
-	ErrInvalidCursor      = "invalid cursor"
+	ErrUserAlreadyExists   = "user already exists"
+	ErrUserNotFound        = "user not found"
+	ErrInvalidUserID       = "invalid user id"
+	ErrEmailTaken          = "email already in use"
+	ErrGroupAlreadyExists  = "group already exists"
+	ErrGroupNotFound       = "group not found"
+	ErrGroupMemberNotFound = "group member not found"
+	ErrNotGroupOwner       = "not the group owner"
+	ErrAlreadyGroupMember  = "already a group member"
+	ErrGroupFull           = "group member limit reached"
+	ErrInviteNotFound      = "invite not found"
+	ErrInviteRevoked       = "invite revoked"
+	ErrInviteExpired       = "invite expired"
+	ErrInviteExhausted     = "invite used up"
+	ErrMessageNotFound     = "message not found"
+	ErrMessageDeleted      = "message deleted"
+	ErrSystemMessage       = "system messages cannot be changed"
+	ErrInvalidCursor       = "invalid cursor"
+	ErrCredentialNotFound  = "credential not found"
+	ErrLoginNameTaken      = "login name already taken"
 )
@@ -0,0 +1,154 @@
+package database
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// OneToOneConversationID returns the canonical conversation key for a chat between two users.
+// The pair is sorted so both participants map to the same key regardless of who sent first.
+func OneToOneConversationID(userA, userB string) string {
+	if userB < userA {
+		userA, userB = userB, userA
+	}
+	return OneToOneConversationPrefix + userA + OneToOneConversationSeparator + userB
+}
+
+// ParseOneToOneConversationID returns the two participants of a canonical one-to-one key
+func ParseOneToOneConversationID(conversationID string) (string, string, bool) {
+	if !strings.HasPrefix(conversationID, OneToOneConversationPrefix) {
+		return "", "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(conversationID, OneToOneConversationPrefix), OneToOneConversationSeparator, 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// IsValidUserID reports whether userID can name a user. IDs cannot contain the
+// separator, or the two participants of a one-to-one key could not be told apart.
+func IsValidUserID(userID string) bool {
+	return userID != "" && !strings.Contains(userID, OneToOneConversationSeparator)
+}
+
+// IsOneToOneConversationID reports whether conversationID is a canonical one-to-one key
+func IsOneToOneConversationID(conversationID string) bool {
+	_, _, ok := ParseOneToOneConversationID(conversationID)
+	return ok
+}
+
+// ConversationIDForMessage returns the conversation a message belongs to:
+// the group ID for group messages, the canonical pair key for one-to-one messages
+func ConversationIDForMessage(message *Message) string {
+	if message.ConversationType == ConversationTypeGroup {
+		return message.DestinationID
+	}
+	return OneToOneConversationID(message.SenderID, message.DestinationID)
+}
+
+// MessageRecipients returns the users a message is addressed to, given the
+// members of its group (ignored for one-to-one messages). The sender is never a recipient.
+func MessageRecipients(message *Message, groupMembers []string) []string {
+	if message.ConversationType == ConversationTypeOneToOne {
+		return []string{message.DestinationID}
+	}
+	recipients := make([]string, 0, len(groupMembers))
+	for _, memberID := range groupMembers {
+		if memberID != message.SenderID {
+			recipients = append(recipients, memberID)
+		}
+	}
+	return recipients
+}
+
+// CountsAsUnread reports whether message adds to userID's unread count: top-level
+// user messages from someone else that were not deleted for everyone.
+// Thread replies and system messages never count.
+func CountsAsUnread(message *Message, userID string) bool {
+	return message.SenderID != userID &&
+		message.Kind != MessageKindSystem &&
+		message.ThreadRootID == "" &&
+		message.DeletedAt == nil
+}
+
+// AggregateMessageStatus returns the sender-visible status of a message:
+// DELIVERED only once every recipient has received it, READ only once every
+// recipient has read it. The status never moves backwards.
+func AggregateMessageStatus(current MessageStatus, recipients []string, delivered, read map[string]bool) MessageStatus {
+	if len(recipients) == 0 {
+		return current
+	}
+
+	allDelivered, allRead := true, true
+	for _, userID := range recipients {
+		if !read[userID] {
+			allRead = false
+		}
+		if !delivered[userID] && !read[userID] {
+			allDelivered = false
+		}
+	}
+
+	status := current
+	switch {
+	case allRead:
+		status = StatusRead
+	case allDelivered && current == StatusSent:
+		status = StatusDelivered
+	}
+	return status
+}
+
+// SummarizeReactions aggregates a message's reactions per emoji as seen by userID.
+// The most used emojis come first; ties keep the order in which emojis were first used.
+func SummarizeReactions(reactions []*Reaction, userID string) []ReactionSummary {
+	ordered := make([]*Reaction, len(reactions))
+	copy(ordered, reactions)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].CreatedAt.Before(ordered[j].CreatedAt)
+	})
+
+	index := make(map[string]int)
+	summaries := make([]ReactionSummary, 0)
+	for _, reaction := range ordered {
+		i, exists := index[reaction.Emoji]
+		if !exists {
+			i = len(summaries)
+			index[reaction.Emoji] = i
+			summaries = append(summaries, ReactionSummary{Emoji: reaction.Emoji})
+		}
+		summaries[i].Count++
+		if reaction.UserID == userID {
+			summaries[i].ReactedByMe = true
+		}
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Count > summaries[j].Count
+	})
+	return summaries
+}
+
+// GroupMemberIDs returns the user IDs of members
+func GroupMemberIDs(members []*GroupMember) []string {
+	userIDs := make([]string, len(members))
+	for i, member := range members {
+		userIDs[i] = member.UserID
+	}
+	return userIDs
+}
+
+// GroupMemberIDsJoinedBy returns the user IDs of members who had joined by at.
+// Members who joined after a message was sent never receive or read it, so they
+// are not among its recipients.
+func GroupMemberIDsJoinedBy(members []*GroupMember, at time.Time) []string {
+	userIDs := make([]string, 0, len(members))
+	for _, member := range members {
+		if !member.JoinedAt.After(at) {
+			userIDs = append(userIDs, member.UserID)
+		}
+	}
+	return userIDs
+}
@@ -0,0 +1,61 @@
+package in_memory
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/database/repositorytest"
+)
+
+// benchUser receives every message; benchConversations one-to-one chats are seeded for them
+const (
+	benchUser          = "user1"
+	benchConversations = 10
+	benchPageSize      = 50
+)
+
+// Total stored messages, spread evenly over the conversations
+var benchTotals = []int{10000, 100000, 1000000}
+
+// BenchmarkAckRead records read receipts spread over the whole store as it grows
+func BenchmarkAckRead(b *testing.B) {
+	for _, total := range benchTotals {
+		store := NewStore()
+		ids := repositorytest.SeedInbox(b, store, benchUser, benchConversations, total/benchConversations, 0)
+
+		b.Run(fmt.Sprintf("messages=%d", total), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				store.CreateMessageReads([]string{ids[(i*7919)%len(ids)]}, benchUser)
+			}
+		})
+	}
+}
+
+// BenchmarkPage reads a page of older messages from a position halfway through
+// peer0's chat, which a linear search for the position would have to pass
+func BenchmarkPage(b *testing.B) {
+	for _, total := range benchTotals {
+		store := NewStore()
+		perConversation := total / benchConversations
+		ids := repositorytest.SeedInbox(b, store, benchUser, benchConversations, perConversation, 0)
+
+		conversationID := database.OneToOneConversationID("peer0", benchUser)
+		query := database.PageQuery{Limit: benchPageSize, Direction: database.PageBefore, Position: ids[perConversation/2]}
+		page, err := store.GetMessages(conversationID, benchUser, query)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(page.Messages) != benchPageSize || !page.HasOlder {
+			b.Fatalf("page returned %d messages, has_older=%t", len(page.Messages), page.HasOlder)
+		}
+
+		b.Run(fmt.Sprintf("messages=%d", total), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				store.GetMessages(conversationID, benchUser, query)
+			}
+		})
+	}
+}
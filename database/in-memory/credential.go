@@ -0,0 +1,105 @@
+package in_memory
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+)
+
+// Credential operations
+func (s *MemoryStore) CreateCredential(credential *database.Credential) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.createCredential(credential)
+}
+
+// createCredential links a credential to its user. Callers hold s.mu.
+func (s *MemoryStore) createCredential(credential *database.Credential) error {
+	if _, exists := s.users[credential.UserID]; !exists {
+		return fmt.Errorf("user not found")
+	}
+	if _, exists := s.credentials[credential.UserID]; exists {
+		return fmt.Errorf("credential already exists")
+	}
+	if _, taken := s.loginNames[credential.LoginName]; taken {
+		return fmt.Errorf(database.ErrLoginNameTaken)
+	}
+
+	now := time.Now()
+	credential.CreatedAt = now
+	credential.UpdatedAt = now
+	copied := *credential
+	s.credentials[credential.UserID] = &copied
+	s.loginNames[credential.LoginName] = credential.UserID
+	return nil
+}
+
+func (s *MemoryStore) GetCredential(userID string) (*database.Credential, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	credential, exists := s.credentials[userID]
+	if !exists {
+		return nil, fmt.Errorf(database.ErrCredentialNotFound)
+	}
+	copied := *credential
+	return &copied, nil
+}
+
+// GetCredentialByLogin finds the credential signed in with login: a login name,
+// or otherwise the email of the user the credential belongs to (in any case)
+func (s *MemoryStore) GetCredentialByLogin(login string) (*database.Credential, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	userID, exists := s.loginNames[login]
+	if !exists {
+		userID, exists = s.userEmails[strings.ToLower(login)]
+	}
+	credential, hasCredential := s.credentials[userID]
+	if !exists || !hasCredential {
+		return nil, fmt.Errorf(database.ErrCredentialNotFound)
+	}
+	copied := *credential
+	return &copied, nil
+}
+
+func (s *MemoryStore) SetCredentialPassword(userID, passwordHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	credential, exists := s.credentials[userID]
+	if !exists {
+		return fmt.Errorf(database.ErrCredentialNotFound)
+	}
+	credential.PasswordHash = passwordHash
+	credential.UpdatedAt = time.Now()
+	return nil
+}
+
+// RenameLogin changes a user's login name. The check that the name is free and
+// the rename happen together, so two users cannot take the same name at once.
+func (s *MemoryStore) RenameLogin(userID, loginName string) (*database.Credential, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	credential, exists := s.credentials[userID]
+	if !exists {
+		return nil, fmt.Errorf(database.ErrCredentialNotFound)
+	}
+	if owner, taken := s.loginNames[loginName]; taken && owner != userID {
+		return nil, fmt.Errorf(database.ErrLoginNameTaken)
+	}
+
+	if credential.LoginName != loginName {
+		delete(s.loginNames, credential.LoginName)
+		s.loginNames[loginName] = userID
+		credential.LoginName = loginName
+		credential.UpdatedAt = time.Now()
+	}
+	copied := *credential
+	return &copied, nil
+}
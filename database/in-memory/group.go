@@ -2,8 +2,11 @@ package in_memory
 
 import (
 	"fmt"
-	"github.com/kasasunil/chat_app/database"
+	"sort"
 	"time"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/pkg/utils"
 )
 
 // Group operations
@@ -11,14 +14,41 @@ func (s *MemoryStore) CreateGroup(group *database.Group) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	return s.createGroup(group)
+}
+
+// CreateGroupWithMembers creates a group together with its first members, all or nothing.
+// Members' group IDs and join times are set by the store.
+func (s *MemoryStore) CreateGroupWithMembers(group *database.Group, members []*database.GroupMember) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.createGroup(group); err != nil {
+		return err
+	}
+	for _, member := range members {
+		member.GroupID = group.ID
+		member.JoinedAt = group.CreatedAt
+		stored := *member
+		s.groupMembers[group.ID][member.UserID] = &stored
+	}
+	return nil
+}
+
+// createGroup stores a new group with no members. Caller must hold the write lock.
+func (s *MemoryStore) createGroup(group *database.Group) error {
 	if _, exists := s.groups[group.ID]; exists {
 		return fmt.Errorf("group already exists")
 	}
 
 	group.CreatedAt = time.Now()
 	group.UpdatedAt = time.Now()
-	s.groups[group.ID] = group
-	s.groupMembers[group.ID] = make(map[string]bool)
+	if group.HistoryVisibility == "" {
+		group.HistoryVisibility = database.HistoryVisibilityFull
+	}
+	stored := *group
+	s.groups[group.ID] = &stored
+	s.groupMembers[group.ID] = make(map[string]*database.GroupMember)
 	return nil
 }
 
@@ -30,10 +60,41 @@ func (s *MemoryStore) GetGroup(groupID string) (*database.Group, error) {
 	if !exists {
 		return nil, fmt.Errorf("group not found")
 	}
-	return group, nil
+	copied := *group
+	return &copied, nil
 }
 
-func (s *MemoryStore) AddGroupMember(groupID, userID string) error {
+// UpdateGroup changes only the fields set in update and returns the group as saved,
+// so concurrent updates to different fields do not undo each other
+func (s *MemoryStore) UpdateGroup(groupID string, update database.GroupUpdate) (*database.Group, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, exists := s.groups[groupID]
+	if !exists {
+		return nil, fmt.Errorf("group not found")
+	}
+
+	if update.Name != nil {
+		group.Name = *update.Name
+	}
+	if update.Description != nil {
+		group.Description = *update.Description
+	}
+	if update.AnnouncementOnly != nil {
+		group.AnnouncementOnly = *update.AnnouncementOnly
+	}
+	if update.HistoryVisibility != nil {
+		group.HistoryVisibility = *update.HistoryVisibility
+	}
+	group.UpdatedAt = time.Now()
+	copied := *group
+	return &copied, nil
+}
+
+// AddGroupMember adds a user to a group with the given role.
+// Adding an existing member keeps their current role.
+func (s *MemoryStore) AddGroupMember(groupID, userID string, role database.GroupRole) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -42,9 +103,76 @@ func (s *MemoryStore) AddGroupMember(groupID, userID string) error {
 	}
 
 	if s.groupMembers[groupID] == nil {
-		s.groupMembers[groupID] = make(map[string]bool)
+		s.groupMembers[groupID] = make(map[string]*database.GroupMember)
+	}
+	if _, exists := s.groupMembers[groupID][userID]; exists {
+		return nil
+	}
+	s.groupMembers[groupID][userID] = &database.GroupMember{
+		GroupID:  groupID,
+		UserID:   userID,
+		Role:     role,
+		JoinedAt: time.Now(),
+	}
+	return nil
+}
+
+// AddGroupMembers adds the users in userIDs who are not yet members to a group with
+// the given role and returns them, in request order. The batch is added under one
+// lock, all or nothing, so concurrent adds cannot push the group past maxMembers.
+func (s *MemoryStore) AddGroupMembers(groupID string, userIDs []string, role database.GroupRole, maxMembers int) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.groups[groupID]; !exists {
+		return nil, fmt.Errorf(database.ErrGroupNotFound)
+	}
+	members := s.groupMembers[groupID]
+	added := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if members[userID] == nil && !utils.Contains(added, userID) {
+			added = append(added, userID)
+		}
+	}
+	if len(members)+len(added) > maxMembers {
+		return nil, fmt.Errorf(database.ErrGroupFull)
+	}
+
+	if members == nil {
+		members = make(map[string]*database.GroupMember)
+		s.groupMembers[groupID] = members
+	}
+	now := time.Now()
+	for _, userID := range added {
+		members[userID] = &database.GroupMember{
+			GroupID:  groupID,
+			UserID:   userID,
+			Role:     role,
+			JoinedAt: now,
+		}
+	}
+	return added, nil
+}
+
+// RemoveGroupMember removes a user from a group. The group drops out of the
+// user's conversation list, as they can no longer read it.
+func (s *MemoryStore) RemoveGroupMember(groupID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.groups[groupID]; !exists {
+		return fmt.Errorf("group not found")
+	}
+
+	delete(s.groupMembers[groupID], userID)
+
+	conversations := s.userConversations[userID]
+	for i, uc := range conversations {
+		if uc.ConversationID == groupID {
+			s.userConversations[userID] = append(conversations[:i:i], conversations[i+1:]...)
+			break
+		}
 	}
-	s.groupMembers[groupID][userID] = true
 	return nil
 }
 
@@ -52,5 +180,68 @@ func (s *MemoryStore) IsGroupMember(groupID, userID string) bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	return s.groupMembers[groupID][userID]
+	return s.groupMembers[groupID][userID] != nil
+}
+
+func (s *MemoryStore) GetGroupMember(groupID, userID string) (*database.GroupMember, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	member := s.groupMembers[groupID][userID]
+	if member == nil {
+		return nil, fmt.Errorf(database.ErrGroupMemberNotFound)
+	}
+	copied := *member
+	return &copied, nil
+}
+
+func (s *MemoryStore) SetGroupMemberRole(groupID, userID string, role database.GroupRole) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	member := s.groupMembers[groupID][userID]
+	if member == nil {
+		return fmt.Errorf(database.ErrGroupMemberNotFound)
+	}
+	member.Role = role
+	return nil
+}
+
+// TransferGroupOwnership makes newOwnerID the owner of a group and ownerID an admin,
+// provided ownerID still owns it
+func (s *MemoryStore) TransferGroupOwnership(groupID, ownerID, newOwnerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	owner := s.groupMembers[groupID][ownerID]
+	newOwner := s.groupMembers[groupID][newOwnerID]
+	if owner == nil || newOwner == nil {
+		return fmt.Errorf(database.ErrGroupMemberNotFound)
+	}
+	if owner.Role != database.GroupRoleOwner {
+		return fmt.Errorf(database.ErrNotGroupOwner)
+	}
+	owner.Role = database.GroupRoleAdmin
+	newOwner.Role = database.GroupRoleOwner
+	return nil
+}
+
+// ListGroupMembers returns a group's members ordered by user ID
+func (s *MemoryStore) ListGroupMembers(groupID string) ([]*database.GroupMember, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, exists := s.groups[groupID]; !exists {
+		return nil, fmt.Errorf("group not found")
+	}
+
+	members := make([]*database.GroupMember, 0, len(s.groupMembers[groupID]))
+	for _, member := range s.groupMembers[groupID] {
+		copied := *member
+		members = append(members, &copied)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].UserID < members[j].UserID
+	})
+	return members, nil
 }
@@ -0,0 +1,127 @@
+package in_memory
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+)
+
+// Group invite operations
+func (s *MemoryStore) CreateGroupInvite(invite *database.GroupInvite) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.groups[invite.GroupID]; !exists {
+		return fmt.Errorf("group not found")
+	}
+	if _, exists := s.groupInvites[invite.ID]; exists {
+		return fmt.Errorf("invite already exists")
+	}
+
+	invite.UseCount = 0
+	invite.RevokedAt = nil
+	invite.CreatedAt = time.Now()
+	stored := *invite
+	s.groupInvites[invite.ID] = &stored
+	return nil
+}
+
+func (s *MemoryStore) GetGroupInvite(inviteID string) (*database.GroupInvite, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	invite, exists := s.groupInvites[inviteID]
+	if !exists {
+		return nil, fmt.Errorf("invite not found")
+	}
+	copied := *invite
+	return &copied, nil
+}
+
+// ListGroupInvites returns every invite of a group, newest first, including
+// revoked, expired and used up ones
+func (s *MemoryStore) ListGroupInvites(groupID string) ([]*database.GroupInvite, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, exists := s.groups[groupID]; !exists {
+		return nil, fmt.Errorf("group not found")
+	}
+
+	invites := make([]*database.GroupInvite, 0)
+	for _, invite := range s.groupInvites {
+		if invite.GroupID == groupID {
+			copied := *invite
+			invites = append(invites, &copied)
+		}
+	}
+	sort.Slice(invites, func(i, j int) bool {
+		return invites[i].CreatedAt.After(invites[j].CreatedAt)
+	})
+	return invites, nil
+}
+
+// RevokeGroupInvite stops an invite from being used. Revoking twice keeps the first time.
+func (s *MemoryStore) RevokeGroupInvite(inviteID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invite, exists := s.groupInvites[inviteID]
+	if !exists {
+		return fmt.Errorf("invite not found")
+	}
+	if invite.RevokedAt == nil {
+		now := time.Now()
+		invite.RevokedAt = &now
+	}
+	return nil
+}
+
+// JoinGroupWithInvite adds userID to the invite's group as a member and counts one
+// use of the invite. The checks, the count and the add happen under one lock, so
+// concurrent joins cannot use an invite more often than it allows, push the group
+// past maxMembers or add the same user twice.
+func (s *MemoryStore) JoinGroupWithInvite(inviteID, userID string, maxMembers int) (*database.GroupInvite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invite, exists := s.groupInvites[inviteID]
+	if !exists {
+		return nil, fmt.Errorf(database.ErrInviteNotFound)
+	}
+	if _, exists := s.groups[invite.GroupID]; !exists {
+		return nil, fmt.Errorf(database.ErrGroupNotFound)
+	}
+	members := s.groupMembers[invite.GroupID]
+	if members[userID] != nil {
+		return nil, fmt.Errorf(database.ErrAlreadyGroupMember)
+	}
+
+	now := time.Now()
+	switch {
+	case invite.RevokedAt != nil:
+		return nil, fmt.Errorf(database.ErrInviteRevoked)
+	case invite.IsExpired(now):
+		return nil, fmt.Errorf(database.ErrInviteExpired)
+	case invite.IsExhausted():
+		return nil, fmt.Errorf(database.ErrInviteExhausted)
+	case len(members) >= maxMembers:
+		return nil, fmt.Errorf(database.ErrGroupFull)
+	}
+
+	invite.UseCount++
+	if members == nil {
+		members = make(map[string]*database.GroupMember)
+		s.groupMembers[invite.GroupID] = members
+	}
+	members[userID] = &database.GroupMember{
+		GroupID:  invite.GroupID,
+		UserID:   userID,
+		Role:     database.GroupRoleMember,
+		JoinedAt: now,
+	}
+	copied := *invite
+	return &copied, nil
+}
@@ -15,26 +15,45 @@ func (s *MemoryStore) CreateMessage(message *database.Message) error {
 	message.CreatedAt = time.Now()
 	message.UpdatedAt = time.Now()
 	message.Status = database.StatusSent
+	if message.Kind == "" {
+		message.Kind = database.MessageKindUser
+	}
+	// Both sides of a one-to-one chat share one canonical conversation key
+	message.ConversationID = database.ConversationIDForMessage(message)
 
-	destKey := message.DestinationID
-	if s.messages[destKey] == nil {
-		s.messages[destKey] = make([]*database.Message, 0)
+	// The store keeps its own copy, which later reads copy again, so callers never
+	// share a message with writers holding the lock
+	stored := *message
+	convKey := stored.ConversationID
+	if s.messages[convKey] == nil {
+		s.messages[convKey] = make([]*database.Message, 0)
+	}
+	if s.messagePositions[convKey] == nil {
+		s.messagePositions[convKey] = make(map[string]int)
 	}
-	s.messages[destKey] = append(s.messages[destKey], message)
+	s.messagePositions[convKey][stored.ID] = len(s.messages[convKey])
+	s.messages[convKey] = append(s.messages[convKey], &stored)
+	s.messageIndex[stored.ID] = &stored
 
-	// Update user conversations for sender
-	s.updateUserConversation(message.SenderID, message.DestinationID, message.ConversationType, message)
+	// Keep the thread root's reply summary current
+	if message.ThreadRootID != "" {
+		if root := s.findMessage(message.ThreadRootID); root != nil {
+			createdAt := message.CreatedAt
+			root.ReplyCount++
+			root.LastReplyAt = &createdAt
+		}
+	}
 
-	// Update user conversations for recipient(s)
+	// Update user conversations for sender and recipient
 	if message.ConversationType == database.ConversationTypeOneToOne {
-		s.updateUserConversation(message.DestinationID, message.SenderID, message.ConversationType, message)
+		s.updateUserConversation(message.SenderID, message.ConversationID, message.DestinationID, message.ConversationType, message)
+		s.updateUserConversation(message.DestinationID, message.ConversationID, message.SenderID, message.ConversationType, message)
 	} else {
-		// For group messages, update all group members
+		// For group messages, update all group members (the sender is one of them;
+		// a system message about a member leaving must not bring the group back for them)
 		if members, exists := s.groupMembers[message.DestinationID]; exists {
 			for memberID := range members {
-				if memberID != message.SenderID {
-					s.updateUserConversation(memberID, message.DestinationID, message.ConversationType, message)
-				}
+				s.updateUserConversation(memberID, message.ConversationID, message.DestinationID, message.ConversationType, message)
 			}
 		}
 	}
@@ -42,25 +61,20 @@ func (s *MemoryStore) CreateMessage(message *database.Message) error {
 	return nil
 }
 
-func (s *MemoryStore) updateUserConversation(userID, destinationID string, convType database.ConversationType, message *database.Message) {
+func (s *MemoryStore) updateUserConversation(userID, conversationID, destinationID string, convType database.ConversationType, message *database.Message) {
 	if s.userConversations[userID] == nil {
 		s.userConversations[userID] = make([]*database.UserConversation, 0)
 	}
 
 	// Check if conversation already exists
-	var found bool
-	for _, uc := range s.userConversations[userID] {
-		if uc.DestinationID == destinationID && uc.ConversationType == convType {
-			uc.UpdatedAt = time.Now()
-			found = true
-			break
-		}
-	}
-
-	if !found {
-		uc := &database.UserConversation{
+	uc := s.findUserConversation(userID, conversationID)
+	if uc != nil {
+		uc.UpdatedAt = time.Now()
+	} else {
+		uc = &database.UserConversation{
 			ID:               fmt.Sprintf("uc_%s_%s", userID, destinationID),
 			UserID:           userID,
+			ConversationID:   conversationID,
 			DestinationID:    destinationID,
 			ConversationType: convType,
 			CreatedAt:        time.Now(),
@@ -68,77 +82,360 @@ func (s *MemoryStore) updateUserConversation(userID, destinationID string, convT
 		}
 		s.userConversations[userID] = append(s.userConversations[userID], uc)
 	}
+
+	if database.CountsAsUnread(message, userID) {
+		uc.UnreadCount++
+	}
+}
+
+// findUserConversation returns userID's entry for a conversation, or nil.
+// Caller must hold the lock.
+func (s *MemoryStore) findUserConversation(userID, conversationID string) *database.UserConversation {
+	for _, uc := range s.userConversations[userID] {
+		if uc.ConversationID == conversationID {
+			return uc
+		}
+	}
+	return nil
+}
+
+// isUnread reports whether msg is counted in userID's unread count: it counts as
+// unread, reached userID as a recipient, and was not read, passed by their read
+// watermark, or hidden by them since. Members only count messages sent after they
+// joined. Caller must hold the lock.
+func (s *MemoryStore) isUnread(msg *database.Message, userID string) bool {
+	return database.CountsAsUnread(msg, userID) && s.hasReceived(msg, userID) &&
+		s.messageReads[msg.ID][userID] == nil && !s.hiddenMessages[userID][msg.ID] &&
+		s.watermarkCovering(msg, userID) == nil
+}
+
+// hasReceived reports whether userID received msg: the destination of a one-to-one
+// message, or a group member who had joined by the time it was sent.
+// Caller must hold the lock.
+func (s *MemoryStore) hasReceived(msg *database.Message, userID string) bool {
+	if msg.SenderID == userID {
+		return false
+	}
+	if msg.ConversationType != database.ConversationTypeGroup {
+		return msg.DestinationID == userID
+	}
+	member := s.groupMembers[msg.DestinationID][userID]
+	return member != nil && !member.JoinedAt.After(msg.CreatedAt)
+}
+
+// decrementUnread takes one message off userID's unread count for a conversation.
+// Caller must hold the write lock.
+func (s *MemoryStore) decrementUnread(userID, conversationID string) {
+	if uc := s.findUserConversation(userID, conversationID); uc != nil && uc.UnreadCount > 0 {
+		uc.UnreadCount--
+	}
+}
+
+// GetMessages returns a page of the conversation as seen by userID:
+// thread replies and messages the user deleted for themselves are left out.
+func (s *MemoryStore) GetMessages(conversationID, userID string, query database.PageQuery) (*database.MessagePage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	start := s.historyStart(conversationID, userID)
+	return s.pageMessages(conversationID, func(msg *database.Message) bool {
+		return msg.ThreadRootID == "" && !msg.CreatedAt.Before(start)
+	}, userID, query)
 }
 
-func (s *MemoryStore) GetMessages(destinationID string, limit int, cursor string) ([]*database.Message, string, error) {
+// GetThreadMessages returns a page of the replies in a thread, using the same
+// ordering and positions as GetMessages
+func (s *MemoryStore) GetThreadMessages(rootMessageID, userID string, query database.PageQuery) (*database.MessagePage, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	messages, exists := s.messages[destinationID]
-	if !exists {
-		return []*database.Message{}, "", nil
+	root := s.findMessage(rootMessageID)
+	if root == nil {
+		return nil, fmt.Errorf("message not found")
 	}
 
-	// Reverse to get newest first, then apply cursor
-	reversed := make([]*database.Message, len(messages))
-	for i := range messages {
-		reversed[len(messages)-1-i] = messages[i]
+	start := s.historyStart(root.ConversationID, userID)
+	return s.pageMessages(root.ConversationID, func(msg *database.Message) bool {
+		return msg.ThreadRootID == rootMessageID && !msg.CreatedAt.Before(start)
+	}, userID, query)
+}
+
+// historyStart returns when userID's view of a conversation begins: their join time
+// in groups that only show history since joining, otherwise the zero time.
+// Caller must hold the lock.
+func (s *MemoryStore) historyStart(conversationID, userID string) time.Time {
+	group, exists := s.groups[conversationID]
+	if !exists || group.HistoryVisibility != database.HistoryVisibilitySinceJoined {
+		return time.Time{}
 	}
+	if member := s.groupMembers[conversationID][userID]; member != nil {
+		return member.JoinedAt
+	}
+	return time.Time{}
+}
 
-	// Apply cursor if provided
-	startIdx := 0
-	if cursor != "" {
-		for i, msg := range reversed {
-			if msg.ID == cursor {
-				startIdx = i + 1
-				break
-			}
+// pageMessages returns one page of a conversation's messages that match include,
+// newest first. Messages are walked outwards from the newest end, or from the
+// position's index, so a page costs the same however long the conversation is.
+// Caller must hold the lock.
+func (s *MemoryStore) pageMessages(conversationID string, include func(*database.Message) bool, userID string, query database.PageQuery) (*database.MessagePage, error) {
+	messages := s.messages[conversationID]
+
+	// The position may be a message the user has since hidden or that the filter
+	// leaves out; only its place in the conversation matters
+	from, step := len(messages)-1, -1
+	if query.Position != "" {
+		i, exists := s.messagePositions[conversationID][query.Position]
+		if !exists {
+			return nil, fmt.Errorf(database.ErrInvalidCursor)
+		}
+		from = i - 1
+		if query.Direction == database.PageAfter {
+			from, step = i+1, 1
 		}
 	}
 
-	// Apply limit
-	endIdx := startIdx + limit
-	if endIdx > len(reversed) {
-		endIdx = len(reversed)
+	// Apply limit, collecting one extra message to learn whether another page exists
+	hidden := s.hiddenMessages[userID]
+	result := make([]*database.Message, 0)
+	hasMore := false
+	for i := from; i >= 0 && i < len(messages); i += step {
+		msg := messages[i]
+		if !include(msg) || hidden[msg.ID] {
+			continue
+		}
+		if len(result) == query.Limit {
+			hasMore = true
+			break
+		}
+		result = append(result, copyMessage(msg))
 	}
 
-	result := reversed[startIdx:endIdx]
-
-	// Get next cursor (first message of next page, if exists)
-	nextCursor := ""
-	if endIdx < len(reversed) {
-		nextCursor = reversed[endIdx].ID
+	page := &database.MessagePage{Messages: result}
+	if step > 0 {
+		// Read oldest first; pages are always returned newest first
+		for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+			result[i], result[j] = result[j], result[i]
+		}
+		page.HasNewer, page.HasOlder = hasMore, true
+	} else {
+		page.HasOlder, page.HasNewer = hasMore, query.Position != ""
 	}
-
-	return result, nextCursor, nil
+	return page, nil
 }
 
 func (s *MemoryStore) GetMessage(messageID string) (*database.Message, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	for _, messages := range s.messages {
-		for _, msg := range messages {
-			if msg.ID == messageID {
-				return msg, nil
-			}
-		}
+	if msg := s.findMessage(messageID); msg != nil {
+		return copyMessage(msg), nil
 	}
 	return nil, fmt.Errorf("message not found")
 }
 
+// GetMessagesByID looks up several messages at once; unknown IDs are left out of the result
+func (s *MemoryStore) GetMessagesByID(messageIDs []string) (map[string]*database.Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make(map[string]*database.Message, len(messageIDs))
+	for _, messageID := range messageIDs {
+		if msg := s.findMessage(messageID); msg != nil {
+			result[messageID] = copyMessage(msg)
+		}
+	}
+	return result, nil
+}
+
 func (s *MemoryStore) UpdateMessageStatus(messageID string, status database.MessageStatus) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for _, messages := range s.messages {
-		for _, msg := range messages {
-			if msg.ID == messageID {
-				msg.Status = status
-				msg.UpdatedAt = time.Now()
-				return nil
-			}
+	msg := s.findMessage(messageID)
+	if msg == nil {
+		return fmt.Errorf("message not found")
+	}
+	msg.Status = status
+	msg.UpdatedAt = time.Now()
+	return nil
+}
+
+// UpdateMessage replaces a message's text, keeping the previous text as a revision.
+// Search reads the current text, so old text stops matching immediately.
+// Deleted and system messages are left alone.
+func (s *MemoryStore) UpdateMessage(messageID, messageText string) (*database.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := s.findMessage(messageID)
+	if msg == nil {
+		return nil, fmt.Errorf("message not found")
+	}
+	if msg.Kind == database.MessageKindSystem {
+		return nil, fmt.Errorf(database.ErrSystemMessage)
+	}
+	if msg.DeletedAt != nil {
+		return nil, fmt.Errorf(database.ErrMessageDeleted)
+	}
+	if msg.MessageText == messageText {
+		return copyMessage(msg), nil
+	}
+
+	now := time.Now()
+	writtenAt := msg.CreatedAt
+	if msg.EditedAt != nil {
+		writtenAt = *msg.EditedAt
+	}
+	s.messageRevisions[messageID] = append(s.messageRevisions[messageID], &database.MessageRevision{
+		ID:          fmt.Sprintf("rev_%s_%d", messageID, len(s.messageRevisions[messageID])+1),
+		MessageID:   messageID,
+		MessageText: msg.MessageText,
+		CreatedAt:   writtenAt,
+		ReplacedAt:  now,
+	})
+
+	msg.MessageText = messageText
+	msg.EditedAt = &now
+	msg.UpdatedAt = now
+	return copyMessage(msg), nil
+}
+
+func (s *MemoryStore) GetMessageRevisions(messageID string) ([]*database.MessageRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.findMessage(messageID) == nil {
+		return nil, fmt.Errorf("message not found")
+	}
+
+	revisions := make([]*database.MessageRevision, len(s.messageRevisions[messageID]))
+	copy(revisions, s.messageRevisions[messageID])
+	return revisions, nil
+}
+
+// DeleteMessageForEveryone turns the message into a tombstone: the text, its
+// revisions and its reactions are discarded and DeletedAt is set. Deleting twice is a no-op.
+func (s *MemoryStore) DeleteMessageForEveryone(messageID string) (*database.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := s.findMessage(messageID)
+	if msg == nil {
+		return nil, fmt.Errorf("message not found")
+	}
+	if msg.DeletedAt != nil {
+		return copyMessage(msg), nil
+	}
+
+	// A tombstone no longer counts as unread for recipients who had not read it
+	recipients := []string{msg.DestinationID}
+	if msg.ConversationType == database.ConversationTypeGroup {
+		recipients = make([]string, 0, len(s.groupMembers[msg.DestinationID]))
+		for memberID := range s.groupMembers[msg.DestinationID] {
+			recipients = append(recipients, memberID)
+		}
+	}
+	for _, userID := range recipients {
+		if s.isUnread(msg, userID) {
+			s.decrementUnread(userID, msg.ConversationID)
+		}
+	}
+
+	now := time.Now()
+	msg.MessageText = ""
+	msg.DeletedAt = &now
+	msg.UpdatedAt = now
+	delete(s.messageRevisions, messageID)
+	delete(s.reactions, messageID)
+	if msg.ThreadRootID != "" {
+		s.removeThreadReply(msg)
+	}
+	return copyMessage(msg), nil
+}
+
+// removeThreadReply takes a reply deleted for everyone off its thread root's reply
+// summary: the count drops and the last reply time moves back to the latest reply
+// still standing. Caller must hold the write lock.
+func (s *MemoryStore) removeThreadReply(reply *database.Message) {
+	root := s.findMessage(reply.ThreadRootID)
+	if root == nil {
+		return
+	}
+	if root.ReplyCount > 0 {
+		root.ReplyCount--
+	}
+	root.LastReplyAt = nil
+	// Replies are stored after their root, so the walk stops there
+	messages := s.messages[root.ConversationID]
+	for i := len(messages) - 1; i > s.messagePositions[root.ConversationID][root.ID]; i-- {
+		if msg := messages[i]; msg.ThreadRootID == root.ID && msg.DeletedAt == nil {
+			createdAt := msg.CreatedAt
+			root.LastReplyAt = &createdAt
+			return
+		}
+	}
+}
+
+// DeleteMessageForUser hides the message from userID's history, unread count and search
+func (s *MemoryStore) DeleteMessageForUser(messageID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := s.findMessage(messageID)
+	if msg == nil {
+		return fmt.Errorf("message not found")
+	}
+	if s.isUnread(msg, userID) {
+		s.decrementUnread(userID, msg.ConversationID)
+	}
+	if s.hiddenMessages[userID] == nil {
+		s.hiddenMessages[userID] = make(map[string]bool)
+	}
+	s.hiddenMessages[userID][messageID] = true
+	return nil
+}
+
+// findMessage looks up a message by ID. Caller must hold the lock.
+func (s *MemoryStore) findMessage(messageID string) *database.Message {
+	return s.messageIndex[messageID]
+}
+
+// copyMessage returns a copy of a stored message for callers outside the lock.
+// Caller must hold the lock.
+func copyMessage(msg *database.Message) *database.Message {
+	copied := *msg
+	return &copied
+}
+
+// refreshMessageStatus recomputes the sender-visible status from per-recipient
+// delivery and read records and read watermarks. Caller must hold the write lock.
+func (s *MemoryStore) refreshMessageStatus(msg *database.Message) {
+	recipients := s.messageRecipients(msg)
+
+	delivered := make(map[string]bool)
+	for userID := range s.messageDeliveries[msg.ID] {
+		delivered[userID] = true
+	}
+	read := make(map[string]bool)
+	for _, userID := range recipients {
+		read[userID] = s.messageReads[msg.ID][userID] != nil || s.watermarkCovering(msg, userID) != nil
+	}
+
+	if status := database.AggregateMessageStatus(msg.Status, recipients, delivered, read); status != msg.Status {
+		msg.Status = status
+		msg.UpdatedAt = time.Now()
+	}
+}
+
+// messageRecipients returns the users msg is addressed to: for a group, the members
+// who had joined by the time it was sent. Caller must hold the lock.
+func (s *MemoryStore) messageRecipients(msg *database.Message) []string {
+	members := make([]*database.GroupMember, 0)
+	if msg.ConversationType == database.ConversationTypeGroup {
+		for _, member := range s.groupMembers[msg.DestinationID] {
+			members = append(members, member)
 		}
 	}
-	return fmt.Errorf("message not found")
+	return database.MessageRecipients(msg, database.GroupMemberIDsJoinedBy(members, msg.CreatedAt))
 }
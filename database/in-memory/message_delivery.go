@@ -0,0 +1,80 @@
+package in_memory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+)
+
+// MessageDelivery operations
+func (s *MemoryStore) CreateMessageDelivery(messageID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := s.findMessage(messageID)
+	if msg == nil {
+		return fmt.Errorf("message not found")
+	}
+	s.deliverMessage(msg, userID)
+	return nil
+}
+
+// CreateMessageDeliveries marks several messages delivered to userID under a single lock.
+// Unknown message IDs are skipped.
+func (s *MemoryStore) CreateMessageDeliveries(messageIDs []string, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, messageID := range messageIDs {
+		if msg := s.findMessage(messageID); msg != nil {
+			s.deliverMessage(msg, userID)
+		}
+	}
+	return nil
+}
+
+// deliverMessage records that userID received msg. Caller must hold the write lock.
+func (s *MemoryStore) deliverMessage(msg *database.Message, userID string) {
+	if s.recordDelivery(msg.ID, userID) {
+		// Update message status (DELIVERED once every recipient has it)
+		s.refreshMessageStatus(msg)
+	}
+}
+
+// recordDelivery stores a delivery receipt, returning false if it already existed.
+// Caller must hold the write lock.
+func (s *MemoryStore) recordDelivery(messageID, userID string) bool {
+	if s.messageDeliveries[messageID] == nil {
+		s.messageDeliveries[messageID] = make(map[string]*database.MessageDelivery)
+	}
+
+	if _, exists := s.messageDeliveries[messageID][userID]; exists {
+		return false // Already delivered
+	}
+
+	s.messageDeliveries[messageID][userID] = &database.MessageDelivery{
+		ID:        fmt.Sprintf("md_%s_%s", messageID, userID),
+		MessageID: messageID,
+		UserID:    userID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	return true
+}
+
+func (s *MemoryStore) GetMessageDeliveries(messageID string) []*database.MessageDelivery {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deliveries, exists := s.messageDeliveries[messageID]
+	if !exists {
+		return []*database.MessageDelivery{}
+	}
+
+	result := make([]*database.MessageDelivery, 0, len(deliveries))
+	for _, md := range deliveries {
+		result = append(result, md)
+	}
+	return result
+}
@@ -13,60 +13,163 @@ func (s *MemoryStore) CreateMessageRead(messageID, userID string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	s.readMessage(messageID, userID)
+	return nil
+}
+
+// CreateMessageReads marks several messages read by userID under a single lock
+func (s *MemoryStore) CreateMessageReads(messageIDs []string, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, messageID := range messageIDs {
+		s.readMessage(messageID, userID)
+	}
+	return nil
+}
+
+// readMessage records that userID read a message. Caller must hold the write lock.
+func (s *MemoryStore) readMessage(messageID, userID string) {
+	if _, exists := s.messageReads[messageID][userID]; exists {
+		return // Already read
+	}
+
+	// Checked before the read is recorded, which takes the message off the count
+	msg := s.findMessage(messageID)
+	if msg != nil && s.isUnread(msg, userID) {
+		s.decrementUnread(userID, msg.ConversationID)
+	}
+	s.recordRead(messageID, userID)
+
+	// Reading a message implies it was delivered
+	s.recordDelivery(messageID, userID)
+
+	// Update message status (READ once every recipient has read it)
+	if msg != nil {
+		s.refreshMessageStatus(msg)
+	}
+}
+
+// recordRead stores a read receipt, returning false if it already existed.
+// Caller must hold the write lock.
+func (s *MemoryStore) recordRead(messageID, userID string) bool {
 	if s.messageReads[messageID] == nil {
 		s.messageReads[messageID] = make(map[string]*database.MessageRead)
 	}
 
 	if _, exists := s.messageReads[messageID][userID]; exists {
-		return nil // Already read
+		return false // Already read
 	}
 
-	mr := &database.MessageRead{
+	s.messageReads[messageID][userID] = &database.MessageRead{
 		ID:        fmt.Sprintf("mr_%s_%s", messageID, userID),
 		MessageID: messageID,
 		UserID:    userID,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
-	s.messageReads[messageID][userID] = mr
-
-	// Update message status to READ
-	// Directly access messages map since we already have the write lock
-	// (Calling GetMessage would cause deadlock as it tries to acquire read lock)
-	found := false
-	for _, messages := range s.messages {
-		for _, msg := range messages {
-			if msg.ID == messageID {
-				msg.Status = database.StatusRead
-				msg.UpdatedAt = time.Now()
-				found = true
-				break
-			}
-		}
-		if found {
-			break
-		}
-	}
-
-	return nil
+	return true
 }
 
+// GetMessageReads returns the read receipts of a message, including the reads implied
+// by recipients' read watermarks
 func (s *MemoryStore) GetMessageReads(messageID string) []*database.MessageRead {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	reads, exists := s.messageReads[messageID]
-	if !exists {
-		return []*database.MessageRead{}
-	}
-
+	reads := s.messageReads[messageID]
 	result := make([]*database.MessageRead, 0, len(reads))
 	for _, mr := range reads {
 		result = append(result, mr)
 	}
+
+	msg := s.findMessage(messageID)
+	if msg == nil {
+		return result
+	}
+	for _, userID := range s.messageRecipients(msg) {
+		if reads[userID] != nil {
+			continue
+		}
+		if uc := s.watermarkCovering(msg, userID); uc != nil {
+			result = append(result, &database.MessageRead{
+				ID:        fmt.Sprintf("mr_%s_%s", messageID, userID),
+				MessageID: messageID,
+				UserID:    userID,
+				CreatedAt: *uc.ReadMarkedAt,
+				UpdatedAt: *uc.ReadMarkedAt,
+			})
+		}
+	}
 	return result
 }
 
+// watermarkCovering returns userID's entry for msg's conversation if its read watermark
+// covers msg, which then counts as read without a receipt of its own; otherwise nil.
+// Caller must hold the lock.
+func (s *MemoryStore) watermarkCovering(msg *database.Message, userID string) *database.UserConversation {
+	if !s.hasReceived(msg, userID) {
+		return nil
+	}
+	uc := s.findUserConversation(userID, msg.ConversationID)
+	if uc == nil || uc.ReadUpTo == nil || msg.CreatedAt.After(*uc.ReadUpTo) {
+		return nil
+	}
+	return uc
+}
+
+// MarkConversationRead moves userID's read watermark in a conversation forward to upTo.
+// Messages up to the watermark count as read without receipts of their own, so only the
+// unread messages sent after it are left in the unread count. It returns the updated
+// entry, nil if the user has none, and how many messages left the unread count.
+// A watermark never moves back.
+func (s *MemoryStore) MarkConversationRead(conversationID, userID string, upTo time.Time) (*database.UserConversation, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uc := s.findUserConversation(userID, conversationID)
+	if uc == nil {
+		return nil, 0, nil
+	}
+	var from time.Time
+	if uc.ReadUpTo != nil {
+		if !upTo.After(*uc.ReadUpTo) {
+			copied := *uc
+			return &copied, 0, nil
+		}
+		from = *uc.ReadUpTo
+	}
+
+	now := time.Now()
+	readUpTo := upTo
+	uc.ReadUpTo = &readUpTo
+	uc.ReadMarkedAt = &now
+
+	// Messages are kept in send order. Only those after the new watermark can still be
+	// unread; none are left when the whole conversation was marked read.
+	messages := s.messages[conversationID]
+	i := len(messages) - 1
+	unread := 0
+	for ; i >= 0 && messages[i].CreatedAt.After(upTo); i-- {
+		if s.isUnread(messages[i], userID) {
+			unread++
+		}
+	}
+	marked := max(uc.UnreadCount-unread, 0)
+	uc.UnreadCount = unread
+
+	// Senders see READ once every recipient has read, so the statuses of the messages
+	// the watermark moved over are refreshed
+	for ; i >= 0 && messages[i].CreatedAt.After(from); i-- {
+		if messages[i].SenderID != userID && messages[i].Status != database.StatusRead {
+			s.refreshMessageStatus(messages[i])
+		}
+	}
+
+	copied := *uc
+	return &copied, marked, nil
+}
+
 // UserConversation operations
 func (s *MemoryStore) GetUserConversations(userID string) ([]*database.UserConversation, error) {
 	s.mu.RLock()
@@ -77,9 +180,12 @@ func (s *MemoryStore) GetUserConversations(userID string) ([]*database.UserConve
 		return []*database.UserConversation{}, nil
 	}
 
-	// Sort by updated_at desc
+	// Sort by updated_at desc. Entries are copied, as their unread counts keep changing.
 	result := make([]*database.UserConversation, len(conversations))
-	copy(result, conversations)
+	for i, uc := range conversations {
+		copied := *uc
+		result[i] = &copied
+	}
 
 	// Simple sort by updated_at (newest first)
 	for i := 0; i < len(result)-1; i++ {
@@ -101,7 +207,8 @@ func (s *MemoryStore) SearchMessages(userID, query string) ([]*database.Message,
 	results := make([]*database.Message, 0)
 
 	// Search in all messages where user is sender or recipient
-	for _, messages := range s.messages {
+	for conversationID, messages := range s.messages {
+		start := s.historyStart(conversationID, userID)
 		for _, msg := range messages {
 			// Check if user is part of this conversation
 			isParticipant := false
@@ -110,15 +217,18 @@ func (s *MemoryStore) SearchMessages(userID, query string) ([]*database.Message,
 			} else if msg.ConversationType == database.ConversationTypeOneToOne && msg.DestinationID == userID {
 				isParticipant = true
 			} else if msg.ConversationType == database.ConversationTypeGroup {
-				if s.groupMembers[msg.DestinationID][userID] {
+				if s.groupMembers[msg.DestinationID][userID] != nil {
 					isParticipant = true
 				}
 			}
 
-			if isParticipant {
+			// Skip system messages, messages from before the user's visible history,
+			// and messages deleted for everyone or hidden by this user
+			if isParticipant && msg.Kind != database.MessageKindSystem && !msg.CreatedAt.Before(start) &&
+				msg.DeletedAt == nil && !s.hiddenMessages[userID][msg.ID] {
 				// Simple keyword search (case-insensitive)
 				if utils.ContainsString(msg.MessageText, query) {
-					results = append(results, msg)
+					results = append(results, copyMessage(msg))
 				}
 			}
 		}
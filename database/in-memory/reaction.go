@@ -0,0 +1,71 @@
+package in_memory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+)
+
+// Reaction operations
+// Adding an existing reaction or removing a missing one is a no-op. Messages deleted
+// for everyone take no new reactions; the check holds the same lock as the delete.
+func (s *MemoryStore) AddReaction(messageID, userID, emoji string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := s.findMessage(messageID)
+	if msg == nil {
+		return fmt.Errorf("message not found")
+	}
+	if msg.DeletedAt != nil {
+		return fmt.Errorf(database.ErrMessageDeleted)
+	}
+
+	for _, reaction := range s.reactions[messageID] {
+		if reaction.UserID == userID && reaction.Emoji == emoji {
+			return nil // Already reacted
+		}
+	}
+
+	s.reactions[messageID] = append(s.reactions[messageID], &database.Reaction{
+		ID:        fmt.Sprintf("re_%s_%s_%s", messageID, userID, emoji),
+		MessageID: messageID,
+		UserID:    userID,
+		Emoji:     emoji,
+		CreatedAt: time.Now(),
+	})
+	return nil
+}
+
+func (s *MemoryStore) RemoveReaction(messageID, userID, emoji string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.findMessage(messageID) == nil {
+		return fmt.Errorf("message not found")
+	}
+
+	reactions := s.reactions[messageID]
+	for i, reaction := range reactions {
+		if reaction.UserID == userID && reaction.Emoji == emoji {
+			s.reactions[messageID] = append(reactions[:i:i], reactions[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// GetMessageReactions returns the reactions of several messages at once, keyed by message ID
+func (s *MemoryStore) GetMessageReactions(messageIDs []string) (map[string][]*database.Reaction, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make(map[string][]*database.Reaction, len(messageIDs))
+	for _, messageID := range messageIDs {
+		if reactions := s.reactions[messageID]; len(reactions) > 0 {
+			result[messageID] = append([]*database.Reaction(nil), reactions...)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,77 @@
+package in_memory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+)
+
+// Session operations
+func (s *MemoryStore) CreateSession(session *database.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.sessions[session.ID]; exists {
+		return fmt.Errorf("session already exists")
+	}
+
+	now := time.Now()
+	session.RevokedAt = nil
+	session.CreatedAt = now
+	session.UpdatedAt = now
+	copied := *session
+	s.sessions[session.ID] = &copied
+	return nil
+}
+
+func (s *MemoryStore) GetSession(sessionID string) (*database.Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, exists := s.sessions[sessionID]
+	if !exists {
+		return nil, fmt.Errorf("session not found")
+	}
+	copied := *session
+	return &copied, nil
+}
+
+// RotateSession replaces the session's refresh token hash and extends its expiry.
+// The check and the swap happen together, so a refresh token can only be used once
+// even by concurrent requests.
+func (s *MemoryStore) RotateSession(sessionID, refreshHash, newRefreshHash string, expiresAt time.Time) (*database.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, exists := s.sessions[sessionID]
+	if !exists {
+		return nil, fmt.Errorf("session not found")
+	}
+	if !session.IsActive(time.Now()) || session.RefreshHash != refreshHash {
+		return nil, fmt.Errorf("session cannot be refreshed")
+	}
+
+	session.RefreshHash = newRefreshHash
+	session.ExpiresAt = expiresAt
+	session.UpdatedAt = time.Now()
+	copied := *session
+	return &copied, nil
+}
+
+// RevokeSession signs a session out. Revoking twice keeps the first time.
+func (s *MemoryStore) RevokeSession(sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, exists := s.sessions[sessionID]
+	if !exists {
+		return fmt.Errorf("session not found")
+	}
+	if session.RevokedAt == nil {
+		now := time.Now()
+		session.RevokedAt = &now
+		session.UpdatedAt = now
+	}
+	return nil
+}
@@ -11,10 +11,21 @@ type MemoryStore struct {
 	mu                sync.RWMutex
 	users             map[string]*database.User
 	groups            map[string]*database.Group
-	groupMembers      map[string]map[string]bool                  // groupID -> userID -> bool
-	messages          map[string][]*database.Message              // destinationID -> messages
-	userConversations map[string][]*database.UserConversation     // userID -> conversations
-	messageReads      map[string]map[string]*database.MessageRead // messageID -> userID -> MessageRead
+	groupMembers      map[string]map[string]*database.GroupMember     // groupID -> userID -> membership
+	groupInvites      map[string]*database.GroupInvite                // inviteID -> invite
+	sessions          map[string]*database.Session                    // sessionID -> session
+	userEmails        map[string]string                               // lowercased email -> userID
+	credentials       map[string]*database.Credential                 // userID -> credential
+	loginNames        map[string]string                               // login name -> userID
+	messages          map[string][]*database.Message                  // conversationID -> messages, oldest first
+	messageIndex      map[string]*database.Message                    // messageID -> message
+	messagePositions  map[string]map[string]int                       // conversationID -> messageID -> index in messages
+	userConversations map[string][]*database.UserConversation         // userID -> conversations
+	messageReads      map[string]map[string]*database.MessageRead     // messageID -> userID -> MessageRead
+	messageDeliveries map[string]map[string]*database.MessageDelivery // messageID -> userID -> MessageDelivery
+	messageRevisions  map[string][]*database.MessageRevision          // messageID -> revisions, oldest first
+	hiddenMessages    map[string]map[string]bool                      // userID -> messageID -> deleted for that user
+	reactions         map[string][]*database.Reaction                 // messageID -> reactions, oldest first
 }
 
 // NewStore creates a new in-memory store
@@ -23,9 +34,20 @@ func NewStore() *MemoryStore {
 	return &MemoryStore{
 		users:             make(map[string]*database.User),
 		groups:            make(map[string]*database.Group),
-		groupMembers:      make(map[string]map[string]bool),
+		groupMembers:      make(map[string]map[string]*database.GroupMember),
+		groupInvites:      make(map[string]*database.GroupInvite),
+		sessions:          make(map[string]*database.Session),
+		userEmails:        make(map[string]string),
+		credentials:       make(map[string]*database.Credential),
+		loginNames:        make(map[string]string),
 		messages:          make(map[string][]*database.Message),
+		messageIndex:      make(map[string]*database.Message),
+		messagePositions:  make(map[string]map[string]int),
 		userConversations: make(map[string][]*database.UserConversation),
 		messageReads:      make(map[string]map[string]*database.MessageRead),
+		messageDeliveries: make(map[string]map[string]*database.MessageDelivery),
+		messageRevisions:  make(map[string][]*database.MessageRevision),
+		hiddenMessages:    make(map[string]map[string]bool),
+		reactions:         make(map[string][]*database.Reaction),
 	}
 }
@@ -0,0 +1,14 @@
+package in_memory
+
+import (
+	"testing"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/database/repositorytest"
+)
+
+func TestRepository(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) database.Repository {
+		return NewStore()
+	})
+}
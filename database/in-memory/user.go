@@ -3,6 +3,7 @@ package in_memory
 import (
 	"fmt"
 	"github.com/kasasunil/chat_app/database"
+	"strings"
 	"time"
 )
 
@@ -11,13 +12,29 @@ func (s *MemoryStore) CreateUser(user *database.User) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	return s.createUser(user)
+}
+
+// createUser adds a user whose ID and email are both unused. Callers hold s.mu.
+func (s *MemoryStore) createUser(user *database.User) error {
+	if !database.IsValidUserID(user.ID) {
+		return fmt.Errorf(database.ErrInvalidUserID)
+	}
 	if _, exists := s.users[user.ID]; exists {
-		return fmt.Errorf("user already exists")
+		return fmt.Errorf(database.ErrUserAlreadyExists)
+	}
+	email := strings.ToLower(user.Email)
+	if _, taken := s.userEmails[email]; taken && email != "" {
+		return fmt.Errorf(database.ErrEmailTaken)
 	}
 
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
-	s.users[user.ID] = user
+	stored := *user
+	s.users[user.ID] = &stored
+	if email != "" {
+		s.userEmails[email] = user.ID
+	}
 	return nil
 }
 
@@ -29,5 +46,52 @@ func (s *MemoryStore) GetUser(userID string) (*database.User, error) {
 	if !exists {
 		return nil, fmt.Errorf("user not found")
 	}
-	return user, nil
+	copied := *user
+	return &copied, nil
+}
+
+// UpdateUser saves the user's profile: name, email, status text and avatar.
+// The email must not belong to another user.
+func (s *MemoryStore) UpdateUser(user *database.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.users[user.ID]
+	if !exists {
+		return fmt.Errorf("user not found")
+	}
+	email, previousEmail := strings.ToLower(user.Email), strings.ToLower(existing.Email)
+	if owner, taken := s.userEmails[email]; taken && email != "" && owner != user.ID {
+		return fmt.Errorf(database.ErrEmailTaken)
+	}
+
+	if email != previousEmail {
+		delete(s.userEmails, previousEmail)
+		if email != "" {
+			s.userEmails[email] = user.ID
+		}
+	}
+	existing.Name = user.Name
+	existing.Email = user.Email
+	existing.StatusText = user.StatusText
+	existing.AvatarRef = user.AvatarRef
+	existing.UpdatedAt = time.Now()
+	*user = *existing
+	return nil
+}
+
+// CreateUserWithCredential creates a user and the credential they sign in with,
+// both or neither
+func (s *MemoryStore) CreateUserWithCredential(user *database.User, credential *database.Credential) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, taken := s.loginNames[credential.LoginName]; taken {
+		return fmt.Errorf(database.ErrLoginNameTaken)
+	}
+	if err := s.createUser(user); err != nil {
+		return err
+	}
+	credential.UserID = user.ID
+	return s.createCredential(credential)
 }
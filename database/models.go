@@ -4,11 +4,23 @@ import "time"
 
 // User represents a user in the system
 type User struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID         string    `json:"id"`
+	Name       string    `json:"name"` // Display name
+	Email      string    `json:"email"`
+	StatusText string    `json:"status_text"`
+	AvatarRef  string    `json:"avatar_ref"` // URL or media ID of the profile picture
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Credential is how a user signs in. It is kept apart from the User, so the login
+// name can change while messages, groups and sessions stay keyed by the user ID.
+type Credential struct {
+	UserID       string    `json:"user_id"`
+	LoginName    string    `json:"login_name"`
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
 
 // Group represents a group in the system
@@ -19,6 +31,92 @@ type Group struct {
 	CreatedBy   string    `json:"created_by"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Settings, changed by admins
+	AnnouncementOnly  bool              `json:"announcement_only"`  // Only admins can send messages
+	HistoryVisibility HistoryVisibility `json:"history_visibility"` // Which earlier messages new members can read
+}
+
+// GroupUpdate lists the group fields to change; nil fields are left as they are
+type GroupUpdate struct {
+	Name              *string
+	Description       *string
+	AnnouncementOnly  *bool
+	HistoryVisibility *HistoryVisibility
+}
+
+// HistoryVisibility controls which messages a member can read
+type HistoryVisibility string
+
+const (
+	HistoryVisibilityFull        HistoryVisibility = "full"         // The whole history, including messages from before they joined
+	HistoryVisibilitySinceJoined HistoryVisibility = "since_joined" // Only messages sent since they (last) joined
+)
+
+// IsValid reports whether v is a known visibility
+func (v HistoryVisibility) IsValid() bool {
+	return v == HistoryVisibilityFull || v == HistoryVisibilitySinceJoined
+}
+
+// GroupRole represents a member's permissions in a group
+type GroupRole string
+
+const (
+	GroupRoleOwner  GroupRole = "owner"  // Admin who cannot be removed; one per group
+	GroupRoleAdmin  GroupRole = "admin"  // Manages members, settings and roles
+	GroupRoleMember GroupRole = "member" // Reads and sends messages
+)
+
+// IsAdmin reports whether the role can manage the group (owners are admins too)
+func (r GroupRole) IsAdmin() bool {
+	return r == GroupRoleOwner || r == GroupRoleAdmin
+}
+
+// GroupMember represents a user's membership in a group
+type GroupMember struct {
+	GroupID  string    `json:"group_id"`
+	UserID   string    `json:"user_id"`
+	Role     GroupRole `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// GroupInvite represents a shareable invite link that lets users join a group
+type GroupInvite struct {
+	ID        string     `json:"id"`
+	GroupID   string     `json:"group_id"`
+	CreatedBy string     `json:"created_by"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // Unset for invites that never expire
+	MaxUses   int        `json:"max_uses,omitempty"`   // 0 for unlimited uses
+	UseCount  int        `json:"use_count"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// IsExpired reports whether the invite's expiry time has passed at now
+func (i *GroupInvite) IsExpired(now time.Time) bool {
+	return i.ExpiresAt != nil && !now.Before(*i.ExpiresAt)
+}
+
+// IsExhausted reports whether the invite has been used as often as it allows
+func (i *GroupInvite) IsExhausted() bool {
+	return i.MaxUses > 0 && i.UseCount >= i.MaxUses
+}
+
+// Session is one signed-in device. Access tokens name their session, so revoking
+// it signs that device out; the refresh token is only kept as a hash.
+type Session struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	RefreshHash string     `json:"-"`
+	ExpiresAt   time.Time  `json:"expires_at"` // When the current refresh token stops working
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"` // Last refresh
+}
+
+// IsActive reports whether the session can still be used at now
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
 }
 
 // ConversationType represents the type of conversation
@@ -38,27 +136,82 @@ const (
 	StatusRead     MessageStatus = "READ"     // Blue double tick (✓✓)
 )
 
+// MessageKind distinguishes messages written by users from messages the server posts
+type MessageKind string
+
+const (
+	MessageKindUser   MessageKind = "user"   // Written by the sender
+	MessageKindSystem MessageKind = "system" // Posted by the server to record a group event
+)
+
+// SystemMessageType identifies the group event a system message records
+type SystemMessageType string
+
+const (
+	SystemMemberAdded     SystemMessageType = "member_added"     // Admin added user_ids
+	SystemMemberJoined    SystemMessageType = "member_joined"    // Actor joined through an invite link
+	SystemMemberLeft      SystemMessageType = "member_left"      // Actor left the group
+	SystemMemberRemoved   SystemMessageType = "member_removed"   // Admin removed user_ids
+	SystemGroupRenamed    SystemMessageType = "group_renamed"    // Admin changed the name from previous_name to name
+	SystemSettingsChanged SystemMessageType = "settings_changed" // Admin changed the settings carried in the payload
+)
+
+// SystemPayload carries the details of a group event
+// Only the fields relevant to the event's SystemMessageType are set
+type SystemPayload struct {
+	ActorID           string            `json:"actor_id"`
+	UserIDs           []string          `json:"user_ids,omitempty"`
+	Name              string            `json:"name,omitempty"`
+	PreviousName      string            `json:"previous_name,omitempty"`
+	AnnouncementOnly  *bool             `json:"announcement_only,omitempty"`
+	HistoryVisibility HistoryVisibility `json:"history_visibility,omitempty"`
+}
+
 // UserConversation represents a user's view of a conversation
 // Used to efficiently build the chat list screen
 type UserConversation struct {
 	ID               string           `json:"id"`
 	UserID           string           `json:"user_id"`
+	ConversationID   string           `json:"conversation_id"`
 	DestinationID    string           `json:"destination_id"`
 	ConversationType ConversationType `json:"conversation_type"`
+	UnreadCount      int              `json:"unread_count"`             // Maintained by the store as messages arrive and are read
+	ReadUpTo         *time.Time       `json:"read_up_to,omitempty"`     // Read watermark: everything up to here counts as read
+	ReadMarkedAt     *time.Time       `json:"read_marked_at,omitempty"` // When the watermark last moved
 	CreatedAt        time.Time        `json:"created_at"`
 	UpdatedAt        time.Time        `json:"updated_at"`
 }
 
 // Message represents a message in the system
 type Message struct {
-	ID               string           `json:"id"`
-	SenderID         string           `json:"sender_id"`
-	DestinationID    string           `json:"destination_id"`
-	MessageText      string           `json:"message_text"`
-	Status           MessageStatus    `json:"status"`
-	ConversationType ConversationType `json:"conversation_type"`
-	CreatedAt        time.Time        `json:"created_at"`
-	UpdatedAt        time.Time        `json:"updated_at"`
+	ID               string            `json:"id"`
+	ConversationID   string            `json:"conversation_id"`
+	SenderID         string            `json:"sender_id"`
+	DestinationID    string            `json:"destination_id"`
+	MessageText      string            `json:"message_text"`
+	Kind             MessageKind       `json:"kind"`
+	SystemType       SystemMessageType `json:"system_type,omitempty"`    // Set on system messages
+	SystemPayload    *SystemPayload    `json:"system_payload,omitempty"` // Set on system messages
+	Status           MessageStatus     `json:"status"`
+	ConversationType ConversationType  `json:"conversation_type"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+	EditedAt         *time.Time        `json:"edited_at,omitempty"`
+	DeletedAt        *time.Time        `json:"deleted_at,omitempty"`          // Set when deleted for everyone; the text is cleared
+	ReplyToMessageID string            `json:"reply_to_message_id,omitempty"` // Message quoted inline by this one
+	ThreadRootID     string            `json:"thread_root_id,omitempty"`      // Set on thread replies; they are left out of the main conversation
+	ReplyCount       int               `json:"reply_count,omitempty"`         // Thread replies, set on thread roots
+	LastReplyAt      *time.Time        `json:"last_reply_at,omitempty"`       // Latest thread reply, set on thread roots
+	Reactions        []ReactionSummary `json:"reactions,omitempty"`           // Filled per viewer by the API, not stored
+}
+
+// MessageRevision represents a previous version of an edited message
+type MessageRevision struct {
+	ID          string    `json:"id"`
+	MessageID   string    `json:"message_id"`
+	MessageText string    `json:"message_text"`
+	CreatedAt   time.Time `json:"created_at"`  // When this text was written
+	ReplacedAt  time.Time `json:"replaced_at"` // When an edit superseded it
 }
 
 // MessageRead represents a read receipt for a message
@@ -71,3 +224,29 @@ type MessageRead struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// MessageDelivery represents a delivery receipt for a message
+// Stores which recipients have received each message
+type MessageDelivery struct {
+	ID        string    `json:"id"`
+	MessageID string    `json:"message_id"`
+	UserID    string    `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Reaction represents one user's emoji reaction to a message
+// A user can react with several emojis, but with each emoji only once
+type Reaction struct {
+	ID        string    `json:"id"`
+	MessageID string    `json:"message_id"`
+	UserID    string    `json:"user_id"`
+	Emoji     string    `json:"emoji"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReactionSummary aggregates the reactions with one emoji on a message
+type ReactionSummary struct {
+	Emoji       string `json:"emoji"`
+	Count       int    `json:"count"`
+	ReactedByMe bool   `json:"reacted_by_me"`
+}
@@ -0,0 +1,30 @@
+package database
+
+// PageDirection says which side of a position a page of messages is read from
+type PageDirection string
+
+const (
+	PageBefore PageDirection = "before" // Older messages, walking back through history
+	PageAfter  PageDirection = "after"  // Newer messages, catching up towards the present
+)
+
+// IsValid reports whether d is a known direction
+func (d PageDirection) IsValid() bool {
+	return d == PageBefore || d == PageAfter
+}
+
+// PageQuery selects one page of messages.
+// Without a Position the page holds the newest messages and Direction is ignored.
+type PageQuery struct {
+	Limit     int
+	Direction PageDirection
+	Position  string // ID of the message the page is read next to; it is not included
+}
+
+// MessagePage is one page of messages, newest first whichever direction it was read in.
+// The side a page was read away from is taken to have more messages: the position itself lies there.
+type MessagePage struct {
+	Messages []*Message
+	HasOlder bool // More messages exist before the oldest one in Messages
+	HasNewer bool // More messages exist after the newest one in Messages
+}
@@ -0,0 +1,1258 @@
+// Package repositorytest is a conformance suite for database.Repository. Every store
+// runs it from its own tests, so the handlers see the same behaviour whichever store
+// the server is configured with.
+package repositorytest
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+)
+
+// Run runs the suite against the stores newStore returns, a new empty one per test
+func Run(t *testing.T, newStore func(t *testing.T) database.Repository) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, s *suite)
+	}{
+		{"Pagination", testPagination},
+		{"ThreadPagination", testThreadPagination},
+		{"DeleteThreadReply", testDeleteThreadReply},
+		{"OneToOneFanOut", testOneToOneFanOut},
+		{"GroupFanOut", testGroupFanOut},
+		{"DeliveryAndReadStatus", testDeliveryAndReadStatus},
+		{"LateJoinerStatus", testLateJoinerStatus},
+		{"UnreadCounters", testUnreadCounters},
+		{"ReadWatermark", testReadWatermark},
+		{"EditDeletedMessage", testEditDeletedMessage},
+		{"Reactions", testReactions},
+		{"Search", testSearch},
+		{"HistoryVisibility", testHistoryVisibility},
+		{"CreateGroupWithMembers", testCreateGroupWithMembers},
+		{"AddGroupMembers", testAddGroupMembers},
+		{"UpdateGroupFields", testUpdateGroupFields},
+		{"TransferGroupOwnership", testTransferGroupOwnership},
+		{"GroupInvites", testGroupInvites},
+		{"JoinGroupWithInvite", testJoinGroupWithInvite},
+		{"UserIDs", testUserIDs},
+		{"UserCopies", testUserCopies},
+		{"Sessions", testSessions},
+		{"Credentials", testCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, &suite{Repository: newStore(t)})
+		})
+	}
+}
+
+// suite is the store under test and the state of one test
+type suite struct {
+	database.Repository
+	sent int
+}
+
+// send stores a message and returns it as stored
+func (s *suite) send(t *testing.T, message *database.Message) *database.Message {
+	t.Helper()
+	s.sent++
+	message.ID = fmt.Sprintf("m%d", s.sent)
+	if message.MessageText == "" {
+		message.MessageText = fmt.Sprintf("message %d", s.sent)
+	}
+	if err := s.CreateMessage(message); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := s.GetMessage(message.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stored
+}
+
+// direct sends a one-to-one message
+func (s *suite) direct(t *testing.T, senderID, destinationID string) *database.Message {
+	t.Helper()
+	return s.send(t, &database.Message{SenderID: senderID, DestinationID: destinationID, ConversationType: database.ConversationTypeOneToOne})
+}
+
+// toGroup sends a group message
+func (s *suite) toGroup(t *testing.T, senderID, groupID string) *database.Message {
+	t.Helper()
+	return s.send(t, &database.Message{SenderID: senderID, DestinationID: groupID, ConversationType: database.ConversationTypeGroup})
+}
+
+// group creates a group with the given members
+func (s *suite) group(t *testing.T, groupID string, memberIDs ...string) {
+	t.Helper()
+	if err := s.CreateGroup(&database.Group{ID: groupID, Name: groupID, CreatedBy: memberIDs[0]}); err != nil {
+		t.Fatal(err)
+	}
+	for _, userID := range memberIDs {
+		if err := s.AddGroupMember(groupID, userID, database.GroupRoleMember); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// conversation returns userID's entry for a conversation, or nil if they have none
+func (s *suite) conversation(t *testing.T, userID, conversationID string) *database.UserConversation {
+	t.Helper()
+	conversations, err := s.GetUserConversations(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, uc := range conversations {
+		if uc.ConversationID == conversationID {
+			return uc
+		}
+	}
+	return nil
+}
+
+// wantUnread checks userID's unread count in a conversation
+func (s *suite) wantUnread(t *testing.T, userID, conversationID string, want int) {
+	t.Helper()
+	uc := s.conversation(t, userID, conversationID)
+	if uc == nil {
+		t.Fatalf("%s has no entry for %s", userID, conversationID)
+	}
+	if uc.UnreadCount != want {
+		t.Errorf("%s has %d unread in %s, want %d", userID, uc.UnreadCount, conversationID, want)
+	}
+}
+
+// wantStatus checks the sender-visible status of a message
+func (s *suite) wantStatus(t *testing.T, messageID string, want database.MessageStatus) {
+	t.Helper()
+	message, err := s.GetMessage(messageID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.Status != want {
+		t.Errorf("%s is %s, want %s", messageID, message.Status, want)
+	}
+}
+
+// pageIDs returns the IDs of a page's messages, in page order
+func pageIDs(page *database.MessagePage) []string {
+	ids := make([]string, len(page.Messages))
+	for i, message := range page.Messages {
+		ids[i] = message.ID
+	}
+	return ids
+}
+
+// readerIDs returns who has read a message, by receipt or watermark
+func (s *suite) readerIDs(messageID string) map[string]bool {
+	readers := make(map[string]bool)
+	for _, mr := range s.GetMessageReads(messageID) {
+		readers[mr.UserID] = true
+	}
+	return readers
+}
+
+func testPagination(t *testing.T, s *suite) {
+	for i := 0; i < 7; i++ {
+		s.direct(t, "user1", "user2")
+	}
+	hidden := s.direct(t, "user2", "user1")
+	if err := s.DeleteMessageForUser(hidden.ID, "user1"); err != nil {
+		t.Fatal(err)
+	}
+	conversationID := database.OneToOneConversationID("user1", "user2")
+
+	tests := []struct {
+		name     string
+		query    database.PageQuery
+		wantIDs  []string
+		hasOlder bool
+		hasNewer bool
+	}{
+		{"newest", database.PageQuery{Limit: 3}, []string{"m7", "m6", "m5"}, true, false},
+		{"before a position", database.PageQuery{Limit: 3, Direction: database.PageBefore, Position: "m5"}, []string{"m4", "m3", "m2"}, true, true},
+		{"oldest", database.PageQuery{Limit: 3, Direction: database.PageBefore, Position: "m2"}, []string{"m1"}, false, true},
+		{"after a position", database.PageQuery{Limit: 3, Direction: database.PageAfter, Position: "m2"}, []string{"m5", "m4", "m3"}, true, true},
+		{"newest after a position", database.PageQuery{Limit: 3, Direction: database.PageAfter, Position: "m5"}, []string{"m7", "m6"}, true, false},
+		{"from a hidden position", database.PageQuery{Limit: 2, Direction: database.PageBefore, Position: hidden.ID}, []string{"m7", "m6"}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := s.GetMessages(conversationID, "user1", tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := fmt.Sprint(pageIDs(page)); got != fmt.Sprint(tt.wantIDs) {
+				t.Errorf("got %s, want %v", got, tt.wantIDs)
+			}
+			if page.HasOlder != tt.hasOlder || page.HasNewer != tt.hasNewer {
+				t.Errorf("has_older=%t has_newer=%t, want %t and %t", page.HasOlder, page.HasNewer, tt.hasOlder, tt.hasNewer)
+			}
+		})
+	}
+
+	t.Run("other user sees the hidden message", func(t *testing.T) {
+		page, err := s.GetMessages(conversationID, "user2", database.PageQuery{Limit: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := pageIDs(page); len(got) != 1 || got[0] != hidden.ID {
+			t.Errorf("got %v, want [%s]", got, hidden.ID)
+		}
+	})
+	t.Run("unknown position", func(t *testing.T) {
+		_, err := s.GetMessages(conversationID, "user1", database.PageQuery{Limit: 3, Direction: database.PageBefore, Position: "missing"})
+		if err == nil || err.Error() != database.ErrInvalidCursor {
+			t.Errorf("got %v, want %s", err, database.ErrInvalidCursor)
+		}
+	})
+}
+
+func testThreadPagination(t *testing.T, s *suite) {
+	root := s.direct(t, "user1", "user2")
+	replies := make([]string, 0)
+	for i := 0; i < 3; i++ {
+		reply := s.send(t, &database.Message{
+			SenderID: "user2", DestinationID: "user1", ConversationType: database.ConversationTypeOneToOne, ThreadRootID: root.ID,
+		})
+		replies = append(replies, reply.ID)
+	}
+	s.direct(t, "user2", "user1")
+
+	main, err := s.GetMessages(root.ConversationID, "user1", database.PageQuery{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(pageIDs(main)); got != "[m5 m1]" {
+		t.Errorf("conversation holds %s, want thread replies left out", got)
+	}
+
+	thread, err := s.GetThreadMessages(root.ID, "user1", database.PageQuery{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(pageIDs(thread)); got != fmt.Sprint([]string{replies[2], replies[1]}) || !thread.HasOlder {
+		t.Errorf("thread page is %s has_older=%t, want the two newest replies and more", got, thread.HasOlder)
+	}
+	older, err := s.GetThreadMessages(root.ID, "user1", database.PageQuery{Limit: 2, Direction: database.PageBefore, Position: replies[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(pageIDs(older)); got != fmt.Sprint([]string{replies[0]}) || older.HasOlder {
+		t.Errorf("older thread page is %s has_older=%t, want the first reply only", got, older.HasOlder)
+	}
+
+	if root, err = s.GetMessage(root.ID); err != nil {
+		t.Fatal(err)
+	}
+	if root.ReplyCount != 3 || root.LastReplyAt == nil {
+		t.Errorf("root has %d replies, last at %v, want 3", root.ReplyCount, root.LastReplyAt)
+	}
+}
+
+func testDeleteThreadReply(t *testing.T, s *suite) {
+	root := s.direct(t, "user1", "user2")
+	replies := make([]*database.Message, 3)
+	for i := range replies {
+		replies[i] = s.send(t, &database.Message{
+			SenderID: "user2", DestinationID: "user1", ConversationType: database.ConversationTypeOneToOne, ThreadRootID: root.ID,
+		})
+	}
+
+	steps := []struct {
+		name       string
+		delete     *database.Message
+		wantCount  int
+		wantLastAt *time.Time
+	}{
+		{"newest reply", replies[2], 2, &replies[1].CreatedAt},
+		{"oldest reply", replies[0], 1, &replies[1].CreatedAt},
+		{"same reply again", replies[0], 1, &replies[1].CreatedAt},
+		{"last reply standing", replies[1], 0, nil},
+	}
+	for _, step := range steps {
+		if _, err := s.DeleteMessageForEveryone(step.delete.ID); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		stored, err := s.GetMessage(root.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lastAtMatches := stored.LastReplyAt == nil && step.wantLastAt == nil ||
+			stored.LastReplyAt != nil && step.wantLastAt != nil && stored.LastReplyAt.Equal(*step.wantLastAt)
+		if stored.ReplyCount != step.wantCount || !lastAtMatches {
+			t.Errorf("after deleting the %s: %d replies, last at %v, want %d at %v",
+				step.name, stored.ReplyCount, stored.LastReplyAt, step.wantCount, step.wantLastAt)
+		}
+	}
+}
+
+func testOneToOneFanOut(t *testing.T, s *suite) {
+	message := s.direct(t, "user2", "user1")
+	conversationID := database.OneToOneConversationID("user1", "user2")
+	if message.ConversationID != conversationID {
+		t.Errorf("message is in %s, want %s", message.ConversationID, conversationID)
+	}
+
+	for userID, peerID := range map[string]string{"user1": "user2", "user2": "user1"} {
+		uc := s.conversation(t, userID, conversationID)
+		if uc == nil {
+			t.Fatalf("%s has no entry for %s", userID, conversationID)
+		}
+		if uc.DestinationID != peerID || uc.ConversationType != database.ConversationTypeOneToOne {
+			t.Errorf("%s's entry points at %s (%s), want %s", userID, uc.DestinationID, uc.ConversationType, peerID)
+		}
+	}
+	if s.conversation(t, "user3", conversationID) != nil {
+		t.Error("a third user got an entry for the chat")
+	}
+
+	// Both participants read the same history from either side
+	reply := s.direct(t, "user1", "user2")
+	for _, userID := range []string{"user1", "user2"} {
+		page, err := s.GetMessages(conversationID, userID, database.PageQuery{Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprint(pageIDs(page)); got != fmt.Sprint([]string{reply.ID, message.ID}) {
+			t.Errorf("%s reads %s, want both messages", userID, got)
+		}
+	}
+}
+
+func testGroupFanOut(t *testing.T, s *suite) {
+	s.group(t, "group1", "user1", "user2", "user3")
+	s.toGroup(t, "user1", "group1")
+
+	for _, userID := range []string{"user1", "user2", "user3"} {
+		uc := s.conversation(t, userID, "group1")
+		if uc == nil {
+			t.Fatalf("%s has no entry for group1", userID)
+		}
+		if uc.DestinationID != "group1" || uc.ConversationType != database.ConversationTypeGroup {
+			t.Errorf("%s's entry points at %s (%s), want group1", userID, uc.DestinationID, uc.ConversationType)
+		}
+	}
+	if s.conversation(t, "user4", "group1") != nil {
+		t.Error("a non-member got an entry for the group")
+	}
+
+	// A member who leaves loses the group from their list and gets no new entries
+	if err := s.RemoveGroupMember("group1", "user3"); err != nil {
+		t.Fatal(err)
+	}
+	s.toGroup(t, "user2", "group1")
+	if s.conversation(t, "user3", "group1") != nil {
+		t.Error("a member who left still has the group")
+	}
+}
+
+func testDeliveryAndReadStatus(t *testing.T, s *suite) {
+	t.Run("one-to-one", func(t *testing.T) {
+		message := s.direct(t, "user1", "user2")
+		s.wantStatus(t, message.ID, database.StatusSent)
+
+		if err := s.CreateMessageDelivery(message.ID, "user2"); err != nil {
+			t.Fatal(err)
+		}
+		s.wantStatus(t, message.ID, database.StatusDelivered)
+
+		if err := s.CreateMessageRead(message.ID, "user2"); err != nil {
+			t.Fatal(err)
+		}
+		s.wantStatus(t, message.ID, database.StatusRead)
+		if len(s.GetMessageDeliveries(message.ID)) != 1 || !s.readerIDs(message.ID)["user2"] {
+			t.Error("delivery or read receipt missing")
+		}
+	})
+
+	t.Run("group waits for every recipient", func(t *testing.T) {
+		s.group(t, "group1", "user1", "user2", "user3")
+		message := s.toGroup(t, "user1", "group1")
+
+		if err := s.CreateMessageDeliveries([]string{message.ID}, "user2"); err != nil {
+			t.Fatal(err)
+		}
+		s.wantStatus(t, message.ID, database.StatusSent)
+		if err := s.CreateMessageDeliveries([]string{message.ID}, "user3"); err != nil {
+			t.Fatal(err)
+		}
+		s.wantStatus(t, message.ID, database.StatusDelivered)
+
+		if err := s.CreateMessageReads([]string{message.ID}, "user2"); err != nil {
+			t.Fatal(err)
+		}
+		s.wantStatus(t, message.ID, database.StatusDelivered)
+		if err := s.CreateMessageReads([]string{message.ID}, "user3"); err != nil {
+			t.Fatal(err)
+		}
+		s.wantStatus(t, message.ID, database.StatusRead)
+	})
+
+	t.Run("reading implies delivery", func(t *testing.T) {
+		message := s.direct(t, "user3", "user1")
+		if err := s.CreateMessageRead(message.ID, "user1"); err != nil {
+			t.Fatal(err)
+		}
+		s.wantStatus(t, message.ID, database.StatusRead)
+		if deliveries := s.GetMessageDeliveries(message.ID); len(deliveries) != 1 || deliveries[0].UserID != "user1" {
+			t.Errorf("got %d deliveries, want one for user1", len(deliveries))
+		}
+	})
+}
+
+// testLateJoinerStatus checks that members who join after a message was sent are not
+// waited for: they never receive it, so the status follows the members who were there
+func testLateJoinerStatus(t *testing.T, s *suite) {
+	s.group(t, "group1", "user1", "user2")
+	visibility := database.HistoryVisibilitySinceJoined
+	if _, err := s.UpdateGroup("group1", database.GroupUpdate{HistoryVisibility: &visibility}); err != nil {
+		t.Fatal(err)
+	}
+	byReceipt := s.toGroup(t, "user1", "group1")
+	byWatermark := s.toGroup(t, "user1", "group1")
+	if err := s.AddGroupMember("group1", "user3", database.GroupRoleMember); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.CreateMessageDelivery(byReceipt.ID, "user2"); err != nil {
+		t.Fatal(err)
+	}
+	s.wantStatus(t, byReceipt.ID, database.StatusDelivered)
+	if err := s.CreateMessageRead(byReceipt.ID, "user2"); err != nil {
+		t.Fatal(err)
+	}
+	s.wantStatus(t, byReceipt.ID, database.StatusRead)
+
+	if _, _, err := s.MarkConversationRead("group1", "user2", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	s.wantStatus(t, byWatermark.ID, database.StatusRead)
+}
+
+func testUnreadCounters(t *testing.T, s *suite) {
+	conversationID := database.OneToOneConversationID("user1", "user2")
+	first := s.direct(t, "user2", "user1")
+	second := s.direct(t, "user2", "user1")
+	third := s.direct(t, "user2", "user1")
+	s.direct(t, "user1", "user2")
+	// Nobody counts the messages they sent themselves
+	s.wantUnread(t, "user1", conversationID, 3)
+	s.wantUnread(t, "user2", conversationID, 1)
+
+	steps := []struct {
+		name string
+		do   func() error
+		want int
+	}{
+		{"read", func() error { return s.CreateMessageRead(first.ID, "user1") }, 2},
+		{"read again", func() error { return s.CreateMessageRead(first.ID, "user1") }, 2},
+		{"read by the sender", func() error { return s.CreateMessageRead(second.ID, "user2") }, 2},
+		{"hidden", func() error { return s.DeleteMessageForUser(second.ID, "user1") }, 1},
+		{"deleted after being hidden", func() error {
+			_, err := s.DeleteMessageForEveryone(second.ID)
+			return err
+		}, 1},
+		{"deleted for everyone", func() error {
+			_, err := s.DeleteMessageForEveryone(third.ID)
+			return err
+		}, 0},
+	}
+	for _, step := range steps {
+		if err := step.do(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if uc := s.conversation(t, "user1", conversationID); uc.UnreadCount != step.want {
+			t.Fatalf("after %s: %d unread, want %d", step.name, uc.UnreadCount, step.want)
+		}
+	}
+
+	t.Run("messages that never count", func(t *testing.T) {
+		s.send(t, &database.Message{SenderID: "user2", DestinationID: "user1", ConversationType: database.ConversationTypeOneToOne, ThreadRootID: first.ID})
+		s.send(t, &database.Message{
+			SenderID: "user2", DestinationID: "user1", ConversationType: database.ConversationTypeOneToOne,
+			Kind: database.MessageKindSystem, SystemType: database.SystemGroupRenamed,
+		})
+		s.wantUnread(t, "user1", conversationID, 0)
+	})
+
+	t.Run("group members count messages from after they joined", func(t *testing.T) {
+		s.group(t, "group1", "user1", "user2")
+		s.toGroup(t, "user1", "group1")
+		if err := s.AddGroupMember("group1", "user3", database.GroupRoleMember); err != nil {
+			t.Fatal(err)
+		}
+		late := s.toGroup(t, "user1", "group1")
+		s.wantUnread(t, "user2", "group1", 2)
+		s.wantUnread(t, "user3", "group1", 1)
+		s.wantUnread(t, "user1", "group1", 0)
+
+		if err := s.CreateMessageRead(late.ID, "user3"); err != nil {
+			t.Fatal(err)
+		}
+		s.wantUnread(t, "user3", "group1", 0)
+		s.wantUnread(t, "user2", "group1", 2)
+	})
+}
+
+func testReadWatermark(t *testing.T, s *suite) {
+	conversationID := database.OneToOneConversationID("user1", "user2")
+	messages := make([]*database.Message, 4)
+	for i := range messages {
+		messages[i] = s.direct(t, "user2", "user1")
+	}
+	if err := s.CreateMessageRead(messages[3].ID, "user1"); err != nil {
+		t.Fatal(err)
+	}
+
+	uc, marked, err := s.MarkConversationRead(conversationID, "user1", messages[1].CreatedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uc.UnreadCount != 1 || marked != 2 {
+		t.Errorf("%d unread and %d marked, want 1 left after the watermark and 2 marked", uc.UnreadCount, marked)
+	}
+	if uc.ReadUpTo == nil || !uc.ReadUpTo.Equal(messages[1].CreatedAt) || uc.ReadMarkedAt == nil {
+		t.Errorf("watermark is %v, want %v", uc.ReadUpTo, messages[1].CreatedAt)
+	}
+	for _, message := range messages[:2] {
+		s.wantStatus(t, message.ID, database.StatusRead)
+		if !s.readerIDs(message.ID)["user1"] {
+			t.Errorf("%s has no read by user1", message.ID)
+		}
+	}
+	s.wantStatus(t, messages[2].ID, database.StatusSent)
+	if s.readerIDs(messages[2].ID)["user1"] {
+		t.Errorf("%s after the watermark was read", messages[2].ID)
+	}
+
+	// Messages behind the watermark are already off the count
+	if _, err := s.DeleteMessageForEveryone(messages[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateMessageRead(messages[1].ID, "user1"); err != nil {
+		t.Fatal(err)
+	}
+	s.wantUnread(t, "user1", conversationID, 1)
+
+	t.Run("never moves back", func(t *testing.T) {
+		uc, marked, err := s.MarkConversationRead(conversationID, "user1", messages[0].CreatedAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if marked != 0 || !uc.ReadUpTo.Equal(messages[1].CreatedAt) {
+			t.Errorf("older marker moved the watermark to %v and marked %d", uc.ReadUpTo, marked)
+		}
+	})
+	t.Run("whole conversation", func(t *testing.T) {
+		uc, marked, err := s.MarkConversationRead(conversationID, "user1", time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if uc.UnreadCount != 0 || marked != 1 {
+			t.Errorf("%d unread and %d marked, want 0 and 1", uc.UnreadCount, marked)
+		}
+		later := s.direct(t, "user2", "user1")
+		s.wantUnread(t, "user1", conversationID, 1)
+		s.wantStatus(t, later.ID, database.StatusSent)
+	})
+	t.Run("group status waits for every member", func(t *testing.T) {
+		s.group(t, "group1", "user1", "user2", "user3")
+		message := s.toGroup(t, "user1", "group1")
+		if _, _, err := s.MarkConversationRead("group1", "user2", time.Now()); err != nil {
+			t.Fatal(err)
+		}
+		s.wantStatus(t, message.ID, database.StatusSent)
+		if _, _, err := s.MarkConversationRead("group1", "user3", time.Now()); err != nil {
+			t.Fatal(err)
+		}
+		s.wantStatus(t, message.ID, database.StatusRead)
+	})
+	t.Run("without an entry", func(t *testing.T) {
+		uc, marked, err := s.MarkConversationRead(database.OneToOneConversationID("user3", "user4"), "user3", time.Now())
+		if err != nil || uc != nil || marked != 0 {
+			t.Errorf("got %v, %d, %v, want nothing marked", uc, marked, err)
+		}
+	})
+}
+
+func testEditDeletedMessage(t *testing.T, s *suite) {
+	message := s.direct(t, "user1", "user2")
+	edited, err := s.UpdateMessage(message.ID, "edited")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edited.MessageText != "edited" || edited.EditedAt == nil {
+		t.Errorf("edit saved %q, edited_at %v", edited.MessageText, edited.EditedAt)
+	}
+	if revisions, err := s.GetMessageRevisions(message.ID); err != nil || len(revisions) != 1 || revisions[0].MessageText != message.MessageText {
+		t.Errorf("got revisions %v, %v, want the original text kept", revisions, err)
+	}
+
+	if _, err := s.DeleteMessageForEveryone(message.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.UpdateMessage(message.ID, "after delete"); err == nil || err.Error() != database.ErrMessageDeleted {
+		t.Errorf("editing a deleted message returned %v, want %s", err, database.ErrMessageDeleted)
+	}
+	if _, err := s.UpdateMessage("missing", "text"); err == nil || err.Error() != database.ErrMessageNotFound {
+		t.Errorf("editing a missing message returned %v, want %s", err, database.ErrMessageNotFound)
+	}
+
+	system := s.send(t, &database.Message{
+		SenderID: "user1", DestinationID: "user2", ConversationType: database.ConversationTypeOneToOne,
+		Kind: database.MessageKindSystem, SystemType: database.SystemGroupRenamed,
+	})
+	if _, err := s.UpdateMessage(system.ID, "text"); err == nil || err.Error() != database.ErrSystemMessage {
+		t.Errorf("editing a system message returned %v, want %s", err, database.ErrSystemMessage)
+	}
+}
+
+func testReactions(t *testing.T, s *suite) {
+	message := s.direct(t, "user1", "user2")
+	other := s.direct(t, "user1", "user2")
+
+	steps := []struct {
+		name string
+		do   func() error
+		want []string // user/emoji pairs on message afterwards
+	}{
+		{"add", func() error { return s.AddReaction(message.ID, "user2", "👍") }, []string{"user2/👍"}},
+		{"add again", func() error { return s.AddReaction(message.ID, "user2", "👍") }, []string{"user2/👍"}},
+		{"same emoji by another user", func() error { return s.AddReaction(message.ID, "user1", "👍") }, []string{"user1/👍", "user2/👍"}},
+		{"another emoji", func() error { return s.AddReaction(message.ID, "user2", "🎉") }, []string{"user1/👍", "user2/👍", "user2/🎉"}},
+		{"remove", func() error { return s.RemoveReaction(message.ID, "user2", "👍") }, []string{"user1/👍", "user2/🎉"}},
+		{"remove again", func() error { return s.RemoveReaction(message.ID, "user2", "👍") }, []string{"user1/👍", "user2/🎉"}},
+	}
+	for _, step := range steps {
+		if err := step.do(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		reactions, err := s.GetMessageReactions([]string{message.ID, other.ID, "missing"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]string, 0, len(reactions[message.ID]))
+		for _, reaction := range reactions[message.ID] {
+			if reaction.MessageID != message.ID {
+				t.Errorf("after %s: reaction %+v is keyed under %s", step.name, reaction, message.ID)
+			}
+			got = append(got, reaction.UserID+"/"+reaction.Emoji)
+		}
+		sort.Strings(got)
+		sort.Strings(step.want)
+		if fmt.Sprint(got) != fmt.Sprint(step.want) {
+			t.Errorf("after %s: reactions %v, want %v", step.name, got, step.want)
+		}
+		if _, exists := reactions[other.ID]; exists {
+			t.Errorf("after %s: a message without reactions has an entry", step.name)
+		}
+	}
+
+	// Reactions racing a delete for everyone either land first and are discarded with
+	// the message, or are refused
+	const reactors = 10
+	var wg sync.WaitGroup
+	for i := 0; i < reactors; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := s.AddReaction(other.ID, fmt.Sprintf("reactor%d", i), "👍")
+			if err != nil && err.Error() != database.ErrMessageDeleted {
+				t.Errorf("reacting during a delete returned %v, want nil or %s", err, database.ErrMessageDeleted)
+			}
+		}(i)
+	}
+	if _, err := s.DeleteMessageForEveryone(other.ID); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	if err := s.AddReaction(other.ID, "user2", "👍"); err == nil || err.Error() != database.ErrMessageDeleted {
+		t.Errorf("reacting to a deleted message returned %v, want %s", err, database.ErrMessageDeleted)
+	}
+	if reactions, err := s.GetMessageReactions([]string{other.ID}); err != nil || len(reactions[other.ID]) != 0 {
+		t.Errorf("a deleted message has reactions %v, %v", reactions[other.ID], err)
+	}
+
+	if err := s.AddReaction("missing", "user1", "👍"); err == nil || err.Error() != database.ErrMessageNotFound {
+		t.Errorf("reacting to a missing message returned %v, want %s", err, database.ErrMessageNotFound)
+	}
+	if err := s.RemoveReaction("missing", "user1", "👍"); err == nil || err.Error() != database.ErrMessageNotFound {
+		t.Errorf("unreacting to a missing message returned %v, want %s", err, database.ErrMessageNotFound)
+	}
+}
+
+func testSearch(t *testing.T, s *suite) {
+	s.group(t, "group1", "user1", "user2")
+	direct := s.send(t, &database.Message{SenderID: "user1", DestinationID: "user2", ConversationType: database.ConversationTypeOneToOne, MessageText: "Lunch at noon?"})
+	inGroup := s.send(t, &database.Message{SenderID: "user2", DestinationID: "group1", ConversationType: database.ConversationTypeGroup, MessageText: "lunch is on me"})
+	hidden := s.send(t, &database.Message{SenderID: "user1", DestinationID: "user2", ConversationType: database.ConversationTypeOneToOne, MessageText: "lunch, take two"})
+	deleted := s.send(t, &database.Message{SenderID: "user1", DestinationID: "user2", ConversationType: database.ConversationTypeOneToOne, MessageText: "lunch, scrapped"})
+	s.send(t, &database.Message{SenderID: "user1", DestinationID: "group1", ConversationType: database.ConversationTypeGroup, MessageText: "lunch group renamed", Kind: database.MessageKindSystem})
+	s.send(t, &database.Message{SenderID: "user3", DestinationID: "user4", ConversationType: database.ConversationTypeOneToOne, MessageText: "lunch elsewhere"})
+	s.send(t, &database.Message{SenderID: "user1", DestinationID: "user2", ConversationType: database.ConversationTypeOneToOne, MessageText: "dinner"})
+	if err := s.DeleteMessageForUser(hidden.ID, "user2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.DeleteMessageForEveryone(deleted.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		userID string
+		query  string
+		want   []string
+	}{
+		{"any case", "user2", "LUNCH", []string{direct.ID, inGroup.ID}},
+		{"not hidden by another user", "user1", "lunch", []string{direct.ID, inGroup.ID, hidden.ID}},
+		{"not a participant", "user5", "lunch", nil},
+		{"wildcards match literally", "user1", "%", nil},
+		{"no match", "user1", "breakfast", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := s.SearchMessages(tt.userID, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, 0, len(results))
+			for _, message := range results {
+				got = append(got, message.ID)
+			}
+			sort.Strings(got)
+			sort.Strings(tt.want)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("search found %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// testHistoryVisibility checks that a since_joined group hides what was sent before
+// a member joined from their history, search and unread count, and that switching
+// back to full history shows it again. Unread counts start when a member joins either way.
+func testHistoryVisibility(t *testing.T, s *suite) {
+	s.group(t, "group1", "user1", "user2")
+	visibility := database.HistoryVisibilitySinceJoined
+	if _, err := s.UpdateGroup("group1", database.GroupUpdate{HistoryVisibility: &visibility}); err != nil {
+		t.Fatal(err)
+	}
+	before := s.toGroup(t, "user1", "group1")
+	if err := s.AddGroupMember("group1", "user3", database.GroupRoleMember); err != nil {
+		t.Fatal(err)
+	}
+	after := s.toGroup(t, "user1", "group1")
+
+	check := func(t *testing.T, userID string, want []string, wantUnread int) {
+		t.Helper()
+		page, err := s.GetMessages("group1", userID, database.PageQuery{Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := pageIDs(page); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%s's history is %v, want %v", userID, got, want)
+		}
+		results, err := s.SearchMessages(userID, "message")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != len(want) {
+			t.Errorf("%s's search found %d messages, want %d", userID, len(results), len(want))
+		}
+		s.wantUnread(t, userID, "group1", wantUnread)
+	}
+
+	t.Run("since joined", func(t *testing.T) {
+		check(t, "user3", []string{after.ID}, 1)
+		check(t, "user2", []string{after.ID, before.ID}, 2)
+	})
+	t.Run("full", func(t *testing.T) {
+		visibility := database.HistoryVisibilityFull
+		if _, err := s.UpdateGroup("group1", database.GroupUpdate{HistoryVisibility: &visibility}); err != nil {
+			t.Fatal(err)
+		}
+		check(t, "user3", []string{after.ID, before.ID}, 1)
+	})
+}
+
+func testCreateGroupWithMembers(t *testing.T, s *suite) {
+	members := []*database.GroupMember{
+		{UserID: "user1", Role: database.GroupRoleOwner},
+		{UserID: "user2", Role: database.GroupRoleMember},
+	}
+	if err := s.CreateGroupWithMembers(&database.Group{ID: "group1", Name: "Team", CreatedBy: "user1"}, members); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := s.ListGroupMembers("group1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != len(members) {
+		t.Fatalf("group has %d members, want %d", len(stored), len(members))
+	}
+	for i, member := range stored {
+		if member.UserID != members[i].UserID || member.Role != members[i].Role || member.JoinedAt.IsZero() {
+			t.Errorf("member %d is %+v, want %s as %s with a join time", i, member, members[i].UserID, members[i].Role)
+		}
+	}
+
+	// A group that cannot be created adds no members to the existing one
+	err = s.CreateGroupWithMembers(&database.Group{ID: "group1", Name: "Again", CreatedBy: "user3"}, []*database.GroupMember{
+		{UserID: "user3", Role: database.GroupRoleOwner},
+	})
+	if err == nil || err.Error() != database.ErrGroupAlreadyExists {
+		t.Errorf("creating an existing group returned %v, want %s", err, database.ErrGroupAlreadyExists)
+	}
+	if s.IsGroupMember("group1", "user3") {
+		t.Error("a failed create added its members to the existing group")
+	}
+}
+
+func testAddGroupMembers(t *testing.T, s *suite) {
+	s.group(t, "group1", "user1", "user2")
+
+	added, err := s.AddGroupMembers("group1", []string{"user2", "user3", "user3"}, database.GroupRoleMember, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(added) != "[user3]" {
+		t.Errorf("added %v, want only the new user3", added)
+	}
+
+	// A batch that does not fit is refused whole
+	if _, err := s.AddGroupMembers("group1", []string{"user4", "user5"}, database.GroupRoleMember, 4); err == nil || err.Error() != database.ErrGroupFull {
+		t.Errorf("overfilling the group returned %v, want %s", err, database.ErrGroupFull)
+	}
+	if s.IsGroupMember("group1", "user4") {
+		t.Error("part of a refused batch was added")
+	}
+
+	// Concurrent batches cannot push the group past the limit between them
+	const batches = 5
+	var wg sync.WaitGroup
+	for i := 0; i < batches; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			userIDs := []string{fmt.Sprintf("batch%d-a", i), fmt.Sprintf("batch%d-b", i)}
+			if _, err := s.AddGroupMembers("group1", userIDs, database.GroupRoleMember, 7); err != nil && err.Error() != database.ErrGroupFull {
+				t.Errorf("concurrent add returned %v, want nil or %s", err, database.ErrGroupFull)
+			}
+		}(i)
+	}
+	wg.Wait()
+	members, err := s.ListGroupMembers("group1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 7 {
+		t.Errorf("group has %d members after concurrent adds, want 7", len(members))
+	}
+
+	if _, err := s.AddGroupMembers("missing", []string{"user1"}, database.GroupRoleMember, 4); err == nil || err.Error() != database.ErrGroupNotFound {
+		t.Errorf("adding to a missing group returned %v, want %s", err, database.ErrGroupNotFound)
+	}
+}
+
+func testUpdateGroupFields(t *testing.T, s *suite) {
+	s.group(t, "group1", "user1")
+
+	name := "Renamed"
+	if _, err := s.UpdateGroup("group1", database.GroupUpdate{Name: &name}); err != nil {
+		t.Fatal(err)
+	}
+	announcementOnly := true
+	visibility := database.HistoryVisibilitySinceJoined
+	updated, err := s.UpdateGroup("group1", database.GroupUpdate{AnnouncementOnly: &announcementOnly, HistoryVisibility: &visibility})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := s.GetGroup("group1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, group := range []*database.Group{updated, saved} {
+		if group.Name != name || !group.AnnouncementOnly || group.HistoryVisibility != visibility || group.Description != "" {
+			t.Errorf("group is %+v, want every update kept and the rest unchanged", group)
+		}
+	}
+
+	// Groups handed out are copies
+	saved.Name = "changed by the caller"
+	if again, _ := s.GetGroup("group1"); again.Name != name {
+		t.Errorf("changing a returned group changed the stored one to %q", again.Name)
+	}
+
+	if _, err := s.UpdateGroup("missing", database.GroupUpdate{Name: &name}); err == nil || err.Error() != database.ErrGroupNotFound {
+		t.Errorf("updating a missing group returned %v, want %s", err, database.ErrGroupNotFound)
+	}
+}
+
+func testTransferGroupOwnership(t *testing.T, s *suite) {
+	s.group(t, "group1", "user1", "user2", "user3")
+	if err := s.SetGroupMemberRole("group1", "user1", database.GroupRoleOwner); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.TransferGroupOwnership("group1", "user1", "user2"); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]database.GroupRole{"user1": database.GroupRoleAdmin, "user2": database.GroupRoleOwner, "user3": database.GroupRoleMember}
+	wantRoles := func() {
+		t.Helper()
+		for userID, role := range want {
+			member, err := s.GetGroupMember("group1", userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if member.Role != role {
+				t.Errorf("%s is %s, want %s", userID, member.Role, role)
+			}
+		}
+	}
+	wantRoles()
+
+	// Failed transfers change nothing
+	if err := s.TransferGroupOwnership("group1", "user1", "user3"); err == nil || err.Error() != database.ErrNotGroupOwner {
+		t.Errorf("transfer by a former owner returned %v, want %s", err, database.ErrNotGroupOwner)
+	}
+	if err := s.TransferGroupOwnership("group1", "user2", "user4"); err == nil || err.Error() != database.ErrGroupMemberNotFound {
+		t.Errorf("transfer to a non-member returned %v, want %s", err, database.ErrGroupMemberNotFound)
+	}
+	wantRoles()
+}
+
+func testGroupInvites(t *testing.T, s *suite) {
+	s.group(t, "group1", "user1")
+	expiresAt := time.Now().Add(time.Hour)
+	first := &database.GroupInvite{ID: "invite1", GroupID: "group1", CreatedBy: "user1", UseCount: 5}
+	second := &database.GroupInvite{ID: "invite2", GroupID: "group1", CreatedBy: "user1", MaxUses: 3, ExpiresAt: &expiresAt}
+	for _, invite := range []*database.GroupInvite{first, second} {
+		if err := s.CreateGroupInvite(invite); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.CreateGroupInvite(&database.GroupInvite{ID: "invite1", GroupID: "group1", CreatedBy: "user1"}); err == nil {
+		t.Error("an invite ID was used twice")
+	}
+	if err := s.CreateGroupInvite(&database.GroupInvite{ID: "invite3", GroupID: "missing", CreatedBy: "user1"}); err == nil || err.Error() != database.ErrGroupNotFound {
+		t.Errorf("inviting to a missing group returned %v, want %s", err, database.ErrGroupNotFound)
+	}
+
+	stored, err := s.GetGroupInvite("invite2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.MaxUses != 3 || stored.ExpiresAt == nil || !stored.ExpiresAt.Equal(expiresAt) || stored.RevokedAt != nil {
+		t.Errorf("stored invite is %+v, want 3 uses that expire at %v", stored, expiresAt)
+	}
+	invites, err := s.ListGroupInvites("group1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(invites) != 2 || invites[0].ID != "invite2" || invites[1].ID != "invite1" || invites[1].UseCount != 0 {
+		t.Errorf("listed %+v, want invite2 then invite1, both unused", invites)
+	}
+
+	if err := s.RevokeGroupInvite("invite1"); err != nil {
+		t.Fatal(err)
+	}
+	revoked, err := s.GetGroupInvite("invite1")
+	if err != nil || revoked.RevokedAt == nil {
+		t.Fatalf("revoked invite is %+v, %v", revoked, err)
+	}
+	if err := s.RevokeGroupInvite("invite1"); err != nil {
+		t.Fatal(err)
+	}
+	if again, err := s.GetGroupInvite("invite1"); err != nil || !again.RevokedAt.Equal(*revoked.RevokedAt) {
+		t.Errorf("revoking twice moved the revocation from %v to %+v, %v", revoked.RevokedAt, again, err)
+	}
+
+	if _, err := s.GetGroupInvite("missing"); err == nil || err.Error() != database.ErrInviteNotFound {
+		t.Errorf("getting a missing invite returned %v, want %s", err, database.ErrInviteNotFound)
+	}
+	if err := s.RevokeGroupInvite("missing"); err == nil || err.Error() != database.ErrInviteNotFound {
+		t.Errorf("revoking a missing invite returned %v, want %s", err, database.ErrInviteNotFound)
+	}
+	if _, err := s.ListGroupInvites("missing"); err == nil || err.Error() != database.ErrGroupNotFound {
+		t.Errorf("listing the invites of a missing group returned %v, want %s", err, database.ErrGroupNotFound)
+	}
+}
+
+func testJoinGroupWithInvite(t *testing.T, s *suite) {
+	s.group(t, "group1", "user1")
+	if err := s.CreateGroupInvite(&database.GroupInvite{ID: "invite1", GroupID: "group1", CreatedBy: "user1", MaxUses: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Concurrent joins by one user add them once and use the invite once
+	const joins = 5
+	errs := make(chan error, joins)
+	var wg sync.WaitGroup
+	for i := 0; i < joins; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.JoinGroupWithInvite("invite1", "user2", 10)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	joined := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			joined++
+		case err.Error() != database.ErrAlreadyGroupMember:
+			t.Errorf("concurrent join returned %v, want nil or %s", err, database.ErrAlreadyGroupMember)
+		}
+	}
+	if joined != 1 {
+		t.Errorf("%d concurrent joins succeeded, want 1", joined)
+	}
+	if member, err := s.GetGroupMember("group1", "user2"); err != nil || member.Role != database.GroupRoleMember {
+		t.Errorf("got member %v, %v, want user2 as a member", member, err)
+	}
+
+	invite, err := s.JoinGroupWithInvite("invite1", "user3", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invite.UseCount != 2 {
+		t.Errorf("invite used %d times, want 2", invite.UseCount)
+	}
+
+	past := time.Now().Add(-time.Minute)
+	for _, invite := range []*database.GroupInvite{
+		{ID: "expired", GroupID: "group1", CreatedBy: "user1", ExpiresAt: &past},
+		{ID: "revoked", GroupID: "group1", CreatedBy: "user1"},
+		{ID: "open", GroupID: "group1", CreatedBy: "user1"},
+	} {
+		if err := s.CreateGroupInvite(invite); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.RevokeGroupInvite("revoked"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		inviteID   string
+		maxMembers int
+		want       string
+	}{
+		{"used up", "invite1", 10, database.ErrInviteExhausted},
+		{"expired", "expired", 10, database.ErrInviteExpired},
+		{"revoked", "revoked", 10, database.ErrInviteRevoked},
+		{"unknown", "missing", 10, database.ErrInviteNotFound},
+		{"group full", "open", 3, database.ErrGroupFull},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.JoinGroupWithInvite(tt.inviteID, "user4", tt.maxMembers); err == nil || err.Error() != tt.want {
+				t.Errorf("got %v, want %s", err, tt.want)
+			}
+		})
+	}
+	if s.IsGroupMember("group1", "user4") {
+		t.Error("a refused join added the user")
+	}
+	if invite, err := s.GetGroupInvite("open"); err != nil || invite.UseCount != 0 {
+		t.Errorf("got %v, %v, want a refused join to leave the invite unused", invite, err)
+	}
+}
+
+func testUserIDs(t *testing.T, s *suite) {
+	// A separator in an ID would make one-to-one keys ambiguous: "a:b" and "c" vs "a" and "b:c"
+	for _, id := range []string{"", "a:b", "dm:user1"} {
+		if err := s.CreateUser(&database.User{ID: id, Name: "Eve"}); err == nil || err.Error() != database.ErrInvalidUserID {
+			t.Errorf("creating user %q returned %v, want %s", id, err, database.ErrInvalidUserID)
+		}
+		credential := &database.Credential{LoginName: "eve", PasswordHash: "hash"}
+		if err := s.CreateUserWithCredential(&database.User{ID: id, Name: "Eve"}, credential); err == nil || err.Error() != database.ErrInvalidUserID {
+			t.Errorf("signing up user %q returned %v, want %s", id, err, database.ErrInvalidUserID)
+		}
+	}
+	if _, err := s.GetCredentialByLogin("eve"); err == nil {
+		t.Error("a rejected sign-up left its credential behind")
+	}
+	if err := s.CreateUser(&database.User{ID: "user1", Name: "Alice"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testUserCopies checks that users are stored and returned by value, so editing one
+// in a handler changes nothing until UpdateUser saves it
+func testUserCopies(t *testing.T, s *suite) {
+	user := &database.User{ID: "user1", Name: "Alice", Email: "alice@example.com"}
+	if err := s.CreateUser(user); err != nil {
+		t.Fatal(err)
+	}
+	user.Name = "Mallory"
+
+	fetched, err := s.GetUser("user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fetched.Name != "Alice" {
+		t.Errorf("editing the created user renamed the stored one to %q", fetched.Name)
+	}
+	fetched.Name = "Mallory"
+	if again, err := s.GetUser("user1"); err != nil || again.Name != "Alice" {
+		t.Errorf("editing a fetched user renamed the stored one: %+v, %v", again, err)
+	}
+}
+
+func testSessions(t *testing.T, s *suite) {
+	if err := s.CreateUser(&database.User{ID: "user1", Name: "Alice"}); err != nil {
+		t.Fatal(err)
+	}
+	revokedAt := time.Now()
+	session := &database.Session{ID: "session1", UserID: "user1", RefreshHash: "hash1", ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}
+	if err := s.CreateSession(session); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateSession(&database.Session{ID: "session1", UserID: "user1", RefreshHash: "other", ExpiresAt: time.Now().Add(time.Hour)}); err == nil {
+		t.Error("a session ID was used twice")
+	}
+	stored, err := s.GetSession("session1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.UserID != "user1" || stored.RefreshHash != "hash1" || !stored.IsActive(time.Now()) {
+		t.Errorf("stored session is %+v, want an active session for user1", stored)
+	}
+
+	expiresAt := time.Now().Add(2 * time.Hour)
+	rotated, err := s.RotateSession("session1", "hash1", "hash2", expiresAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated.RefreshHash != "hash2" || !rotated.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("rotated session is %+v, want hash2 expiring at %v", rotated, expiresAt)
+	}
+	// A refresh token works once
+	if _, err := s.RotateSession("session1", "hash1", "hash3", expiresAt); err == nil {
+		t.Error("a traded refresh token rotated the session again")
+	}
+
+	if err := s.CreateSession(&database.Session{ID: "session2", UserID: "user1", RefreshHash: "hash1", ExpiresAt: time.Now().Add(-time.Second)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RotateSession("session2", "hash1", "hash2", expiresAt); err == nil {
+		t.Error("an expired session was rotated")
+	}
+
+	if err := s.RevokeSession("session1"); err != nil {
+		t.Fatal(err)
+	}
+	revoked, err := s.GetSession("session1")
+	if err != nil || revoked.RevokedAt == nil || revoked.IsActive(time.Now()) {
+		t.Fatalf("revoked session is %+v, %v", revoked, err)
+	}
+	if err := s.RevokeSession("session1"); err != nil {
+		t.Fatal(err)
+	}
+	if again, err := s.GetSession("session1"); err != nil || !again.RevokedAt.Equal(*revoked.RevokedAt) {
+		t.Errorf("revoking twice moved the revocation from %v to %+v, %v", revoked.RevokedAt, again, err)
+	}
+	if _, err := s.RotateSession("session1", "hash2", "hash3", expiresAt); err == nil {
+		t.Error("a revoked session was rotated")
+	}
+
+	if _, err := s.GetSession("missing"); err == nil {
+		t.Error("got a missing session")
+	}
+	if _, err := s.RotateSession("missing", "hash1", "hash2", expiresAt); err == nil {
+		t.Error("rotated a missing session")
+	}
+	if err := s.RevokeSession("missing"); err == nil {
+		t.Error("revoked a missing session")
+	}
+}
+
+func testCredentials(t *testing.T, s *suite) {
+	for _, user := range []*database.User{{ID: "user1", Name: "Alice", Email: "Alice@Example.com"}, {ID: "user2", Name: "Bob"}} {
+		if err := s.CreateUser(user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.CreateCredential(&database.Credential{UserID: "user1", LoginName: "alice", PasswordHash: "hash1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateCredential(&database.Credential{UserID: "user1", LoginName: "alice2", PasswordHash: "hash1"}); err == nil {
+		t.Error("a user got a second credential")
+	}
+	if err := s.CreateCredential(&database.Credential{UserID: "user2", LoginName: "alice", PasswordHash: "hash2"}); err == nil || err.Error() != database.ErrLoginNameTaken {
+		t.Errorf("taking another user's login name returned %v, want %s", err, database.ErrLoginNameTaken)
+	}
+	if err := s.CreateCredential(&database.Credential{UserID: "missing", LoginName: "eve", PasswordHash: "hash"}); err == nil {
+		t.Error("created a credential for a missing user")
+	}
+	if err := s.CreateCredential(&database.Credential{UserID: "user2", LoginName: "bob", PasswordHash: "hash2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	lookups := []struct {
+		login    string
+		wantUser string // Empty when no credential matches
+	}{
+		{"alice", "user1"},
+		{"alice@example.com", "user1"},
+		{"ALICE@EXAMPLE.COM", "user1"},
+		{"bob", "user2"},
+		{"nobody", ""},
+		{"", ""},
+	}
+	for _, lookup := range lookups {
+		credential, err := s.GetCredentialByLogin(lookup.login)
+		switch {
+		case lookup.wantUser == "" && (err == nil || err.Error() != database.ErrCredentialNotFound):
+			t.Errorf("signing in as %q returned %+v, %v, want %s", lookup.login, credential, err, database.ErrCredentialNotFound)
+		case lookup.wantUser != "" && (err != nil || credential.UserID != lookup.wantUser):
+			t.Errorf("signing in as %q returned %+v, %v, want %s", lookup.login, credential, err, lookup.wantUser)
+		}
+	}
+
+	renamed, err := s.RenameLogin("user1", "alicia")
+	if err != nil || renamed.LoginName != "alicia" {
+		t.Fatalf("rename returned %+v, %v", renamed, err)
+	}
+	if _, err := s.GetCredentialByLogin("alice"); err == nil {
+		t.Error("the old login name still signs in")
+	}
+	if credential, err := s.GetCredentialByLogin("alicia"); err != nil || credential.UserID != "user1" {
+		t.Errorf("the new login name signs in as %+v, %v", credential, err)
+	}
+	if same, err := s.RenameLogin("user1", "alicia"); err != nil || same.LoginName != "alicia" {
+		t.Errorf("renaming to the same name returned %+v, %v", same, err)
+	}
+	if _, err := s.RenameLogin("user2", "alicia"); err == nil || err.Error() != database.ErrLoginNameTaken {
+		t.Errorf("renaming to a taken name returned %v, want %s", err, database.ErrLoginNameTaken)
+	}
+
+	if err := s.SetCredentialPassword("user1", "hash3"); err != nil {
+		t.Fatal(err)
+	}
+	if credential, err := s.GetCredential("user1"); err != nil || credential.PasswordHash != "hash3" || credential.LoginName != "alicia" {
+		t.Errorf("after a password change the credential is %+v, %v", credential, err)
+	}
+
+	if _, err := s.GetCredential("missing"); err == nil || err.Error() != database.ErrCredentialNotFound {
+		t.Errorf("getting a missing credential returned %v, want %s", err, database.ErrCredentialNotFound)
+	}
+	if _, err := s.RenameLogin("missing", "eve"); err == nil || err.Error() != database.ErrCredentialNotFound {
+		t.Errorf("renaming a missing credential returned %v, want %s", err, database.ErrCredentialNotFound)
+	}
+	if err := s.SetCredentialPassword("missing", "hash"); err == nil || err.Error() != database.ErrCredentialNotFound {
+		t.Errorf("setting the password of a missing credential returned %v, want %s", err, database.ErrCredentialNotFound)
+	}
+}
@@ -0,0 +1,39 @@
+package repositorytest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kasasunil/chat_app/database"
+)
+
+// SeedInbox stores perConversation one-to-one messages from each of peer0, peer1, ...
+// up to conversations to userID, who has read the first readPerConversation messages
+// of every chat. It returns the message IDs in the order they were sent.
+// Benchmarks use it to fill a store before timing reads of it.
+func SeedInbox(tb testing.TB, store database.Repository, userID string, conversations, perConversation, readPerConversation int) []string {
+	tb.Helper()
+	ids := make([]string, 0, conversations*perConversation)
+	for c := 0; c < conversations; c++ {
+		peerID := fmt.Sprintf("peer%d", c)
+		for i := 0; i < perConversation; i++ {
+			message := &database.Message{
+				ID:               fmt.Sprintf("%s-m%d", peerID, i),
+				SenderID:         peerID,
+				DestinationID:    userID,
+				MessageText:      fmt.Sprintf("message %d", i),
+				ConversationType: database.ConversationTypeOneToOne,
+			}
+			if err := store.CreateMessage(message); err != nil {
+				tb.Fatal(err)
+			}
+			if i < readPerConversation {
+				if err := store.CreateMessageRead(message.ID, userID); err != nil {
+					tb.Fatal(err)
+				}
+			}
+			ids = append(ids, message.ID)
+		}
+	}
+	return ids
+}
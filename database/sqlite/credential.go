@@ -0,0 +1,116 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+)
+
+const credentialColumns = `user_id, login_name, password_hash, created_at, updated_at`
+
+func scanCredential(row rowScanner) (*database.Credential, error) {
+	var credential database.Credential
+	var createdAt, updatedAt int64
+	if err := row.Scan(
+		&credential.UserID, &credential.LoginName, &credential.PasswordHash, &createdAt, &updatedAt,
+	); err != nil {
+		return nil, err
+	}
+	credential.CreatedAt = fromUnix(createdAt)
+	credential.UpdatedAt = fromUnix(updatedAt)
+	return &credential, nil
+}
+
+// Credential operations
+func (s *SQLiteStore) CreateCredential(credential *database.Credential) error {
+	if _, err := s.GetUser(credential.UserID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	result, err := s.db.Exec(
+		`INSERT OR IGNORE INTO credentials (`+credentialColumns+`) VALUES (?, ?, ?, ?, ?)`,
+		credential.UserID, credential.LoginName, credential.PasswordHash, toUnix(now), toUnix(now),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create credential: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		if _, err := s.GetCredential(credential.UserID); err == nil {
+			return fmt.Errorf("credential already exists")
+		}
+		return fmt.Errorf(database.ErrLoginNameTaken)
+	}
+
+	credential.CreatedAt = now
+	credential.UpdatedAt = now
+	return nil
+}
+
+func (s *SQLiteStore) GetCredential(userID string) (*database.Credential, error) {
+	return s.queryCredential(`SELECT `+credentialColumns+` FROM credentials WHERE user_id = ?`, userID)
+}
+
+// GetCredentialByLogin finds the credential signed in with login: a login name,
+// or otherwise the email of the user the credential belongs to (in any case)
+func (s *SQLiteStore) GetCredentialByLogin(login string) (*database.Credential, error) {
+	credential, err := s.queryCredential(`SELECT `+credentialColumns+` FROM credentials WHERE login_name = ?`, login)
+	if err == nil || err.Error() != database.ErrCredentialNotFound {
+		return credential, err
+	}
+	if login == "" {
+		return nil, fmt.Errorf(database.ErrCredentialNotFound) // Users without an email have an empty one
+	}
+	return s.queryCredential(
+		`SELECT c.user_id, c.login_name, c.password_hash, c.created_at, c.updated_at
+		 FROM users u JOIN credentials c ON c.user_id = u.id
+		 WHERE u.email = ? COLLATE NOCASE ORDER BY u.created_at, u.rowid LIMIT 1`, login,
+	)
+}
+
+func (s *SQLiteStore) queryCredential(query string, args ...interface{}) (*database.Credential, error) {
+	credential, err := scanCredential(s.db.QueryRow(query, args...))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf(database.ErrCredentialNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credential: %w", err)
+	}
+	return credential, nil
+}
+
+func (s *SQLiteStore) SetCredentialPassword(userID, passwordHash string) error {
+	result, err := s.db.Exec(
+		`UPDATE credentials SET password_hash = ?, updated_at = ? WHERE user_id = ?`,
+		passwordHash, toUnix(time.Now()), userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set password: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf(database.ErrCredentialNotFound)
+	}
+	return nil
+}
+
+// RenameLogin changes a user's login name. The unique index on login_name decides
+// between concurrent renames, so two users cannot take the same name at once.
+func (s *SQLiteStore) RenameLogin(userID, loginName string) (*database.Credential, error) {
+	result, err := s.db.Exec(
+		`UPDATE OR IGNORE credentials SET login_name = ?, updated_at = ? WHERE user_id = ? AND login_name != ?`,
+		loginName, toUnix(time.Now()), userID, loginName,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename login: %w", err)
+	}
+	credential, err := s.GetCredential(userID)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 && credential.LoginName != loginName {
+		return nil, fmt.Errorf(database.ErrLoginNameTaken)
+	}
+	return credential, nil
+}
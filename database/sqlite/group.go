@@ -0,0 +1,301 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/pkg/utils"
+)
+
+// Group operations
+func (s *SQLiteStore) CreateGroup(group *database.Group) error {
+	return insertGroup(s.db, group)
+}
+
+// CreateGroupWithMembers creates a group together with its first members, all or nothing.
+// Members' group IDs and join times are set by the store.
+func (s *SQLiteStore) CreateGroupWithMembers(group *database.Group, members []*database.GroupMember) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertGroup(tx, group); err != nil {
+		return err
+	}
+	for _, member := range members {
+		if _, err := tx.Exec(
+			`INSERT OR IGNORE INTO group_members (group_id, user_id, role, joined_at) VALUES (?, ?, ?, ?)`,
+			group.ID, member.UserID, member.Role, toUnix(group.CreatedAt),
+		); err != nil {
+			return fmt.Errorf("failed to add group member: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit group: %w", err)
+	}
+
+	for _, member := range members {
+		member.GroupID = group.ID
+		member.JoinedAt = group.CreatedAt
+	}
+	return nil
+}
+
+// insertGroup stores a new group with no members
+func insertGroup(db execer, group *database.Group) error {
+	now := time.Now()
+	if group.HistoryVisibility == "" {
+		group.HistoryVisibility = database.HistoryVisibilityFull
+	}
+	result, err := db.Exec(
+		`INSERT OR IGNORE INTO groups (id, name, description, created_by, created_at, updated_at, announcement_only, history_visibility)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		group.ID, group.Name, group.Description, group.CreatedBy, toUnix(now), toUnix(now), group.AnnouncementOnly, group.HistoryVisibility,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create group: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("group already exists")
+	}
+
+	group.CreatedAt = now
+	group.UpdatedAt = now
+	return nil
+}
+
+func (s *SQLiteStore) GetGroup(groupID string) (*database.Group, error) {
+	var group database.Group
+	var createdAt, updatedAt int64
+	err := s.db.QueryRow(
+		`SELECT id, name, description, created_by, created_at, updated_at, announcement_only, history_visibility FROM groups WHERE id = ?`, groupID,
+	).Scan(&group.ID, &group.Name, &group.Description, &group.CreatedBy, &createdAt, &updatedAt, &group.AnnouncementOnly, &group.HistoryVisibility)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("group not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+
+	group.CreatedAt = fromUnix(createdAt)
+	group.UpdatedAt = fromUnix(updatedAt)
+	return &group, nil
+}
+
+// UpdateGroup changes only the fields set in update and returns the group as saved.
+// Unset fields keep their stored value in the same statement, so concurrent updates
+// to different fields do not undo each other.
+func (s *SQLiteStore) UpdateGroup(groupID string, update database.GroupUpdate) (*database.Group, error) {
+	result, err := s.db.Exec(
+		`UPDATE groups SET
+		   name = COALESCE(?, name),
+		   description = COALESCE(?, description),
+		   announcement_only = COALESCE(?, announcement_only),
+		   history_visibility = COALESCE(?, history_visibility),
+		   updated_at = ?
+		 WHERE id = ?`,
+		update.Name, update.Description, update.AnnouncementOnly, update.HistoryVisibility, toUnix(time.Now()), groupID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update group: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, fmt.Errorf("group not found")
+	}
+	return s.GetGroup(groupID)
+}
+
+// AddGroupMember adds a user to a group with the given role.
+// Adding an existing member keeps their current role.
+func (s *SQLiteStore) AddGroupMember(groupID, userID string, role database.GroupRole) error {
+	if _, err := s.GetGroup(groupID); err != nil {
+		return err
+	}
+
+	if _, err := s.db.Exec(
+		`INSERT OR IGNORE INTO group_members (group_id, user_id, role, joined_at) VALUES (?, ?, ?, ?)`,
+		groupID, userID, role, toUnix(time.Now()),
+	); err != nil {
+		return fmt.Errorf("failed to add group member: %w", err)
+	}
+	return nil
+}
+
+// AddGroupMembers adds the users in userIDs who are not yet members to a group with
+// the given role and returns them, in request order. The count and the adds share
+// one transaction, all or nothing, so concurrent adds cannot push the group past maxMembers.
+func (s *SQLiteStore) AddGroupMembers(groupID string, userIDs []string, role database.GroupRole, maxMembers int) ([]string, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow(`SELECT 1 FROM groups WHERE id = ?`, groupID).Scan(&exists); err == sql.ErrNoRows {
+		return nil, fmt.Errorf(database.ErrGroupNotFound)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+	memberIDs, err := groupMemberIDs(tx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	added := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if !utils.Contains(memberIDs, userID) && !utils.Contains(added, userID) {
+			added = append(added, userID)
+		}
+	}
+	if len(memberIDs)+len(added) > maxMembers {
+		return nil, fmt.Errorf(database.ErrGroupFull)
+	}
+
+	now := toUnix(time.Now())
+	for _, userID := range added {
+		if _, err := tx.Exec(
+			`INSERT INTO group_members (group_id, user_id, role, joined_at) VALUES (?, ?, ?, ?)`,
+			groupID, userID, role, now,
+		); err != nil {
+			return nil, fmt.Errorf("failed to add group member: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit group members: %w", err)
+	}
+	return added, nil
+}
+
+// RemoveGroupMember removes a user from a group. The group drops out of the
+// user's conversation list, as they can no longer read it.
+func (s *SQLiteStore) RemoveGroupMember(groupID, userID string) error {
+	if _, err := s.GetGroup(groupID); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		`DELETE FROM group_members WHERE group_id = ? AND user_id = ?`, groupID, userID,
+	); err != nil {
+		return fmt.Errorf("failed to remove group member: %w", err)
+	}
+	if _, err := tx.Exec(
+		`DELETE FROM user_conversations WHERE user_id = ? AND conversation_id = ?`, userID, groupID,
+	); err != nil {
+		return fmt.Errorf("failed to remove user conversation: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) IsGroupMember(groupID, userID string) bool {
+	var exists int
+	err := s.db.QueryRow(
+		`SELECT 1 FROM group_members WHERE group_id = ? AND user_id = ?`, groupID, userID,
+	).Scan(&exists)
+	return err == nil
+}
+
+func (s *SQLiteStore) GetGroupMember(groupID, userID string) (*database.GroupMember, error) {
+	var member database.GroupMember
+	var joinedAt int64
+	err := s.db.QueryRow(
+		`SELECT group_id, user_id, role, joined_at FROM group_members WHERE group_id = ? AND user_id = ?`, groupID, userID,
+	).Scan(&member.GroupID, &member.UserID, &member.Role, &joinedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf(database.ErrGroupMemberNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group member: %w", err)
+	}
+	member.JoinedAt = fromUnix(joinedAt)
+	return &member, nil
+}
+
+func (s *SQLiteStore) SetGroupMemberRole(groupID, userID string, role database.GroupRole) error {
+	result, err := s.db.Exec(
+		`UPDATE group_members SET role = ? WHERE group_id = ? AND user_id = ?`, role, groupID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set group member role: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf(database.ErrGroupMemberNotFound)
+	}
+	return nil
+}
+
+// TransferGroupOwnership makes newOwnerID the owner of a group and ownerID an admin,
+// provided ownerID still owns it
+func (s *SQLiteStore) TransferGroupOwnership(groupID, ownerID, newOwnerID string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var ownerRole database.GroupRole
+	err = tx.QueryRow(
+		`SELECT role FROM group_members WHERE group_id = ? AND user_id = ?`, groupID, ownerID,
+	).Scan(&ownerRole)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf(database.ErrGroupMemberNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get group member: %w", err)
+	}
+	if ownerRole != database.GroupRoleOwner {
+		return fmt.Errorf(database.ErrNotGroupOwner)
+	}
+
+	if _, err := tx.Exec(
+		`UPDATE group_members SET role = ? WHERE group_id = ? AND user_id = ?`, database.GroupRoleAdmin, groupID, ownerID,
+	); err != nil {
+		return fmt.Errorf("failed to set group member role: %w", err)
+	}
+	result, err := tx.Exec(
+		`UPDATE group_members SET role = ? WHERE group_id = ? AND user_id = ?`, database.GroupRoleOwner, groupID, newOwnerID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set group member role: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf(database.ErrGroupMemberNotFound)
+	}
+	return tx.Commit()
+}
+
+// ListGroupMembers returns a group's members ordered by user ID
+func (s *SQLiteStore) ListGroupMembers(groupID string) ([]*database.GroupMember, error) {
+	if _, err := s.GetGroup(groupID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(
+		`SELECT group_id, user_id, role, joined_at FROM group_members WHERE group_id = ? ORDER BY user_id`, groupID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list group members: %w", err)
+	}
+	defer rows.Close()
+
+	members := make([]*database.GroupMember, 0)
+	for rows.Next() {
+		var member database.GroupMember
+		var joinedAt int64
+		if err := rows.Scan(&member.GroupID, &member.UserID, &member.Role, &joinedAt); err != nil {
+			return nil, fmt.Errorf("failed to list group members: %w", err)
+		}
+		member.JoinedAt = fromUnix(joinedAt)
+		members = append(members, &member)
+	}
+	return members, rows.Err()
+}
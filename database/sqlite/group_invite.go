@@ -0,0 +1,163 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+)
+
+const groupInviteColumns = `id, group_id, created_by, expires_at, max_uses, use_count, revoked_at, created_at`
+
+func scanGroupInvite(row rowScanner) (*database.GroupInvite, error) {
+	var invite database.GroupInvite
+	var expiresAt, revokedAt sql.NullInt64
+	var createdAt int64
+	if err := row.Scan(
+		&invite.ID, &invite.GroupID, &invite.CreatedBy, &expiresAt,
+		&invite.MaxUses, &invite.UseCount, &revokedAt, &createdAt,
+	); err != nil {
+		return nil, err
+	}
+	invite.ExpiresAt = fromNullableUnix(expiresAt)
+	invite.RevokedAt = fromNullableUnix(revokedAt)
+	invite.CreatedAt = fromUnix(createdAt)
+	return &invite, nil
+}
+
+// Group invite operations
+func (s *SQLiteStore) CreateGroupInvite(invite *database.GroupInvite) error {
+	if _, err := s.GetGroup(invite.GroupID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	result, err := s.db.Exec(
+		`INSERT OR IGNORE INTO group_invites (`+groupInviteColumns+`) VALUES (?, ?, ?, ?, ?, 0, NULL, ?)`,
+		invite.ID, invite.GroupID, invite.CreatedBy, toNullableUnix(invite.ExpiresAt), invite.MaxUses, toUnix(now),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create invite: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("invite already exists")
+	}
+
+	invite.UseCount = 0
+	invite.RevokedAt = nil
+	invite.CreatedAt = now
+	return nil
+}
+
+func (s *SQLiteStore) GetGroupInvite(inviteID string) (*database.GroupInvite, error) {
+	invite, err := scanGroupInvite(s.db.QueryRow(`SELECT `+groupInviteColumns+` FROM group_invites WHERE id = ?`, inviteID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("invite not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invite: %w", err)
+	}
+	return invite, nil
+}
+
+// ListGroupInvites returns every invite of a group, newest first, including
+// revoked, expired and used up ones
+func (s *SQLiteStore) ListGroupInvites(groupID string) ([]*database.GroupInvite, error) {
+	if _, err := s.GetGroup(groupID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(
+		`SELECT `+groupInviteColumns+` FROM group_invites WHERE group_id = ? ORDER BY created_at DESC`, groupID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invites: %w", err)
+	}
+	defer rows.Close()
+
+	invites := make([]*database.GroupInvite, 0)
+	for rows.Next() {
+		invite, err := scanGroupInvite(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list invites: %w", err)
+		}
+		invites = append(invites, invite)
+	}
+	return invites, rows.Err()
+}
+
+// RevokeGroupInvite stops an invite from being used. Revoking twice keeps the first time.
+func (s *SQLiteStore) RevokeGroupInvite(inviteID string) error {
+	if _, err := s.GetGroupInvite(inviteID); err != nil {
+		return err
+	}
+
+	if _, err := s.db.Exec(
+		`UPDATE group_invites SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`, toUnix(time.Now()), inviteID,
+	); err != nil {
+		return fmt.Errorf("failed to revoke invite: %w", err)
+	}
+	return nil
+}
+
+// JoinGroupWithInvite adds userID to the invite's group as a member and counts one
+// use of the invite. The checks, the count and the add share one transaction, so
+// concurrent joins cannot use an invite more often than it allows, push the group
+// past maxMembers or add the same user twice.
+func (s *SQLiteStore) JoinGroupWithInvite(inviteID, userID string, maxMembers int) (*database.GroupInvite, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	invite, err := scanGroupInvite(tx.QueryRow(`SELECT `+groupInviteColumns+` FROM group_invites WHERE id = ?`, inviteID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf(database.ErrInviteNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invite: %w", err)
+	}
+
+	var memberCount int
+	var isMember bool
+	if err := tx.QueryRow(
+		`SELECT COUNT(*), COALESCE(MAX(user_id = ?), 0) FROM group_members WHERE group_id = ?`, userID, invite.GroupID,
+	).Scan(&memberCount, &isMember); err != nil {
+		return nil, fmt.Errorf("failed to count group members: %w", err)
+	}
+	if isMember {
+		return nil, fmt.Errorf(database.ErrAlreadyGroupMember)
+	}
+
+	now := time.Now()
+	switch {
+	case invite.RevokedAt != nil:
+		return nil, fmt.Errorf(database.ErrInviteRevoked)
+	case invite.IsExpired(now):
+		return nil, fmt.Errorf(database.ErrInviteExpired)
+	case invite.IsExhausted():
+		return nil, fmt.Errorf(database.ErrInviteExhausted)
+	case memberCount >= maxMembers:
+		return nil, fmt.Errorf(database.ErrGroupFull)
+	}
+
+	if _, err := tx.Exec(
+		`UPDATE group_invites SET use_count = use_count + 1 WHERE id = ?`, inviteID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to use invite: %w", err)
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO group_members (group_id, user_id, role, joined_at) VALUES (?, ?, ?, ?)`,
+		invite.GroupID, userID, database.GroupRoleMember, toUnix(now),
+	); err != nil {
+		return nil, fmt.Errorf("failed to add group member: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit invite join: %w", err)
+	}
+
+	invite.UseCount++
+	return invite, nil
+}
@@ -0,0 +1,582 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+)
+
+const messageColumns = `id, conversation_id, sender_id, destination_id, message_text, status, conversation_type, created_at, updated_at, edited_at, deleted_at,
+	reply_to_message_id, thread_root_id, reply_count, last_reply_at, kind, system_type, system_payload`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanMessage(row rowScanner) (*database.Message, error) {
+	var msg database.Message
+	var createdAt, updatedAt int64
+	var editedAt, deletedAt, lastReplyAt sql.NullInt64
+	var systemPayload sql.NullString
+	if err := row.Scan(
+		&msg.ID, &msg.ConversationID, &msg.SenderID, &msg.DestinationID, &msg.MessageText,
+		&msg.Status, &msg.ConversationType, &createdAt, &updatedAt, &editedAt, &deletedAt,
+		&msg.ReplyToMessageID, &msg.ThreadRootID, &msg.ReplyCount, &lastReplyAt, &msg.Kind,
+		&msg.SystemType, &systemPayload,
+	); err != nil {
+		return nil, err
+	}
+	if systemPayload.Valid {
+		msg.SystemPayload = &database.SystemPayload{}
+		if err := json.Unmarshal([]byte(systemPayload.String), msg.SystemPayload); err != nil {
+			return nil, fmt.Errorf("failed to decode system payload: %w", err)
+		}
+	}
+	msg.CreatedAt = fromUnix(createdAt)
+	msg.UpdatedAt = fromUnix(updatedAt)
+	msg.EditedAt = fromNullableUnix(editedAt)
+	msg.DeletedAt = fromNullableUnix(deletedAt)
+	msg.LastReplyAt = fromNullableUnix(lastReplyAt)
+	return &msg, nil
+}
+
+func scanMessages(rows *sql.Rows) ([]*database.Message, error) {
+	defer rows.Close()
+
+	messages := make([]*database.Message, 0)
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
+
+// Message operations
+func (s *SQLiteStore) CreateMessage(message *database.Message) error {
+	now := time.Now()
+	message.CreatedAt = now
+	message.UpdatedAt = now
+	message.Status = database.StatusSent
+	if message.Kind == "" {
+		message.Kind = database.MessageKindUser
+	}
+	// Both sides of a one-to-one chat share one canonical conversation key
+	message.ConversationID = database.ConversationIDForMessage(message)
+
+	// System payloads are stored as JSON
+	var systemPayload interface{}
+	if message.SystemPayload != nil {
+		encoded, err := json.Marshal(message.SystemPayload)
+		if err != nil {
+			return fmt.Errorf("failed to encode system payload: %w", err)
+		}
+		systemPayload = string(encoded)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		`INSERT INTO messages (`+messageColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		message.ID, message.ConversationID, message.SenderID, message.DestinationID, message.MessageText,
+		message.Status, message.ConversationType, toUnix(now), toUnix(now),
+		toNullableUnix(message.EditedAt), toNullableUnix(message.DeletedAt),
+		message.ReplyToMessageID, message.ThreadRootID, message.ReplyCount, toNullableUnix(message.LastReplyAt),
+		message.Kind, message.SystemType, systemPayload,
+	); err != nil {
+		return fmt.Errorf("failed to create message: %w", err)
+	}
+
+	// Keep the thread root's reply summary current
+	if message.ThreadRootID != "" {
+		if _, err := tx.Exec(
+			`UPDATE messages SET reply_count = reply_count + 1, last_reply_at = ? WHERE id = ?`,
+			toUnix(now), message.ThreadRootID,
+		); err != nil {
+			return fmt.Errorf("failed to update thread root: %w", err)
+		}
+	}
+
+	// Update user conversations for sender and recipient
+	if message.ConversationType == database.ConversationTypeOneToOne {
+		if err := updateUserConversation(tx, message.SenderID, message.ConversationID, message.DestinationID, message.ConversationType, unreadIncrement(message, message.SenderID), now); err != nil {
+			return err
+		}
+		if err := updateUserConversation(tx, message.DestinationID, message.ConversationID, message.SenderID, message.ConversationType, unreadIncrement(message, message.DestinationID), now); err != nil {
+			return err
+		}
+	} else {
+		// For group messages, update all group members (the sender is one of them;
+		// a system message about a member leaving must not bring the group back for them)
+		memberIDs, err := groupMemberIDs(tx, message.DestinationID)
+		if err != nil {
+			return err
+		}
+		for _, memberID := range memberIDs {
+			if err := updateUserConversation(tx, memberID, message.ConversationID, message.DestinationID, message.ConversationType, unreadIncrement(message, memberID), now); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+func updateUserConversation(tx *sql.Tx, userID, conversationID, destinationID string, convType database.ConversationType, unread int, now time.Time) error {
+	_, err := tx.Exec(
+		`INSERT INTO user_conversations (id, user_id, conversation_id, destination_id, conversation_type, unread_count, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT (user_id, conversation_id) DO UPDATE SET
+			updated_at = excluded.updated_at,
+			unread_count = unread_count + excluded.unread_count`,
+		fmt.Sprintf("uc_%s_%s", userID, destinationID), userID, conversationID, destinationID, convType, unread, toUnix(now), toUnix(now),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update user conversation: %w", err)
+	}
+	return nil
+}
+
+// unreadIncrement is 1 when message adds to userID's unread count, otherwise 0
+func unreadIncrement(message *database.Message, userID string) int {
+	if database.CountsAsUnread(message, userID) {
+		return 1
+	}
+	return 0
+}
+
+// isUnread reports whether msg is counted in userID's unread count: it counts as
+// unread, reached userID as a recipient, and was not read, passed by their read
+// watermark, or hidden by them since. Members only count messages sent after they joined.
+func isUnread(tx *sql.Tx, msg *database.Message, userID string) (bool, error) {
+	if !database.CountsAsUnread(msg, userID) {
+		return false, nil
+	}
+	if msg.ConversationType != database.ConversationTypeGroup && msg.DestinationID != userID {
+		return false, nil
+	}
+
+	var unread bool
+	err := tx.QueryRow(
+		`SELECT (? != 'group' OR EXISTS (SELECT 1 FROM group_members WHERE group_id = ? AND user_id = ? AND joined_at <= ?))
+		    AND NOT EXISTS (SELECT 1 FROM message_reads WHERE message_id = ? AND user_id = ?)
+		    AND NOT EXISTS (SELECT 1 FROM hidden_messages WHERE message_id = ? AND user_id = ?)
+		    AND NOT EXISTS (SELECT 1 FROM user_conversations WHERE user_id = ? AND conversation_id = ? AND read_up_to >= ?)`,
+		msg.ConversationType, msg.DestinationID, userID, toUnix(msg.CreatedAt),
+		msg.ID, userID,
+		msg.ID, userID,
+		userID, msg.ConversationID, toUnix(msg.CreatedAt),
+	).Scan(&unread)
+	if err != nil {
+		return false, fmt.Errorf("failed to check unread state: %w", err)
+	}
+	return unread, nil
+}
+
+// decrementUnread takes one message off userID's unread count for a conversation
+func decrementUnread(tx *sql.Tx, userID, conversationID string) error {
+	if _, err := tx.Exec(
+		`UPDATE user_conversations SET unread_count = unread_count - 1
+		 WHERE user_id = ? AND conversation_id = ? AND unread_count > 0`,
+		userID, conversationID,
+	); err != nil {
+		return fmt.Errorf("failed to update unread count: %w", err)
+	}
+	return nil
+}
+
+func groupMemberIDs(tx *sql.Tx, groupID string) ([]string, error) {
+	return queryMemberIDs(tx, `SELECT user_id FROM group_members WHERE group_id = ?`, groupID)
+}
+
+// groupMemberIDsJoinedBy returns the members of a group who had joined by at
+func groupMemberIDsJoinedBy(tx *sql.Tx, groupID string, at time.Time) ([]string, error) {
+	return queryMemberIDs(tx, `SELECT user_id FROM group_members WHERE group_id = ? AND joined_at <= ?`, groupID, toUnix(at))
+}
+
+func queryMemberIDs(tx *sql.Tx, query string, args ...interface{}) ([]string, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list group members: %w", err)
+	}
+	defer rows.Close()
+
+	memberIDs := make([]string, 0)
+	for rows.Next() {
+		var memberID string
+		if err := rows.Scan(&memberID); err != nil {
+			return nil, err
+		}
+		memberIDs = append(memberIDs, memberID)
+	}
+	return memberIDs, rows.Err()
+}
+
+// GetMessages returns a page of the conversation as seen by userID:
+// thread replies and messages the user deleted for themselves are left out.
+func (s *SQLiteStore) GetMessages(conversationID, userID string, query database.PageQuery) (*database.MessagePage, error) {
+	start, err := s.historyStart(conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.pageMessages(`conversation_id = ? AND thread_root_id = '' AND created_at >= ?`, []interface{}{conversationID, start}, conversationID, userID, query)
+}
+
+// GetThreadMessages returns a page of the replies in a thread, using the same
+// ordering and positions as GetMessages
+func (s *SQLiteStore) GetThreadMessages(rootMessageID, userID string, query database.PageQuery) (*database.MessagePage, error) {
+	root, err := s.GetMessage(rootMessageID)
+	if err != nil {
+		return nil, err
+	}
+	start, err := s.historyStart(root.ConversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.pageMessages(`thread_root_id = ? AND created_at >= ?`, []interface{}{rootMessageID, start}, root.ConversationID, userID, query)
+}
+
+// historyStart returns when userID's view of a conversation begins, in unix nanoseconds:
+// their join time in groups that only show history since joining, otherwise 0
+func (s *SQLiteStore) historyStart(conversationID, userID string) (int64, error) {
+	var joinedAt int64
+	err := s.db.QueryRow(
+		`SELECT gm.joined_at FROM group_members gm JOIN groups g ON g.id = gm.group_id
+		 WHERE gm.group_id = ? AND gm.user_id = ? AND g.history_visibility = ?`,
+		conversationID, userID, database.HistoryVisibilitySinceJoined,
+	).Scan(&joinedAt)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get history visibility: %w", err)
+	}
+	return joinedAt, nil
+}
+
+// pageMessages returns one page of the conversation's messages matching filter, newest first.
+// The position may be a message the user has since hidden or that the filter leaves out;
+// only its place in the conversation matters.
+func (s *SQLiteStore) pageMessages(filter string, filterArgs []interface{}, conversationID, userID string, query database.PageQuery) (*database.MessagePage, error) {
+	sqlQuery := `SELECT ` + messageColumns + ` FROM messages WHERE ` + filter + `
+	          AND id NOT IN (SELECT message_id FROM hidden_messages WHERE user_id = ?)`
+	args := append(append([]interface{}{}, filterArgs...), userID)
+	order := `DESC`
+	if query.Position != "" {
+		var positionSeq int64
+		err := s.db.QueryRow(
+			`SELECT seq FROM messages WHERE id = ? AND conversation_id = ?`, query.Position, conversationID,
+		).Scan(&positionSeq)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf(database.ErrInvalidCursor)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve cursor: %w", err)
+		}
+		if query.Direction == database.PageAfter {
+			sqlQuery += ` AND seq > ?`
+			order = `ASC`
+		} else {
+			sqlQuery += ` AND seq < ?`
+		}
+		args = append(args, positionSeq)
+	}
+	// Fetch one extra row to learn whether another page exists
+	sqlQuery += ` ORDER BY seq ` + order + ` LIMIT ?`
+	args = append(args, query.Limit+1)
+
+	rows, err := s.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messages: %w", err)
+	}
+	messages, err := scanMessages(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messages: %w", err)
+	}
+
+	hasMore := len(messages) > query.Limit
+	if hasMore {
+		messages = messages[:query.Limit]
+	}
+	page := &database.MessagePage{Messages: messages}
+	if order == `ASC` {
+		// Read oldest first; pages are always returned newest first
+		for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+			messages[i], messages[j] = messages[j], messages[i]
+		}
+		page.HasNewer, page.HasOlder = hasMore, true
+	} else {
+		page.HasOlder, page.HasNewer = hasMore, query.Position != ""
+	}
+	return page, nil
+}
+
+func (s *SQLiteStore) GetMessage(messageID string) (*database.Message, error) {
+	msg, err := scanMessage(s.db.QueryRow(`SELECT `+messageColumns+` FROM messages WHERE id = ?`, messageID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("message not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+	return msg, nil
+}
+
+// GetMessagesByID looks up several messages at once; unknown IDs are left out of the result
+func (s *SQLiteStore) GetMessagesByID(messageIDs []string) (map[string]*database.Message, error) {
+	result := make(map[string]*database.Message, len(messageIDs))
+
+	// Query in batches to stay under SQLite's bound parameter limit
+	for start := 0; start < len(messageIDs); start += maxBatchParams {
+		end := start + maxBatchParams
+		if end > len(messageIDs) {
+			end = len(messageIDs)
+		}
+		batch := messageIDs[start:end]
+
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(batch)), ", ")
+		args := make([]interface{}, len(batch))
+		for i, messageID := range batch {
+			args[i] = messageID
+		}
+		rows, err := s.db.Query(`SELECT `+messageColumns+` FROM messages WHERE id IN (`+placeholders+`)`, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get messages: %w", err)
+		}
+		messages, err := scanMessages(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get messages: %w", err)
+		}
+		for _, msg := range messages {
+			result[msg.ID] = msg
+		}
+	}
+	return result, nil
+}
+
+func (s *SQLiteStore) UpdateMessageStatus(messageID string, status database.MessageStatus) error {
+	result, err := s.db.Exec(
+		`UPDATE messages SET status = ?, updated_at = ? WHERE id = ?`, status, toUnix(time.Now()), messageID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update message status: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("message not found")
+	}
+	return nil
+}
+
+// UpdateMessage replaces a message's text, keeping the previous text as a revision.
+// Search matches on the stored text, so old text stops matching immediately.
+// Deleted and system messages are left alone.
+func (s *SQLiteStore) UpdateMessage(messageID, messageText string) (*database.Message, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	msg, err := scanMessage(tx.QueryRow(`SELECT `+messageColumns+` FROM messages WHERE id = ?`, messageID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("message not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+	if msg.Kind == database.MessageKindSystem {
+		return nil, fmt.Errorf(database.ErrSystemMessage)
+	}
+	if msg.DeletedAt != nil {
+		return nil, fmt.Errorf(database.ErrMessageDeleted)
+	}
+	if msg.MessageText == messageText {
+		return msg, nil
+	}
+
+	now := time.Now()
+	writtenAt := msg.CreatedAt
+	if msg.EditedAt != nil {
+		writtenAt = *msg.EditedAt
+	}
+
+	// Checked again by the update itself, in case the message was deleted since it was read
+	result, err := tx.Exec(
+		`UPDATE messages SET message_text = ?, edited_at = ?, updated_at = ?
+		 WHERE id = ? AND deleted_at IS NULL AND kind = ?`,
+		messageText, toUnix(now), toUnix(now), messageID, database.MessageKindUser,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update message: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, fmt.Errorf(database.ErrMessageDeleted)
+	}
+
+	var revisionCount int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM message_revisions WHERE message_id = ?`, messageID).Scan(&revisionCount); err != nil {
+		return nil, fmt.Errorf("failed to count revisions: %w", err)
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO message_revisions (id, message_id, message_text, created_at, replaced_at) VALUES (?, ?, ?, ?, ?)`,
+		fmt.Sprintf("rev_%s_%d", messageID, revisionCount+1), messageID, msg.MessageText, toUnix(writtenAt), toUnix(now),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create revision: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to update message: %w", err)
+	}
+
+	msg.MessageText = messageText
+	msg.EditedAt = &now
+	msg.UpdatedAt = now
+	return msg, nil
+}
+
+func (s *SQLiteStore) GetMessageRevisions(messageID string) ([]*database.MessageRevision, error) {
+	if _, err := s.GetMessage(messageID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(
+		`SELECT id, message_id, message_text, created_at, replaced_at
+		 FROM message_revisions WHERE message_id = ? ORDER BY replaced_at`, messageID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := make([]*database.MessageRevision, 0)
+	for rows.Next() {
+		var rev database.MessageRevision
+		var createdAt, replacedAt int64
+		if err := rows.Scan(&rev.ID, &rev.MessageID, &rev.MessageText, &createdAt, &replacedAt); err != nil {
+			return nil, fmt.Errorf("failed to get revisions: %w", err)
+		}
+		rev.CreatedAt = fromUnix(createdAt)
+		rev.ReplacedAt = fromUnix(replacedAt)
+		revisions = append(revisions, &rev)
+	}
+	return revisions, rows.Err()
+}
+
+// DeleteMessageForEveryone turns the message into a tombstone: the text, its
+// revisions and its reactions are discarded and deleted_at is set. Deleting twice is a no-op.
+func (s *SQLiteStore) DeleteMessageForEveryone(messageID string) (*database.Message, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	msg, err := scanMessage(tx.QueryRow(`SELECT `+messageColumns+` FROM messages WHERE id = ?`, messageID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("message not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+	if msg.DeletedAt != nil {
+		return msg, nil
+	}
+
+	// A tombstone no longer counts as unread for recipients who had not read it
+	recipients := []string{msg.DestinationID}
+	if msg.ConversationType == database.ConversationTypeGroup {
+		if recipients, err = groupMemberIDs(tx, msg.DestinationID); err != nil {
+			return nil, err
+		}
+	}
+	for _, userID := range recipients {
+		unread, err := isUnread(tx, msg, userID)
+		if err != nil {
+			return nil, err
+		}
+		if unread {
+			if err := decrementUnread(tx, userID, msg.ConversationID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	now := time.Now()
+	if _, err := tx.Exec(
+		`UPDATE messages SET message_text = '', deleted_at = ?, updated_at = ? WHERE id = ?`,
+		toUnix(now), toUnix(now), messageID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to delete message: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM message_revisions WHERE message_id = ?`, messageID); err != nil {
+		return nil, fmt.Errorf("failed to delete revisions: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM reactions WHERE message_id = ?`, messageID); err != nil {
+		return nil, fmt.Errorf("failed to delete reactions: %w", err)
+	}
+	// A deleted reply no longer counts toward its thread root's reply summary
+	if msg.ThreadRootID != "" {
+		if _, err := tx.Exec(
+			`UPDATE messages SET
+			   reply_count = MAX(reply_count - 1, 0),
+			   last_reply_at = (SELECT MAX(created_at) FROM messages WHERE thread_root_id = ? AND deleted_at IS NULL)
+			 WHERE id = ?`,
+			msg.ThreadRootID, msg.ThreadRootID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to update thread root: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to delete message: %w", err)
+	}
+
+	msg.MessageText = ""
+	msg.DeletedAt = &now
+	msg.UpdatedAt = now
+	return msg, nil
+}
+
+// DeleteMessageForUser hides the message from userID's history, unread count and search
+func (s *SQLiteStore) DeleteMessageForUser(messageID, userID string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	msg, err := scanMessage(tx.QueryRow(`SELECT `+messageColumns+` FROM messages WHERE id = ?`, messageID))
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("message not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get message: %w", err)
+	}
+
+	unread, err := isUnread(tx, msg, userID)
+	if err != nil {
+		return err
+	}
+	if unread {
+		if err := decrementUnread(tx, userID, msg.ConversationID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(
+		`INSERT OR IGNORE INTO hidden_messages (user_id, message_id, created_at) VALUES (?, ?, ?)`,
+		userID, messageID, toUnix(time.Now()),
+	); err != nil {
+		return fmt.Errorf("failed to hide message: %w", err)
+	}
+	return tx.Commit()
+}
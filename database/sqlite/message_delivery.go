@@ -0,0 +1,158 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+)
+
+// MessageDelivery operations
+func (s *SQLiteStore) CreateMessageDelivery(messageID, userID string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	found, err := deliverMessage(tx, messageID, userID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("message not found")
+	}
+	return tx.Commit()
+}
+
+// CreateMessageDeliveries marks several messages delivered to userID in a single transaction.
+// Unknown message IDs are skipped.
+func (s *SQLiteStore) CreateMessageDeliveries(messageIDs []string, userID string) error {
+	now := time.Now()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, messageID := range messageIDs {
+		if _, err := deliverMessage(tx, messageID, userID, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// deliverMessage records that userID received a message, returning false if the message does not exist
+func deliverMessage(tx *sql.Tx, messageID, userID string, now time.Time) (bool, error) {
+	msg, err := scanMessage(tx.QueryRow(`SELECT `+messageColumns+` FROM messages WHERE id = ?`, messageID))
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	inserted, err := recordDelivery(tx, messageID, userID, now)
+	if err != nil {
+		return false, err
+	}
+	if inserted {
+		// Update message status (DELIVERED once every recipient has it)
+		if err := refreshMessageStatus(tx, msg); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// recordDelivery stores a delivery receipt, returning false if it already existed
+func recordDelivery(tx *sql.Tx, messageID, userID string, now time.Time) (bool, error) {
+	result, err := tx.Exec(
+		`INSERT OR IGNORE INTO message_deliveries (id, message_id, user_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		fmt.Sprintf("md_%s_%s", messageID, userID), messageID, userID, toUnix(now), toUnix(now),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to create message delivery: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// refreshMessageStatus recomputes the sender-visible status from per-recipient
+// delivery and read records and read watermarks. Group members who joined after
+// the message was sent are not among its recipients.
+func refreshMessageStatus(tx *sql.Tx, msg *database.Message) error {
+	members := make([]string, 0)
+	if msg.ConversationType == database.ConversationTypeGroup {
+		var err error
+		if members, err = groupMemberIDsJoinedBy(tx, msg.DestinationID, msg.CreatedAt); err != nil {
+			return err
+		}
+	}
+	recipients := database.MessageRecipients(msg, members)
+
+	delivered, err := receiptUserIDs(tx, `SELECT user_id FROM message_deliveries WHERE message_id = ?`, msg.ID)
+	if err != nil {
+		return err
+	}
+	read, err := receiptUserIDs(tx, `SELECT user_id FROM message_reads WHERE message_id = ? UNION SELECT uc.user_id`+watermarkReaders, msg.ID, msg.ID)
+	if err != nil {
+		return err
+	}
+
+	status := database.AggregateMessageStatus(msg.Status, recipients, delivered, read)
+	if status == msg.Status {
+		return nil
+	}
+	if _, err := tx.Exec(
+		`UPDATE messages SET status = ?, updated_at = ? WHERE id = ?`, status, toUnix(time.Now()), msg.ID,
+	); err != nil {
+		return fmt.Errorf("failed to update message status: %w", err)
+	}
+	return nil
+}
+
+func receiptUserIDs(tx *sql.Tx, query string, args ...interface{}) (map[string]bool, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load receipts: %w", err)
+	}
+	defer rows.Close()
+
+	userIDs := make(map[string]bool)
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs[userID] = true
+	}
+	return userIDs, rows.Err()
+}
+
+func (s *SQLiteStore) GetMessageDeliveries(messageID string) []*database.MessageDelivery {
+	result := make([]*database.MessageDelivery, 0)
+
+	rows, err := s.db.Query(
+		`SELECT id, message_id, user_id, created_at, updated_at FROM message_deliveries WHERE message_id = ?`, messageID,
+	)
+	if err != nil {
+		return result
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var md database.MessageDelivery
+		var createdAt, updatedAt int64
+		if err := rows.Scan(&md.ID, &md.MessageID, &md.UserID, &createdAt, &updatedAt); err != nil {
+			return result
+		}
+		md.CreatedAt = fromUnix(createdAt)
+		md.UpdatedAt = fromUnix(updatedAt)
+		result = append(result, &md)
+	}
+	return result
+}
@@ -0,0 +1,287 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+)
+
+// MessageRead operations
+func (s *SQLiteStore) CreateMessageRead(messageID, userID string) error {
+	return s.CreateMessageReads([]string{messageID}, userID)
+}
+
+// CreateMessageReads marks several messages read by userID in a single transaction
+func (s *SQLiteStore) CreateMessageReads(messageIDs []string, userID string) error {
+	now := time.Now()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, messageID := range messageIDs {
+		if err := readMessage(tx, messageID, userID, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// readMessage records that userID read a message
+func readMessage(tx *sql.Tx, messageID, userID string, now time.Time) error {
+	// Checked before the read is recorded, which takes the message off the count
+	msg, err := scanMessage(tx.QueryRow(`SELECT `+messageColumns+` FROM messages WHERE id = ?`, messageID))
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get message: %w", err)
+	}
+	unread := false
+	if msg != nil {
+		if unread, err = isUnread(tx, msg, userID); err != nil {
+			return err
+		}
+	}
+
+	recorded, err := recordRead(tx, messageID, userID, now)
+	if err != nil {
+		return err
+	}
+	if !recorded {
+		return nil // Already read
+	}
+
+	// Reading a message implies it was delivered
+	if _, err := recordDelivery(tx, messageID, userID, now); err != nil {
+		return err
+	}
+
+	if unread {
+		if err := decrementUnread(tx, userID, msg.ConversationID); err != nil {
+			return err
+		}
+	}
+
+	// Update message status (READ once every recipient has read it)
+	if msg != nil {
+		if err := refreshMessageStatus(tx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordRead stores a read receipt, returning false if it already existed
+func recordRead(tx *sql.Tx, messageID, userID string, now time.Time) (bool, error) {
+	result, err := tx.Exec(
+		`INSERT OR IGNORE INTO message_reads (id, message_id, user_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		fmt.Sprintf("mr_%s_%s", messageID, userID), messageID, userID, toUnix(now), toUnix(now),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to create message read: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// watermarkReaders is the FROM and WHERE of a query for the recipients whose read
+// watermark covers the message with the given ID, which then counts as read without a
+// receipt of its own: uc is their conversation entry and m the message. Group members
+// only count for messages sent after they joined.
+const watermarkReaders = ` FROM user_conversations uc JOIN messages m ON m.id = ?
+	WHERE uc.conversation_id = m.conversation_id AND uc.read_up_to >= m.created_at AND uc.user_id != m.sender_id
+	  AND (m.conversation_type != 'group' OR EXISTS (SELECT 1 FROM group_members gm
+	       WHERE gm.group_id = m.destination_id AND gm.user_id = uc.user_id AND gm.joined_at <= m.created_at))`
+
+// GetMessageReads returns the read receipts of a message, including the reads implied
+// by recipients' read watermarks
+func (s *SQLiteStore) GetMessageReads(messageID string) []*database.MessageRead {
+	result := make([]*database.MessageRead, 0)
+
+	rows, err := s.db.Query(
+		`SELECT id, message_id, user_id, created_at, updated_at FROM message_reads WHERE message_id = ?
+		 UNION ALL
+		 SELECT 'mr_' || m.id || '_' || uc.user_id, m.id, uc.user_id,
+		        COALESCE(uc.read_marked_at, uc.read_up_to), COALESCE(uc.read_marked_at, uc.read_up_to)`+watermarkReaders+`
+		   AND NOT EXISTS (SELECT 1 FROM message_reads r WHERE r.message_id = m.id AND r.user_id = uc.user_id)`,
+		messageID, messageID,
+	)
+	if err != nil {
+		return result
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var mr database.MessageRead
+		var createdAt, updatedAt int64
+		if err := rows.Scan(&mr.ID, &mr.MessageID, &mr.UserID, &createdAt, &updatedAt); err != nil {
+			return result
+		}
+		mr.CreatedAt = fromUnix(createdAt)
+		mr.UpdatedAt = fromUnix(updatedAt)
+		result = append(result, &mr)
+	}
+	return result
+}
+
+// MarkConversationRead moves userID's read watermark in a conversation forward to upTo.
+// Messages up to the watermark count as read without receipts of their own, so only the
+// unread messages sent after it are left in the unread count. It returns the updated
+// entry, nil if the user has none, and how many messages left the unread count.
+// A watermark never moves back.
+func (s *SQLiteStore) MarkConversationRead(conversationID, userID string, upTo time.Time) (*database.UserConversation, int, error) {
+	now := time.Now()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	uc, err := scanUserConversation(tx.QueryRow(
+		`SELECT `+userConversationColumns+` FROM user_conversations WHERE user_id = ? AND conversation_id = ?`,
+		userID, conversationID,
+	))
+	if err == sql.ErrNoRows {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get user conversation: %w", err)
+	}
+	if uc.ReadUpTo != nil && !upTo.After(*uc.ReadUpTo) {
+		return uc, 0, nil
+	}
+	var from int64
+	if uc.ReadUpTo != nil {
+		from = toUnix(*uc.ReadUpTo)
+	}
+
+	// The watermark and the unread count move together. Only messages sent after the
+	// watermark can still be unread; none are left when the whole conversation was marked read.
+	if _, err := tx.Exec(
+		`UPDATE user_conversations SET read_up_to = ?, read_marked_at = ?, unread_count = (
+			SELECT COUNT(*) FROM messages m
+			WHERE m.conversation_id = user_conversations.conversation_id AND m.created_at > ?
+			  AND m.sender_id != user_conversations.user_id
+			  AND m.kind != 'system' AND m.thread_root_id = '' AND m.deleted_at IS NULL
+			  AND (m.conversation_type != 'group' OR EXISTS (
+			       SELECT 1 FROM group_members gm WHERE gm.group_id = m.destination_id
+			          AND gm.user_id = user_conversations.user_id AND gm.joined_at <= m.created_at))
+			  AND NOT EXISTS (SELECT 1 FROM message_reads r WHERE r.message_id = m.id AND r.user_id = user_conversations.user_id)
+			  AND NOT EXISTS (SELECT 1 FROM hidden_messages h WHERE h.message_id = m.id AND h.user_id = user_conversations.user_id))
+		 WHERE id = ? AND (read_up_to IS NULL OR read_up_to < ?)`,
+		toUnix(upTo), toUnix(now), toUnix(upTo), uc.ID, toUnix(upTo),
+	); err != nil {
+		return nil, 0, fmt.Errorf("failed to update read watermark: %w", err)
+	}
+
+	// Senders see READ once every recipient has read, by receipt or by watermark.
+	// Members who joined after a message was sent are not among its recipients.
+	if _, err := tx.Exec(
+		`UPDATE messages SET status = ?, updated_at = ?
+		 WHERE conversation_id = ? AND created_at > ? AND created_at <= ? AND sender_id != ? AND status != ?
+		   AND (conversation_type != 'group' OR NOT EXISTS (
+		        SELECT 1 FROM group_members gm WHERE gm.group_id = messages.destination_id AND gm.user_id != messages.sender_id
+		           AND gm.joined_at <= messages.created_at
+		           AND NOT EXISTS (SELECT 1 FROM message_reads r WHERE r.message_id = messages.id AND r.user_id = gm.user_id)
+		           AND NOT EXISTS (SELECT 1 FROM user_conversations w WHERE w.user_id = gm.user_id
+		                           AND w.conversation_id = messages.conversation_id AND w.read_up_to >= messages.created_at)))`,
+		database.StatusRead, toUnix(now), conversationID, from, toUnix(upTo), userID, database.StatusRead,
+	); err != nil {
+		return nil, 0, fmt.Errorf("failed to update message status: %w", err)
+	}
+
+	updated, err := scanUserConversation(tx.QueryRow(
+		`SELECT `+userConversationColumns+` FROM user_conversations WHERE id = ?`, uc.ID,
+	))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get user conversation: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("failed to mark conversation read: %w", err)
+	}
+	return updated, max(uc.UnreadCount-updated.UnreadCount, 0), nil
+}
+
+// UserConversation operations
+const userConversationColumns = `id, user_id, conversation_id, destination_id, conversation_type, unread_count, read_up_to, read_marked_at, created_at, updated_at`
+
+func scanUserConversation(row rowScanner) (*database.UserConversation, error) {
+	var uc database.UserConversation
+	var createdAt, updatedAt int64
+	var readUpTo, readMarkedAt sql.NullInt64
+	if err := row.Scan(
+		&uc.ID, &uc.UserID, &uc.ConversationID, &uc.DestinationID, &uc.ConversationType,
+		&uc.UnreadCount, &readUpTo, &readMarkedAt, &createdAt, &updatedAt,
+	); err != nil {
+		return nil, err
+	}
+	uc.ReadUpTo = fromNullableUnix(readUpTo)
+	uc.ReadMarkedAt = fromNullableUnix(readMarkedAt)
+	uc.CreatedAt = fromUnix(createdAt)
+	uc.UpdatedAt = fromUnix(updatedAt)
+	return &uc, nil
+}
+
+func (s *SQLiteStore) GetUserConversations(userID string) ([]*database.UserConversation, error) {
+	rows, err := s.db.Query(
+		`SELECT `+userConversationColumns+` FROM user_conversations WHERE user_id = ? ORDER BY updated_at DESC`, userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user conversations: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*database.UserConversation, 0)
+	for rows.Next() {
+		uc, err := scanUserConversation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user conversations: %w", err)
+		}
+		result = append(result, uc)
+	}
+	return result, rows.Err()
+}
+
+// Search operations
+func (s *SQLiteStore) SearchMessages(userID, query string) ([]*database.Message, error) {
+	if query == "" {
+		return []*database.Message{}, nil
+	}
+
+	// LIKE is case-insensitive for ASCII, matching utils.ContainsString
+	rows, err := s.db.Query(
+		`SELECT `+messageColumns+` FROM messages
+		 WHERE (sender_id = ?
+		        OR (conversation_type = ? AND destination_id = ?)
+		        OR (conversation_type = ? AND destination_id IN (SELECT group_id FROM group_members WHERE user_id = ?)))
+		   AND message_text LIKE ? ESCAPE '\'
+		   AND kind != ?
+		   AND NOT EXISTS (SELECT 1 FROM group_members gm JOIN groups g ON g.id = gm.group_id
+		                   WHERE gm.group_id = messages.conversation_id AND gm.user_id = ?
+		                     AND g.history_visibility = ? AND messages.created_at < gm.joined_at)
+		   AND deleted_at IS NULL
+		   AND id NOT IN (SELECT message_id FROM hidden_messages WHERE user_id = ?)
+		 ORDER BY seq`,
+		userID,
+		database.ConversationTypeOneToOne, userID,
+		database.ConversationTypeGroup, userID,
+		"%"+escapeLike(query)+"%",
+		database.MessageKindSystem,
+		userID, database.HistoryVisibilitySinceJoined,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search messages: %w", err)
+	}
+	return scanMessages(rows)
+}
+
+// escapeLike escapes LIKE wildcards so the query is matched literally
+func escapeLike(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(s)
+}
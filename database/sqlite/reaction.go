@@ -0,0 +1,101 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+)
+
+// Reaction operations
+// Adding an existing reaction or removing a missing one is a no-op. Messages deleted
+// for everyone take no new reactions; the check shares a transaction with the insert.
+func (s *SQLiteStore) AddReaction(messageID, userID, emoji string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var deletedAt sql.NullInt64
+	err = tx.QueryRow(`SELECT deleted_at FROM messages WHERE id = ?`, messageID).Scan(&deletedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("message not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get message: %w", err)
+	}
+	if deletedAt.Valid {
+		return fmt.Errorf(database.ErrMessageDeleted)
+	}
+
+	if _, err := tx.Exec(
+		`INSERT OR IGNORE INTO reactions (id, message_id, user_id, emoji, created_at) VALUES (?, ?, ?, ?, ?)`,
+		fmt.Sprintf("re_%s_%s_%s", messageID, userID, emoji), messageID, userID, emoji, toUnix(time.Now()),
+	); err != nil {
+		return fmt.Errorf("failed to add reaction: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit reaction: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) RemoveReaction(messageID, userID, emoji string) error {
+	if _, err := s.GetMessage(messageID); err != nil {
+		return err
+	}
+	if _, err := s.db.Exec(
+		`DELETE FROM reactions WHERE message_id = ? AND user_id = ? AND emoji = ?`, messageID, userID, emoji,
+	); err != nil {
+		return fmt.Errorf("failed to remove reaction: %w", err)
+	}
+	return nil
+}
+
+// GetMessageReactions returns the reactions of several messages at once, keyed by message ID
+func (s *SQLiteStore) GetMessageReactions(messageIDs []string) (map[string][]*database.Reaction, error) {
+	result := make(map[string][]*database.Reaction, len(messageIDs))
+
+	// Query in batches to stay under SQLite's bound parameter limit
+	for start := 0; start < len(messageIDs); start += maxBatchParams {
+		end := start + maxBatchParams
+		if end > len(messageIDs) {
+			end = len(messageIDs)
+		}
+		if err := s.loadReactions(messageIDs[start:end], result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (s *SQLiteStore) loadReactions(messageIDs []string, result map[string][]*database.Reaction) error {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(messageIDs)), ", ")
+	args := make([]interface{}, len(messageIDs))
+	for i, messageID := range messageIDs {
+		args[i] = messageID
+	}
+
+	rows, err := s.db.Query(
+		`SELECT id, message_id, user_id, emoji, created_at FROM reactions
+		 WHERE message_id IN (`+placeholders+`) ORDER BY created_at`, args...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get reactions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var reaction database.Reaction
+		var createdAt int64
+		if err := rows.Scan(&reaction.ID, &reaction.MessageID, &reaction.UserID, &reaction.Emoji, &createdAt); err != nil {
+			return fmt.Errorf("failed to get reactions: %w", err)
+		}
+		reaction.CreatedAt = fromUnix(createdAt)
+		result[reaction.MessageID] = append(result[reaction.MessageID], &reaction)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,233 @@
+package sqlite
+
+// migrations holds the schema history, applied in order on startup
+// Never edit an existing entry - append a new one instead
+var migrations = []string{
+	// 1: initial schema
+	`
+	CREATE TABLE users (
+		id         TEXT PRIMARY KEY,
+		name       TEXT NOT NULL,
+		email      TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL
+	);
+
+	CREATE TABLE groups (
+		id          TEXT PRIMARY KEY,
+		name        TEXT NOT NULL,
+		description TEXT NOT NULL,
+		created_by  TEXT NOT NULL,
+		created_at  INTEGER NOT NULL,
+		updated_at  INTEGER NOT NULL
+	);
+
+	CREATE TABLE group_members (
+		group_id TEXT NOT NULL REFERENCES groups(id),
+		user_id  TEXT NOT NULL,
+		PRIMARY KEY (group_id, user_id)
+	);
+	CREATE INDEX idx_group_members_user ON group_members(user_id);
+
+	CREATE TABLE messages (
+		seq               INTEGER PRIMARY KEY AUTOINCREMENT,
+		id                TEXT NOT NULL UNIQUE,
+		sender_id         TEXT NOT NULL,
+		destination_id    TEXT NOT NULL,
+		message_text      TEXT NOT NULL,
+		status            TEXT NOT NULL,
+		conversation_type TEXT NOT NULL,
+		created_at        INTEGER NOT NULL,
+		updated_at        INTEGER NOT NULL
+	);
+	CREATE INDEX idx_messages_destination ON messages(destination_id, seq);
+
+	CREATE TABLE user_conversations (
+		id                TEXT PRIMARY KEY,
+		user_id           TEXT NOT NULL,
+		destination_id    TEXT NOT NULL,
+		conversation_type TEXT NOT NULL,
+		created_at        INTEGER NOT NULL,
+		updated_at        INTEGER NOT NULL,
+		UNIQUE (user_id, destination_id, conversation_type)
+	);
+
+	CREATE TABLE message_reads (
+		id         TEXT PRIMARY KEY,
+		message_id TEXT NOT NULL,
+		user_id    TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL,
+		UNIQUE (message_id, user_id)
+	);
+	`,
+	// 2: canonical conversation IDs (one-to-one chats share a sorted "dm:<a>:<b>" key)
+	`
+	ALTER TABLE messages ADD COLUMN conversation_id TEXT NOT NULL DEFAULT '';
+	UPDATE messages SET conversation_id = CASE
+		WHEN conversation_type = 'group' THEN destination_id
+		ELSE 'dm:' || min(sender_id, destination_id) || ':' || max(sender_id, destination_id)
+	END;
+	CREATE INDEX idx_messages_conversation ON messages(conversation_id, seq);
+
+	ALTER TABLE user_conversations ADD COLUMN conversation_id TEXT NOT NULL DEFAULT '';
+	UPDATE user_conversations SET conversation_id = CASE
+		WHEN conversation_type = 'group' THEN destination_id
+		ELSE 'dm:' || min(user_id, destination_id) || ':' || max(user_id, destination_id)
+	END;
+	CREATE UNIQUE INDEX idx_user_conversations_conversation ON user_conversations(user_id, conversation_id);
+	`,
+	// 3: per-recipient delivery receipts (reads imply delivery)
+	`
+	CREATE TABLE message_deliveries (
+		id         TEXT PRIMARY KEY,
+		message_id TEXT NOT NULL,
+		user_id    TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL,
+		UNIQUE (message_id, user_id)
+	);
+	INSERT OR IGNORE INTO message_deliveries (id, message_id, user_id, created_at, updated_at)
+		SELECT 'md_' || message_id || '_' || user_id, message_id, user_id, created_at, updated_at FROM message_reads;
+	`,
+	// 4: message edits with revision history
+	`
+	ALTER TABLE messages ADD COLUMN edited_at INTEGER;
+
+	CREATE TABLE message_revisions (
+		id           TEXT PRIMARY KEY,
+		message_id   TEXT NOT NULL,
+		message_text TEXT NOT NULL,
+		created_at   INTEGER NOT NULL,
+		replaced_at  INTEGER NOT NULL
+	);
+	CREATE INDEX idx_message_revisions_message ON message_revisions(message_id, replaced_at);
+	`,
+	// 5: delete for everyone (tombstones) and delete for me (per-user hides)
+	`
+	ALTER TABLE messages ADD COLUMN deleted_at INTEGER;
+
+	CREATE TABLE hidden_messages (
+		user_id    TEXT NOT NULL,
+		message_id TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		PRIMARY KEY (user_id, message_id)
+	);
+	`,
+	// 6: inline replies and threads
+	`
+	ALTER TABLE messages ADD COLUMN reply_to_message_id TEXT NOT NULL DEFAULT '';
+	ALTER TABLE messages ADD COLUMN thread_root_id TEXT NOT NULL DEFAULT '';
+	ALTER TABLE messages ADD COLUMN reply_count INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE messages ADD COLUMN last_reply_at INTEGER;
+	CREATE INDEX idx_messages_thread ON messages(thread_root_id, seq);
+	`,
+	// 7: emoji reactions
+	`
+	CREATE TABLE reactions (
+		id         TEXT PRIMARY KEY,
+		message_id TEXT NOT NULL,
+		user_id    TEXT NOT NULL,
+		emoji      TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		UNIQUE (message_id, user_id, emoji)
+	);
+	`,
+	// 8: group roles; existing creators become owners
+	`
+	ALTER TABLE group_members ADD COLUMN role TEXT NOT NULL DEFAULT 'member';
+	ALTER TABLE group_members ADD COLUMN joined_at INTEGER NOT NULL DEFAULT 0;
+	UPDATE group_members SET
+		role = CASE
+			WHEN user_id = (SELECT created_by FROM groups WHERE groups.id = group_members.group_id) THEN 'owner'
+			ELSE 'member'
+		END,
+		joined_at = (SELECT created_at FROM groups WHERE groups.id = group_members.group_id);
+	`,
+	// 9: announcement-only groups and server-posted system messages
+	`
+	ALTER TABLE groups ADD COLUMN announcement_only INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE messages ADD COLUMN kind TEXT NOT NULL DEFAULT 'user';
+	`,
+	// 10: typed system messages with a JSON payload; earlier system messages were settings changes
+	`
+	ALTER TABLE messages ADD COLUMN system_type TEXT NOT NULL DEFAULT '';
+	ALTER TABLE messages ADD COLUMN system_payload TEXT;
+	UPDATE messages SET system_type = 'settings_changed', system_payload = json_object('actor_id', sender_id)
+		WHERE kind = 'system';
+	`,
+	// 11: group invite links
+	`
+	CREATE TABLE group_invites (
+		id         TEXT PRIMARY KEY,
+		group_id   TEXT NOT NULL REFERENCES groups(id),
+		created_by TEXT NOT NULL,
+		expires_at INTEGER,
+		max_uses   INTEGER NOT NULL DEFAULT 0,
+		use_count  INTEGER NOT NULL DEFAULT 0,
+		revoked_at INTEGER,
+		created_at INTEGER NOT NULL
+	);
+	CREATE INDEX idx_group_invites_group ON group_invites(group_id, created_at);
+	`,
+	// 12: per-group history visibility for new members
+	`
+	ALTER TABLE groups ADD COLUMN history_visibility TEXT NOT NULL DEFAULT 'full';
+	`,
+	// 13: maintained unread counters; group members only count messages sent after they joined.
+	// The chat list's latest-message lookup pages top-level messages without sorting the conversation.
+	`
+	CREATE INDEX idx_messages_conversation_top ON messages(conversation_id, thread_root_id, seq);
+	ALTER TABLE user_conversations ADD COLUMN unread_count INTEGER NOT NULL DEFAULT 0;
+	UPDATE user_conversations SET unread_count = (
+		SELECT COUNT(*) FROM messages m
+		WHERE m.conversation_id = user_conversations.conversation_id
+		  AND m.sender_id != user_conversations.user_id
+		  AND m.kind != 'system' AND m.thread_root_id = '' AND m.deleted_at IS NULL
+		  AND (m.conversation_type != 'group' OR EXISTS (
+		       SELECT 1 FROM group_members gm WHERE gm.group_id = m.destination_id
+		          AND gm.user_id = user_conversations.user_id AND gm.joined_at <= m.created_at))
+		  AND NOT EXISTS (SELECT 1 FROM message_reads r WHERE r.message_id = m.id AND r.user_id = user_conversations.user_id)
+		  AND NOT EXISTS (SELECT 1 FROM hidden_messages h WHERE h.message_id = m.id AND h.user_id = user_conversations.user_id)
+	);
+	`,
+	// 14: per-conversation read watermarks
+	`
+	ALTER TABLE user_conversations ADD COLUMN read_up_to INTEGER;
+	CREATE INDEX idx_messages_conversation_created ON messages(conversation_id, created_at);
+	`,
+	// 15: signed-in sessions behind access and refresh tokens
+	`
+	CREATE TABLE sessions (
+		id           TEXT PRIMARY KEY,
+		user_id      TEXT NOT NULL,
+		refresh_hash TEXT NOT NULL,
+		expires_at   INTEGER NOT NULL,
+		revoked_at   INTEGER,
+		created_at   INTEGER NOT NULL,
+		updated_at   INTEGER NOT NULL
+	);
+	CREATE INDEX idx_sessions_user ON sessions(user_id);
+	`,
+	// 16: sign-in credentials kept apart from users, found by login name or by the user's email
+	`
+	CREATE TABLE credentials (
+		user_id       TEXT PRIMARY KEY REFERENCES users(id),
+		login_name    TEXT NOT NULL UNIQUE,
+		password_hash TEXT NOT NULL,
+		created_at    INTEGER NOT NULL,
+		updated_at    INTEGER NOT NULL
+	);
+	CREATE INDEX idx_users_email ON users(email COLLATE NOCASE);
+	`,
+	// 17: profile fields
+	`
+	ALTER TABLE users ADD COLUMN status_text TEXT NOT NULL DEFAULT '';
+	ALTER TABLE users ADD COLUMN avatar_ref TEXT NOT NULL DEFAULT '';
+	`,
+	// 18: when each read watermark last moved, the read time of the messages it covers
+	`
+	ALTER TABLE user_conversations ADD COLUMN read_marked_at INTEGER;
+	CREATE INDEX idx_user_conversations_watermark ON user_conversations(conversation_id, read_up_to);
+	`,
+}
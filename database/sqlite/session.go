@@ -0,0 +1,95 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+)
+
+const sessionColumns = `id, user_id, refresh_hash, expires_at, revoked_at, created_at, updated_at`
+
+func scanSession(row rowScanner) (*database.Session, error) {
+	var session database.Session
+	var expiresAt, createdAt, updatedAt int64
+	var revokedAt sql.NullInt64
+	if err := row.Scan(
+		&session.ID, &session.UserID, &session.RefreshHash, &expiresAt, &revokedAt, &createdAt, &updatedAt,
+	); err != nil {
+		return nil, err
+	}
+	session.ExpiresAt = fromUnix(expiresAt)
+	session.RevokedAt = fromNullableUnix(revokedAt)
+	session.CreatedAt = fromUnix(createdAt)
+	session.UpdatedAt = fromUnix(updatedAt)
+	return &session, nil
+}
+
+// Session operations
+func (s *SQLiteStore) CreateSession(session *database.Session) error {
+	now := time.Now()
+	result, err := s.db.Exec(
+		`INSERT OR IGNORE INTO sessions (`+sessionColumns+`) VALUES (?, ?, ?, ?, NULL, ?, ?)`,
+		session.ID, session.UserID, session.RefreshHash, toUnix(session.ExpiresAt), toUnix(now), toUnix(now),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("session already exists")
+	}
+
+	session.RevokedAt = nil
+	session.CreatedAt = now
+	session.UpdatedAt = now
+	return nil
+}
+
+func (s *SQLiteStore) GetSession(sessionID string) (*database.Session, error) {
+	session, err := scanSession(s.db.QueryRow(`SELECT `+sessionColumns+` FROM sessions WHERE id = ?`, sessionID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("session not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return session, nil
+}
+
+// RotateSession replaces the session's refresh token hash and extends its expiry.
+// The check and the swap happen in one statement, so a refresh token can only be
+// used once even by concurrent requests.
+func (s *SQLiteStore) RotateSession(sessionID, refreshHash, newRefreshHash string, expiresAt time.Time) (*database.Session, error) {
+	now := toUnix(time.Now())
+	result, err := s.db.Exec(
+		`UPDATE sessions SET refresh_hash = ?, expires_at = ?, updated_at = ?
+		 WHERE id = ? AND refresh_hash = ? AND revoked_at IS NULL AND expires_at > ?`,
+		newRefreshHash, toUnix(expiresAt), now, sessionID, refreshHash, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate session: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		if _, err := s.GetSession(sessionID); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("session cannot be refreshed")
+	}
+	return s.GetSession(sessionID)
+}
+
+// RevokeSession signs a session out. Revoking twice keeps the first time.
+func (s *SQLiteStore) RevokeSession(sessionID string) error {
+	if _, err := s.GetSession(sessionID); err != nil {
+		return err
+	}
+
+	now := toUnix(time.Now())
+	if _, err := s.db.Exec(
+		`UPDATE sessions SET revoked_at = ?, updated_at = ? WHERE id = ? AND revoked_at IS NULL`, now, now, sessionID,
+	); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
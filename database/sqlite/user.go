@@ -0,0 +1,133 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+)
+
+const userColumns = `id, name, email, status_text, avatar_ref, created_at, updated_at`
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// User operations
+func (s *SQLiteStore) CreateUser(user *database.User) error {
+	return insertUser(s.db, user)
+}
+
+// insertUser adds a user whose ID and email are both unused. The email check is
+// part of the insert, so concurrent sign-ups cannot share an email.
+func insertUser(db execer, user *database.User) error {
+	if !database.IsValidUserID(user.ID) {
+		return fmt.Errorf(database.ErrInvalidUserID)
+	}
+	now := time.Now()
+	result, err := db.Exec(
+		`INSERT OR IGNORE INTO users (`+userColumns+`)
+		 SELECT ?, ?, ?, ?, ?, ?, ?
+		 WHERE ? = '' OR NOT EXISTS (SELECT 1 FROM users WHERE email = ? COLLATE NOCASE)`,
+		user.ID, user.Name, user.Email, user.StatusText, user.AvatarRef, toUnix(now), toUnix(now),
+		user.Email, user.Email,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		var exists bool
+		if err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM users WHERE id = ?)`, user.ID).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		if exists {
+			return fmt.Errorf(database.ErrUserAlreadyExists)
+		}
+		return fmt.Errorf(database.ErrEmailTaken)
+	}
+
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	return nil
+}
+
+func (s *SQLiteStore) GetUser(userID string) (*database.User, error) {
+	var user database.User
+	var createdAt, updatedAt int64
+	err := s.db.QueryRow(
+		`SELECT `+userColumns+` FROM users WHERE id = ?`, userID,
+	).Scan(&user.ID, &user.Name, &user.Email, &user.StatusText, &user.AvatarRef, &createdAt, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	user.CreatedAt = fromUnix(createdAt)
+	user.UpdatedAt = fromUnix(updatedAt)
+	return &user, nil
+}
+
+// UpdateUser saves the user's profile: name, email, status text and avatar.
+// The email must not belong to another user.
+func (s *SQLiteStore) UpdateUser(user *database.User) error {
+	now := time.Now()
+	result, err := s.db.Exec(
+		`UPDATE users SET name = ?, email = ?, status_text = ?, avatar_ref = ?, updated_at = ?
+		 WHERE id = ? AND (? = '' OR NOT EXISTS (SELECT 1 FROM users WHERE email = ? COLLATE NOCASE AND id != ?))`,
+		user.Name, user.Email, user.StatusText, user.AvatarRef, toUnix(now),
+		user.ID, user.Email, user.Email, user.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		if _, err := s.GetUser(user.ID); err != nil {
+			return err
+		}
+		return fmt.Errorf(database.ErrEmailTaken)
+	}
+
+	updated, err := s.GetUser(user.ID)
+	if err != nil {
+		return err
+	}
+	*user = *updated
+	return nil
+}
+
+// CreateUserWithCredential creates a user and the credential they sign in with,
+// both or neither
+func (s *SQLiteStore) CreateUserWithCredential(user *database.User, credential *database.Credential) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertUser(tx, user); err != nil {
+		return err
+	}
+	credential.UserID = user.ID
+	result, err := tx.Exec(
+		`INSERT OR IGNORE INTO credentials (`+credentialColumns+`) VALUES (?, ?, ?, ?, ?)`,
+		credential.UserID, credential.LoginName, credential.PasswordHash, toUnix(user.CreatedAt), toUnix(user.CreatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create credential: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf(database.ErrLoginNameTaken)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit user: %w", err)
+	}
+
+	credential.CreatedAt = user.CreatedAt
+	credential.UpdatedAt = user.UpdatedAt
+	return nil
+}
@@ -1,36 +1,87 @@
 package database
 
+import "time"
+
 // Repository defines the interface for database operations
 // This allows for easy swapping between in-memory and real database implementations
 // without changing any code in handlers, services, or other parts of the application.
 //
 // Any implementation (in-memory, PostgreSQL, MySQL, etc.) must implement this interface.
-// The current implementation is MemoryStore which provides in-memory storage.
+// Current implementations are MemoryStore (in-memory storage) and SQLiteStore
+// (persistent storage), selected through the [database] mode setting.
 //
 // To add a new database implementation:
 //  1. Create a new struct that implements all methods in this interface
-//  2. Update main.go to use the new implementation instead of NewStore()
+//  2. Add a mode for it in bootstrap.NewRepository()
 //  3. No other code changes are needed
 type Repository interface {
 	// User operations
 	CreateUser(user *User) error
 	GetUser(userID string) (*User, error)
+	UpdateUser(user *User) error
+	CreateUserWithCredential(user *User, credential *Credential) error
+
+	// Credential operations
+	CreateCredential(credential *Credential) error
+	GetCredential(userID string) (*Credential, error)
+	GetCredentialByLogin(login string) (*Credential, error)
+	SetCredentialPassword(userID, passwordHash string) error
+	RenameLogin(userID, loginName string) (*Credential, error)
 
 	// Group operations
 	CreateGroup(group *Group) error
+	CreateGroupWithMembers(group *Group, members []*GroupMember) error
 	GetGroup(groupID string) (*Group, error)
-	AddGroupMember(groupID, userID string) error
+	UpdateGroup(groupID string, update GroupUpdate) (*Group, error)
+	AddGroupMember(groupID, userID string, role GroupRole) error
+	AddGroupMembers(groupID string, userIDs []string, role GroupRole, maxMembers int) ([]string, error)
+	RemoveGroupMember(groupID, userID string) error
 	IsGroupMember(groupID, userID string) bool
+	GetGroupMember(groupID, userID string) (*GroupMember, error)
+	SetGroupMemberRole(groupID, userID string, role GroupRole) error
+	TransferGroupOwnership(groupID, ownerID, newOwnerID string) error
+	ListGroupMembers(groupID string) ([]*GroupMember, error)
+
+	// Group invite operations
+	CreateGroupInvite(invite *GroupInvite) error
+	GetGroupInvite(inviteID string) (*GroupInvite, error)
+	ListGroupInvites(groupID string) ([]*GroupInvite, error)
+	RevokeGroupInvite(inviteID string) error
+	JoinGroupWithInvite(inviteID, userID string, maxMembers int) (*GroupInvite, error)
+
+	// Session operations
+	CreateSession(session *Session) error
+	GetSession(sessionID string) (*Session, error)
+	RotateSession(sessionID, refreshHash, newRefreshHash string, expiresAt time.Time) (*Session, error)
+	RevokeSession(sessionID string) error
 
 	// Message operations
 	CreateMessage(message *Message) error
 	GetMessage(messageID string) (*Message, error)
-	GetMessages(destinationID string, limit int, cursor string) ([]*Message, string, error)
+	GetMessagesByID(messageIDs []string) (map[string]*Message, error)
+	GetMessages(conversationID, userID string, query PageQuery) (*MessagePage, error)
+	GetThreadMessages(rootMessageID, userID string, query PageQuery) (*MessagePage, error)
 	UpdateMessageStatus(messageID string, status MessageStatus) error
+	UpdateMessage(messageID, messageText string) (*Message, error)
+	GetMessageRevisions(messageID string) ([]*MessageRevision, error)
+	DeleteMessageForEveryone(messageID string) (*Message, error)
+	DeleteMessageForUser(messageID, userID string) error
+
+	// Reaction operations
+	AddReaction(messageID, userID, emoji string) error
+	RemoveReaction(messageID, userID, emoji string) error
+	GetMessageReactions(messageIDs []string) (map[string][]*Reaction, error)
+
+	// MessageDelivery operations
+	CreateMessageDelivery(messageID, userID string) error
+	CreateMessageDeliveries(messageIDs []string, userID string) error
+	GetMessageDeliveries(messageID string) []*MessageDelivery
 
 	// MessageRead operations
 	CreateMessageRead(messageID, userID string) error
+	CreateMessageReads(messageIDs []string, userID string) error
 	GetMessageReads(messageID string) []*MessageRead
+	MarkConversationRead(conversationID, userID string, upTo time.Time) (*UserConversation, int, error)
 
 	// UserConversation operations
 	GetUserConversations(userID string) ([]*UserConversation, error)
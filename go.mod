@@ -5,4 +5,9 @@ go 1.21
 require (
 	github.com/BurntSushi/toml v1.6.0
 	github.com/gorilla/mux v1.8.1
+	github.com/mattn/go-sqlite3 v1.14.33
 )
+
+require github.com/gorilla/websocket v1.5.3
+
+require golang.org/x/crypto v0.31.0
@@ -6,10 +6,14 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/kasasunil/chat_app/config"
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/pkg/auth"
 	"github.com/kasasunil/chat_app/internal/pkg/errors"
 	"github.com/kasasunil/chat_app/internal/pkg/logger"
+	"github.com/kasasunil/chat_app/internal/pkg/signing"
 )
 
 type contextKey string
@@ -18,17 +22,25 @@ const UserIDKey contextKey = "user_id"
 
 // AuthMiddleware provides authentication for routes
 type AuthMiddleware struct {
-	config *config.Config
+	config      *config.Config
+	store       database.Repository
+	tokens      *auth.Tokens
+	credentials *auth.Credentials
 }
 
-// NewAuthMiddleware creates a new authentication middleware
-func NewAuthMiddleware(cfg *config.Config) *AuthMiddleware {
+// NewAuthMiddleware creates a new authentication middleware.
+// Basic credentials and Bearer tokens are checked against the credentials and sessions in store.
+func NewAuthMiddleware(cfg *config.Config, store database.Repository) *AuthMiddleware {
 	return &AuthMiddleware{
-		config: cfg,
+		config:      cfg,
+		store:       store,
+		tokens:      auth.NewTokens(signing.NewSigner(cfg.GetSigningKey())),
+		credentials: auth.NewCredentials(store),
 	}
 }
 
-// Authenticate is the middleware function that validates authentication
+// Authenticate is the middleware function that validates authentication.
+// It accepts Basic credentials and Bearer access tokens issued by /auth/login.
 func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Get Authorization header
@@ -39,53 +51,91 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
-		// Check if it's Basic auth
 		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != AuthSchemeBasic {
+		if len(parts) != 2 || (parts[0] != AuthSchemeBasic && parts[0] != AuthSchemeBearer) {
 			logger.Warn(logger.TraceAuthInvalidFormat)
 			respondWithError(w, errors.ErrInvalidAuthFormat)
 			return
 		}
 
-		// Decode base64 credentials
-		decoded, err := base64.StdEncoding.DecodeString(parts[1])
-		if err != nil {
-			logger.Warn(logger.TraceAuthInvalidBase64)
-			respondWithError(w, errors.ErrInvalidBase64)
-			return
+		var userID string
+		var appErr *errors.AppError
+		if parts[0] == AuthSchemeBearer {
+			userID, appErr = m.authenticateBearer(parts[1])
+		} else {
+			userID, appErr = m.authenticateBasic(parts[1])
 		}
-
-		// Split username and password
-		credentials := strings.SplitN(string(decoded), ":", 2)
-		if len(credentials) != 2 {
-			logger.Warn(logger.TraceAuthInvalidCreds)
-			respondWithError(w, errors.ErrInvalidCredentialsFormat)
+		if appErr != nil {
+			respondWithError(w, appErr)
 			return
 		}
 
-		username := credentials[0]
-		password := credentials[1]
+		logger.Debug(logger.TraceAuthSuccess, userID)
+		// Add the ID of the signed-in user to context; for Basic auth it is resolved from the login name
+		ctx := context.WithValue(r.Context(), UserIDKey, userID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
 
-		// Iterate through all auth clients and check if credentials match
-		authenticated := false
-		for _, client := range m.config.GetAuthClients() {
-			if client.Username == username && client.Password == password {
-				authenticated = true
-				break
-			}
-		}
+// authenticateBasic checks base64 "login:password" credentials against the stored credentials
+// and returns the ID of the user they belong to. The login is a login name or an email.
+func (m *AuthMiddleware) authenticateBasic(encoded string) (string, *errors.AppError) {
+	// Decode base64 credentials
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		logger.Warn(logger.TraceAuthInvalidBase64)
+		return "", errors.ErrInvalidBase64
+	}
 
-		if !authenticated {
-			logger.Warn(logger.TraceAuthFailed, username)
-			respondWithError(w, errors.ErrInvalidCredentials)
-			return
-		}
+	// Split username and password
+	credentials := strings.SplitN(string(decoded), ":", 2)
+	if len(credentials) != 2 {
+		logger.Warn(logger.TraceAuthInvalidCreds)
+		return "", errors.ErrInvalidCredentialsFormat
+	}
 
-		logger.Debug(logger.TraceAuthSuccess, username)
-		// Add username to context (can be used as user identifier)
-		ctx := context.WithValue(r.Context(), UserIDKey, username)
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+	username := credentials[0]
+	password := credentials[1]
+
+	user, appErr := m.credentials.Authenticate(username, password)
+	if appErr == errors.ErrAccountNotFound {
+		logger.Warn(logger.TraceAuthUserMissing, username)
+		return "", appErr
+	}
+	if appErr != nil {
+		logger.Warn(logger.TraceAuthFailed, username)
+		return "", appErr
+	}
+	return user.ID, nil
+}
+
+// authenticateBearer checks an access token: its signature, its expiry, that its
+// session has not been signed out since it was issued, and that its user still exists
+func (m *AuthMiddleware) authenticateBearer(token string) (string, *errors.AppError) {
+	claims, err := m.tokens.VerifyAccess(token)
+	if err != nil {
+		logger.Warn(logger.TraceAuthInvalidToken)
+		return "", errors.ErrInvalidToken
+	}
+	if claims.IsExpired(time.Now()) {
+		logger.Debug(logger.TraceAuthTokenRejected, claims.SessionID, claims.UserID, "expired")
+		return "", errors.ErrTokenExpired
+	}
+
+	session, err := m.store.GetSession(claims.SessionID)
+	if err != nil || session.UserID != claims.UserID {
+		logger.Warn(logger.TraceAuthTokenRejected, claims.SessionID, claims.UserID, "unknown session")
+		return "", errors.ErrInvalidToken
+	}
+	if session.RevokedAt != nil {
+		logger.Warn(logger.TraceAuthTokenRejected, claims.SessionID, claims.UserID, "revoked")
+		return "", errors.ErrSessionRevoked
+	}
+	if _, err := m.store.GetUser(claims.UserID); err != nil {
+		logger.Warn(logger.TraceAuthTokenRejected, claims.SessionID, claims.UserID, "user no longer exists")
+		return "", errors.ErrAccountNotFound
+	}
+	return claims.UserID, nil
 }
 
 // GetUserID extracts user ID from request context
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/kasasunil/chat_app/config"
+	"github.com/kasasunil/chat_app/database"
+	in_memory "github.com/kasasunil/chat_app/database/in-memory"
+	"github.com/kasasunil/chat_app/internal/pkg/auth"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/signing"
+)
+
+// newTestStore returns a store with user1, who signs in as "alice" or alice@example.com
+// with the password "password-alice"
+func newTestStore(t *testing.T) *in_memory.MemoryStore {
+	t.Helper()
+	store := in_memory.NewStore()
+	if err := store.CreateUser(&database.User{ID: "user1", Name: "Alice", Email: "alice@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := auth.HashPassword("password-alice", bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.CreateCredential(&database.Credential{UserID: "user1", LoginName: "alice", PasswordHash: hash}); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+// createSession stores a session for userID and returns an access token for it valid until expiresAt
+func createSession(t *testing.T, store database.Repository, tokens *auth.Tokens, sessionID, userID string, expiresAt time.Time) string {
+	t.Helper()
+	if err := store.CreateSession(&database.Session{ID: sessionID, UserID: userID, ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	return tokens.IssueAccess(sessionID, userID, expiresAt)
+}
+
+func basic(login, password string) string {
+	return AuthSchemeBasic + " " + base64.StdEncoding.EncodeToString([]byte(login+":"+password))
+}
+
+func TestAuthenticate(t *testing.T) {
+	cfg := config.NewConfig()
+	store := newTestStore(t)
+	tokens := auth.NewTokens(signing.NewSigner(cfg.GetSigningKey()))
+	authMiddleware := NewAuthMiddleware(cfg, store)
+
+	valid := createSession(t, store, tokens, "session-valid", "user1", time.Now().Add(time.Hour))
+	expired := createSession(t, store, tokens, "session-expired", "user1", time.Now().Add(-time.Second))
+	revoked := createSession(t, store, tokens, "session-revoked", "user1", time.Now().Add(time.Hour))
+	if err := store.RevokeSession("session-revoked"); err != nil {
+		t.Fatal(err)
+	}
+	orphaned := createSession(t, store, tokens, "session-orphaned", "user2", time.Now().Add(time.Hour))
+	unknownSession := tokens.IssueAccess("session-unknown", "user1", time.Now().Add(time.Hour))
+	otherUser := tokens.IssueAccess("session-valid", "user3", time.Now().Add(time.Hour))
+	refreshToken, _ := tokens.IssueRefresh("session-valid")
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantErr       *errors.AppError
+	}{
+		{"no header", "", errors.ErrAuthRequired},
+		{"unknown scheme", "Digest abc", errors.ErrInvalidAuthFormat},
+		{"missing credentials", AuthSchemeBasic, errors.ErrInvalidAuthFormat},
+		{"basic by login name", basic("alice", "password-alice"), nil},
+		{"basic by email", basic("Alice@Example.com", "password-alice"), nil},
+		{"basic with a wrong password", basic("alice", "wrong"), errors.ErrInvalidCredentials},
+		{"basic with an unknown login", basic("nobody", "password-alice"), errors.ErrInvalidCredentials},
+		{"basic with invalid base64", AuthSchemeBasic + " !!!", errors.ErrInvalidBase64},
+		{"basic without a colon", AuthSchemeBasic + " " + base64.StdEncoding.EncodeToString([]byte("alice")), errors.ErrInvalidCredentialsFormat},
+		{"bearer", AuthSchemeBearer + " " + valid, nil},
+		{"bearer tampered", AuthSchemeBearer + " " + valid + "x", errors.ErrInvalidToken},
+		{"bearer refresh token", AuthSchemeBearer + " " + refreshToken, errors.ErrInvalidToken},
+		{"bearer expired", AuthSchemeBearer + " " + expired, errors.ErrTokenExpired},
+		{"bearer revoked session", AuthSchemeBearer + " " + revoked, errors.ErrSessionRevoked},
+		{"bearer unknown session", AuthSchemeBearer + " " + unknownSession, errors.ErrInvalidToken},
+		{"bearer for another user's session", AuthSchemeBearer + " " + otherUser, errors.ErrInvalidToken},
+		{"bearer for a deleted user", AuthSchemeBearer + " " + orphaned, errors.ErrAccountNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(GetUserID(r)))
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me", nil)
+			if tt.authorization != "" {
+				req.Header.Set(HeaderAuthorization, tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if tt.wantErr == nil {
+				if rec.Code != http.StatusOK || rec.Body.String() != "user1" {
+					t.Fatalf("got %d %q, want 200 signed in as user1", rec.Code, rec.Body.String())
+				}
+				return
+			}
+			if code := errorCode(t, rec); rec.Code != tt.wantErr.HTTPStatus || code != string(tt.wantErr.Code) {
+				t.Fatalf("got %d %s, want %d %s", rec.Code, code, tt.wantErr.HTTPStatus, tt.wantErr.Code)
+			}
+		})
+	}
+}
+
+// errorCode returns the code of an error response
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode error response: %v", err)
+	}
+	return body.Error.Code
+}
@@ -9,9 +9,17 @@ const (
 const (
 	HeaderAuthorization = "Authorization"
 	HeaderContentType   = "Content-Type"
+	HeaderRetryAfter    = "Retry-After"
 )
 
 // Authorization scheme
 const (
-	AuthSchemeBasic = "Basic"
+	AuthSchemeBasic  = "Basic"
+	AuthSchemeBearer = "Bearer"
+)
+
+// Rate limiter key prefixes, so a user ID can never share a bucket with an IP
+const (
+	RateLimitKeyUser = "user:"
+	RateLimitKeyIP   = "ip:"
 )
@@ -0,0 +1,218 @@
+package middleware
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/kasasunil/chat_app/config"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+	"github.com/kasasunil/chat_app/internal/pkg/ratelimit"
+)
+
+// RateLimitMiddleware limits how fast clients can call the API. Requests are
+// counted per signed-in user, or per client IP on public routes, against the
+// limit configured for their route. Failed sign-ins are counted per client IP
+// on their own, so passwords cannot be guessed faster than that limit allows.
+type RateLimitMiddleware struct {
+	enabled        bool
+	clientIPHeader string
+	defaultLimiter *ratelimit.Limiter            // nil when routes without their own limit are unlimited
+	routeLimiters  map[string]*ratelimit.Limiter // "METHOD /path/template" -> limiter; nil when the route is unlimited
+	failedLogins   *ratelimit.Limiter
+}
+
+// NewRateLimitMiddleware creates the rate limiters configured in cfg
+func NewRateLimitMiddleware(cfg *config.Config) *RateLimitMiddleware {
+	cleanupInterval := cfg.GetRateLimitCleanupInterval()
+	m := &RateLimitMiddleware{
+		enabled:        cfg.RateLimit.Enabled,
+		clientIPHeader: cfg.RateLimit.ClientIPHeader,
+		defaultLimiter: newLimiter(cfg.RateLimit.Default, cleanupInterval),
+		routeLimiters:  make(map[string]*ratelimit.Limiter),
+		failedLogins:   newLimiter(cfg.RateLimit.FailedLogins, cleanupInterval),
+	}
+	for route, limit := range cfg.RateLimit.Routes {
+		m.routeLimiters[route] = newLimiter(limit, cleanupInterval)
+	}
+	return m
+}
+
+// newLimiter returns a limiter for limit, or nil if limit is unlimited
+func newLimiter(limit config.RateLimit, cleanupInterval time.Duration) *ratelimit.Limiter {
+	if limit.IsUnlimited() {
+		return nil
+	}
+	return ratelimit.NewLimiter(ratelimit.Limit{Rate: limit.RequestsPerMinute / 60, Burst: limit.Burst}, cleanupInterval)
+}
+
+// Limit is the middleware function that applies the route's request limit.
+// It must run after Authenticate on protected routes, so requests are counted per user.
+func (m *RateLimitMiddleware) Limit(next http.Handler) http.Handler {
+	if !m.enabled {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeKey(r)
+		limiter, configured := m.routeLimiters[route]
+		if !configured {
+			limiter = m.defaultLimiter
+		}
+		if limiter == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := RateLimitKeyIP + m.clientIP(r)
+		if userID := GetUserID(r); userID != "" {
+			key = RateLimitKeyUser + userID
+		}
+		if allowed, retryAfter := limiter.Allow(key); !allowed {
+			logger.Warn(logger.TraceRateLimited, key, route, retryAfter)
+			respondRateLimited(w, errors.ErrRateLimited, retryAfter)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// FailedLogins is the middleware function for routes that sign in with a password
+// in the body, such as /auth/login. Every 401 they return spends one of the
+// client IP's failed sign-in attempts; with none left, the IP is turned away.
+// An attempt is taken before the password is checked and given back unless it
+// fails, so concurrent guesses cannot all get past a single remaining attempt.
+func (m *RateLimitMiddleware) FailedLogins(next http.Handler) http.Handler {
+	return m.throttleFailedLogins(next, func(r *http.Request) bool {
+		return true
+	}, true)
+}
+
+// FailedBasicAuth is FailedLogins for protected routes: only requests signed in
+// with Basic credentials count, as Bearer tokens cannot be guessed. It must run
+// before Authenticate. Basic credentials come with every request, so an attempt
+// is only checked for beforehand and taken once the credentials fail: any number
+// of concurrent requests with the right password get through, even from one IP.
+func (m *RateLimitMiddleware) FailedBasicAuth(next http.Handler) http.Handler {
+	return m.throttleFailedLogins(next, func(r *http.Request) bool {
+		return strings.HasPrefix(r.Header.Get(HeaderAuthorization), AuthSchemeBasic+" ")
+	}, false)
+}
+
+// throttleFailedLogins counts the 401 responses to the requests isAttempt picks out.
+// With reserve set, an attempt is taken up front and refunded unless the request fails;
+// otherwise the IP only needs one left, and it is taken when the request fails.
+func (m *RateLimitMiddleware) throttleFailedLogins(next http.Handler, isAttempt func(*http.Request) bool, reserve bool) http.Handler {
+	if !m.enabled || m.failedLogins == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isAttempt(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ip := m.clientIP(r)
+		check := m.failedLogins.Check
+		if reserve {
+			check = m.failedLogins.Allow
+		}
+		// Blocked IPs are turned away even with the right password, or guessing would go on
+		if allowed, retryAfter := check(ip); !allowed {
+			logger.Warn(logger.TraceLoginThrottled, ip, retryAfter)
+			respondRateLimited(w, errors.ErrTooManyLoginAttempts, retryAfter)
+			return
+		}
+
+		// Settled as soon as the response starts, so a WebSocket signed in with Basic
+		// credentials does not hold on to an attempt for as long as it stays open
+		recorder := &statusRecorder{ResponseWriter: w, onStatus: func(status int) {
+			failed := status == http.StatusUnauthorized
+			switch {
+			case reserve && !failed:
+				m.failedLogins.Refund(ip)
+			case !reserve && failed:
+				m.failedLogins.Allow(ip) // Spends the attempt; the next request sees if any are left
+			}
+		}}
+		next.ServeHTTP(recorder, r)
+		recorder.settle(http.StatusOK)
+	})
+}
+
+// clientIP returns the address a request came from. Behind a trusted proxy that is
+// the last address in the configured header, the one the proxy itself added.
+func (m *RateLimitMiddleware) clientIP(r *http.Request) string {
+	if m.clientIPHeader != "" {
+		if value := r.Header.Get(m.clientIPHeader); value != "" {
+			addresses := strings.Split(value, ",")
+			return strings.TrimSpace(addresses[len(addresses)-1])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// routeKey returns the "METHOD /path/template" a request was routed by, as used in rate_limit.routes
+func routeKey(r *http.Request) string {
+	path := r.URL.Path
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			path = template
+		}
+	}
+	return r.Method + " " + path
+}
+
+// respondRateLimited sends a 429 error telling the client how many seconds to wait
+func respondRateLimited(w http.ResponseWriter, err *errors.AppError, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set(HeaderRetryAfter, strconv.Itoa(seconds))
+	respondWithError(w, err)
+}
+
+// statusRecorder passes the status code of a response to onStatus once it is known
+type statusRecorder struct {
+	http.ResponseWriter
+	onStatus func(status int)
+	settled  bool
+}
+
+func (s *statusRecorder) settle(status int) {
+	if !s.settled {
+		s.settled = true
+		s.onStatus(status)
+	}
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.settle(status)
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	s.settle(http.StatusOK)
+	return s.ResponseWriter.Write(b)
+}
+
+// Hijack lets WebSocket upgrades through the recorder
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	s.settle(http.StatusSwitchingProtocols)
+	hijacker, ok := s.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
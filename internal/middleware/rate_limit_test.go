@@ -0,0 +1,330 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kasasunil/chat_app/config"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+)
+
+// Ten requests a second, so tests can wait for a token without slowing down
+const (
+	testPerMinute = 600
+	testRefill    = 150 * time.Millisecond
+)
+
+// Request headers the test handlers read in place of real authentication
+const (
+	headerTestUser     = "X-Test-User"
+	headerTestPassword = "X-Test-Password"
+)
+
+func newRateLimitConfig() *config.Config {
+	cfg := config.NewConfig()
+	cfg.RateLimit.Default = config.RateLimit{RequestsPerMinute: testPerMinute, Burst: 3}
+	cfg.RateLimit.FailedLogins = config.RateLimit{RequestsPerMinute: testPerMinute, Burst: 2}
+	cfg.RateLimit.Routes = map[string]config.RateLimit{
+		"GET /limited/{id}": {RequestsPerMinute: testPerMinute, Burst: 2},
+		"GET /unlimited":    {},
+	}
+	return cfg
+}
+
+// newRateLimitRouter serves the limited test routes. A request is signed in as the
+// user in headerTestUser, and /login accepts only the password "right".
+func newRateLimitRouter(m *RateLimitMiddleware) *mux.Router {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	signIn := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(headerTestPassword) != "" && r.Header.Get(headerTestPassword) != "right" {
+				respondWithError(w, errors.ErrInvalidCredentials)
+				return
+			}
+			if userID := r.Header.Get(headerTestUser); userID != "" {
+				r = r.WithContext(context.WithValue(r.Context(), UserIDKey, userID))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	router := mux.NewRouter()
+	router.Handle("/login", m.FailedLogins(signIn(m.Limit(ok)))).Methods("POST")
+	api := router.PathPrefix("/").Subrouter()
+	api.Use(m.FailedBasicAuth, signIn, m.Limit)
+	api.Handle("/limited/{id}", ok).Methods("GET")
+	api.Handle("/unlimited", ok).Methods("GET")
+	api.Handle("/other", ok).Methods("GET")
+	return router
+}
+
+// rateLimitStep is one request of a sequence and the error it should get, nil for 200 OK
+type rateLimitStep struct {
+	method   string
+	path     string
+	user     string
+	ip       string
+	password string // Sent when set; "right" is the only password accepted
+	basic    bool   // Send a Basic Authorization header
+	wait     bool   // Wait for buckets to refill before the request
+	wantErr  *errors.AppError
+}
+
+func TestRateLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []rateLimitStep
+	}{
+		{
+			name: "route limit per user",
+			steps: []rateLimitStep{
+				{method: "GET", path: "/limited/1", user: "user1"},
+				{method: "GET", path: "/limited/2", user: "user1"},
+				{method: "GET", path: "/limited/3", user: "user1", wantErr: errors.ErrRateLimited},
+				{method: "GET", path: "/limited/1", user: "user2"},
+				{method: "GET", path: "/other", user: "user1"},
+				{method: "GET", path: "/limited/1", user: "user1", wait: true},
+			},
+		},
+		{
+			name: "default limit for routes without their own",
+			steps: []rateLimitStep{
+				{method: "GET", path: "/other", user: "user1"},
+				{method: "GET", path: "/other", user: "user1"},
+				{method: "GET", path: "/other", user: "user1"},
+				{method: "GET", path: "/other", user: "user1", wantErr: errors.ErrRateLimited},
+			},
+		},
+		{
+			name: "unlimited route",
+			steps: []rateLimitStep{
+				{method: "GET", path: "/unlimited", user: "user1"},
+				{method: "GET", path: "/unlimited", user: "user1"},
+				{method: "GET", path: "/unlimited", user: "user1"},
+				{method: "GET", path: "/unlimited", user: "user1"},
+			},
+		},
+		{
+			name: "per client IP without a user",
+			steps: []rateLimitStep{
+				{method: "GET", path: "/limited/1", ip: "10.0.0.1"},
+				{method: "GET", path: "/limited/1", ip: "10.0.0.1"},
+				{method: "GET", path: "/limited/1", ip: "10.0.0.1", wantErr: errors.ErrRateLimited},
+				{method: "GET", path: "/limited/1", ip: "10.0.0.2"},
+			},
+		},
+		{
+			name: "failed logins block the client IP",
+			steps: []rateLimitStep{
+				{method: "POST", path: "/login", ip: "10.0.0.1", password: "wrong", wantErr: errors.ErrInvalidCredentials},
+				{method: "POST", path: "/login", ip: "10.0.0.1", password: "wrong", wantErr: errors.ErrInvalidCredentials},
+				{method: "POST", path: "/login", ip: "10.0.0.1", password: "right", wantErr: errors.ErrTooManyLoginAttempts},
+				{method: "GET", path: "/other", user: "user1", ip: "10.0.0.1", password: "right", basic: true, wantErr: errors.ErrTooManyLoginAttempts},
+				{method: "GET", path: "/other", user: "user1", ip: "10.0.0.1"},
+				{method: "POST", path: "/login", ip: "10.0.0.2", password: "right"},
+				{method: "POST", path: "/login", ip: "10.0.0.1", password: "right", wait: true},
+			},
+		},
+		{
+			name: "successful logins are free",
+			steps: []rateLimitStep{
+				{method: "POST", path: "/login", ip: "10.0.0.1", password: "right"},
+				{method: "POST", path: "/login", ip: "10.0.0.1", password: "right"},
+				{method: "POST", path: "/login", ip: "10.0.0.1", password: "right"},
+			},
+		},
+		{
+			name: "failed basic auth",
+			steps: []rateLimitStep{
+				{method: "GET", path: "/unlimited", ip: "10.0.0.1", password: "wrong", basic: true, wantErr: errors.ErrInvalidCredentials},
+				{method: "GET", path: "/unlimited", ip: "10.0.0.1", password: "wrong", basic: true, wantErr: errors.ErrInvalidCredentials},
+				{method: "GET", path: "/unlimited", ip: "10.0.0.1", password: "right", basic: true, wantErr: errors.ErrTooManyLoginAttempts},
+			},
+		},
+		{
+			name: "failures without Basic credentials are not counted",
+			steps: []rateLimitStep{
+				{method: "GET", path: "/unlimited", ip: "10.0.0.1", password: "wrong", wantErr: errors.ErrInvalidCredentials},
+				{method: "GET", path: "/unlimited", ip: "10.0.0.1", password: "wrong", wantErr: errors.ErrInvalidCredentials},
+				{method: "GET", path: "/unlimited", ip: "10.0.0.1", password: "wrong", wantErr: errors.ErrInvalidCredentials},
+				{method: "POST", path: "/login", ip: "10.0.0.1", password: "right"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newRateLimitRouter(NewRateLimitMiddleware(newRateLimitConfig()))
+			for i, step := range tt.steps {
+				if step.wait {
+					time.Sleep(testRefill)
+				}
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, step.request())
+
+				if step.wantErr == nil {
+					if rec.Code != http.StatusOK {
+						t.Fatalf("step %d: got %d %s, want 200", i, rec.Code, errorCode(t, rec))
+					}
+					continue
+				}
+				if code := errorCode(t, rec); rec.Code != step.wantErr.HTTPStatus || code != string(step.wantErr.Code) {
+					t.Fatalf("step %d: got %d %s, want %d %s", i, rec.Code, code, step.wantErr.HTTPStatus, step.wantErr.Code)
+				}
+				if step.wantErr.HTTPStatus == http.StatusTooManyRequests && rec.Header().Get(HeaderRetryAfter) != "1" {
+					t.Errorf("step %d: Retry-After is %q, want 1", i, rec.Header().Get(HeaderRetryAfter))
+				}
+			}
+		})
+	}
+}
+
+func (s rateLimitStep) request() *http.Request {
+	req := httptest.NewRequest(s.method, s.path, nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	if s.ip != "" {
+		req.RemoteAddr = s.ip + ":1234"
+	}
+	if s.user != "" {
+		req.Header.Set(headerTestUser, s.user)
+	}
+	if s.password != "" {
+		req.Header.Set(headerTestPassword, s.password)
+	}
+	if s.basic {
+		req.Header.Set(HeaderAuthorization, basic("alice", s.password))
+	}
+	return req
+}
+
+func TestConcurrentFailedLogins(t *testing.T) {
+	const guesses = 20
+	m := NewRateLimitMiddleware(newRateLimitConfig())
+
+	// Every guess that gets past the limit waits for the others before failing,
+	// so none of them has spent its attempt by the time the rest arrive
+	release := make(chan struct{})
+	var checking sync.WaitGroup
+	handler := m.FailedLogins(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checking.Done()
+		<-release
+		respondWithError(w, errors.ErrInvalidCredentials)
+	}))
+
+	burst := newRateLimitConfig().RateLimit.FailedLogins.Burst
+	checking.Add(burst)
+	codes := make(chan int, guesses)
+	var done sync.WaitGroup
+	for i := 0; i < guesses; i++ {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, rateLimitStep{method: "POST", path: "/login", ip: "10.0.0.1"}.request())
+			codes <- rec.Code
+		}()
+	}
+	checking.Wait()
+	time.Sleep(20 * time.Millisecond) // Let the remaining guesses reach the limiter
+	close(release)
+	done.Wait()
+	close(codes)
+
+	checked := 0
+	for code := range codes {
+		if code == http.StatusUnauthorized {
+			checked++
+		}
+	}
+	if checked != burst {
+		t.Errorf("%d of %d concurrent guesses had their password checked, want %d", checked, guesses, burst)
+	}
+}
+
+func TestConcurrentBasicAuthIsNotThrottled(t *testing.T) {
+	const requests = 20
+	m := NewRateLimitMiddleware(newRateLimitConfig())
+
+	// Every request holds on until all of them are in, well past the failed sign-in burst
+	release := make(chan struct{})
+	var checking sync.WaitGroup
+	checking.Add(requests)
+	handler := m.FailedBasicAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checking.Done()
+		<-release
+	}))
+
+	codes := make(chan int, requests)
+	for i := 0; i < requests; i++ {
+		go func() {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, rateLimitStep{method: "GET", path: "/other", ip: "10.0.0.1", password: "right", basic: true}.request())
+			codes <- rec.Code
+		}()
+	}
+	allIn := make(chan struct{})
+	go func() {
+		checking.Wait()
+		close(allIn)
+	}()
+	select {
+	case <-allIn:
+	case <-time.After(time.Second):
+		t.Error("concurrent requests with the right Basic credentials were turned away")
+	}
+	close(release)
+
+	for i := 0; i < requests; i++ {
+		if code := <-codes; code != http.StatusOK {
+			t.Errorf("request got %d, want 200", code)
+		}
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	cfg := newRateLimitConfig()
+	cfg.RateLimit.Enabled = false
+	router := newRateLimitRouter(NewRateLimitMiddleware(cfg))
+	for i := 0; i < 5; i++ {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, rateLimitStep{method: "GET", path: "/limited/1", user: "user1"}.request())
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: got %d, want 200", i, rec.Code)
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		forwarded  string
+		remoteAddr string
+		want       string
+	}{
+		{"connection address", "", "203.0.113.9", "192.0.2.1:1234", "192.0.2.1"},
+		{"ipv6 connection address", "", "", "[2001:db8::1]:1234", "2001:db8::1"},
+		{"address the proxy added", "X-Forwarded-For", "203.0.113.9, 198.51.100.7", "192.0.2.1:1234", "198.51.100.7"},
+		{"proxy header missing", "X-Forwarded-For", "", "192.0.2.1:1234", "192.0.2.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newRateLimitConfig()
+			cfg.RateLimit.ClientIPHeader = tt.header
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if got := NewRateLimitMiddleware(cfg).clientIP(req); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"sync"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+)
+
+// Credentials checks sign-ins against the credentials in the store and resolves
+// them to the user they belong to
+type Credentials struct {
+	store database.Repository
+}
+
+// NewCredentials creates a credential checker backed by store
+func NewCredentials(store database.Repository) *Credentials {
+	return &Credentials{store: store}
+}
+
+// Authenticate returns the user that login and password sign in as. login is a
+// login name or the user's email. Unknown logins cost the same hash check as wrong
+// passwords, so response times do not reveal which part was wrong.
+func (c *Credentials) Authenticate(login, password string) (*database.User, *errors.AppError) {
+	credential, err := c.store.GetCredentialByLogin(login)
+	if err != nil {
+		CheckPassword(unknownLoginHash(), password)
+		return nil, errors.ErrInvalidCredentials
+	}
+	if !CheckPassword(credential.PasswordHash, password) {
+		return nil, errors.ErrInvalidCredentials
+	}
+
+	user, err := c.store.GetUser(credential.UserID)
+	if err != nil {
+		return nil, errors.ErrAccountNotFound
+	}
+	return user, nil
+}
+
+var (
+	unknownLoginHashOnce sync.Once
+	unknownLoginHashed   string
+)
+
+// unknownLoginHash returns a hash to check the passwords of unknown logins against
+func unknownLoginHash() string {
+	unknownLoginHashOnce.Do(func() {
+		unknownLoginHashed, _ = HashPassword("unknown login", DefaultPasswordCost)
+	})
+	return unknownLoginHashed
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kasasunil/chat_app/internal/pkg/signing"
+)
+
+// refreshSecretBytes is the size of the random secret inside a refresh token
+const refreshSecretBytes = 32
+
+// AccessClaims is what an access token carries
+type AccessClaims struct {
+	SessionID string `json:"sid"`
+	UserID    string `json:"sub"`
+	ExpiresAt int64  `json:"exp"` // Unix seconds
+}
+
+// IsExpired reports whether the token's expiry time has passed at now
+func (c *AccessClaims) IsExpired(now time.Time) bool {
+	return now.Unix() >= c.ExpiresAt
+}
+
+// refreshClaims is what a refresh token carries. Only a hash of Secret is stored,
+// so a leaked session table cannot be used to refresh.
+type refreshClaims struct {
+	SessionID string `json:"sid"`
+	Secret    string `json:"key"`
+}
+
+// Tokens issues and verifies the access and refresh tokens of a session.
+// Both are signed, so forged or tampered tokens are rejected before the store is asked.
+type Tokens struct {
+	signer *signing.Signer
+}
+
+// NewTokens creates a token issuer that signs with signer
+func NewTokens(signer *signing.Signer) *Tokens {
+	return &Tokens{signer: signer}
+}
+
+// IssueAccess returns an access token for userID's session, valid until expiresAt
+func (t *Tokens) IssueAccess(sessionID, userID string, expiresAt time.Time) string {
+	payload, _ := json.Marshal(AccessClaims{SessionID: sessionID, UserID: userID, ExpiresAt: expiresAt.Unix()})
+	return t.signer.Sign(signing.PurposeAccess, string(payload))
+}
+
+// VerifyAccess checks an access token's signature and returns its claims.
+// Expiry and the session's state are left to the caller.
+func (t *Tokens) VerifyAccess(token string) (*AccessClaims, error) {
+	payload, err := t.signer.Verify(signing.PurposeAccess, token)
+	if err != nil {
+		return nil, err
+	}
+	var claims AccessClaims
+	if err := json.Unmarshal([]byte(payload), &claims); err != nil || claims.SessionID == "" || claims.UserID == "" {
+		return nil, fmt.Errorf("malformed token")
+	}
+	return &claims, nil
+}
+
+// IssueRefresh returns a new refresh token for a session, and the hash to store for it
+func (t *Tokens) IssueRefresh(sessionID string) (string, string) {
+	secret := make([]byte, refreshSecretBytes)
+	rand.Read(secret)
+	encoded := base64.RawURLEncoding.EncodeToString(secret)
+
+	payload, _ := json.Marshal(refreshClaims{SessionID: sessionID, Secret: encoded})
+	return t.signer.Sign(signing.PurposeRefresh, string(payload)), hashSecret(encoded)
+}
+
+// VerifyRefresh checks a refresh token's signature and returns its session ID and the
+// hash to compare with the stored one
+func (t *Tokens) VerifyRefresh(token string) (string, string, error) {
+	payload, err := t.signer.Verify(signing.PurposeRefresh, token)
+	if err != nil {
+		return "", "", err
+	}
+	var claims refreshClaims
+	if err := json.Unmarshal([]byte(payload), &claims); err != nil || claims.SessionID == "" || claims.Secret == "" {
+		return "", "", fmt.Errorf("malformed token")
+	}
+	return claims.SessionID, hashSecret(claims.Secret), nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -11,11 +11,12 @@ type ErrorCode string
 // Error code prefixes for classification
 const (
 	// 4xx Client Errors
-	PrefixBadRequest   = "BAD_REQUEST"
-	PrefixUnauthorized = "UNAUTHORIZED"
-	PrefixForbidden    = "FORBIDDEN"
-	PrefixNotFound     = "NOT_FOUND"
-	PrefixConflict     = "CONFLICT"
+	PrefixBadRequest      = "BAD_REQUEST"
+	PrefixUnauthorized    = "UNAUTHORIZED"
+	PrefixForbidden       = "FORBIDDEN"
+	PrefixNotFound        = "NOT_FOUND"
+	PrefixConflict        = "CONFLICT"
+	PrefixTooManyRequests = "TOO_MANY_REQUESTS"
 
 	// 5xx Server Errors
 	PrefixServerError = "SERVER_ERROR"
@@ -31,6 +32,22 @@ const (
 	ErrCodeBadRequestSearchQueryRequired ErrorCode = PrefixBadRequest + "_SEARCH_QUERY_REQUIRED"
 	ErrCodeBadRequestGroupMemberLimit    ErrorCode = PrefixBadRequest + "_GROUP_MEMBER_LIMIT"
 	ErrCodeBadRequestInvalidConversation ErrorCode = PrefixBadRequest + "_INVALID_CONVERSATION"
+	ErrCodeBadRequestInvalidDeleteScope  ErrorCode = PrefixBadRequest + "_INVALID_DELETE_SCOPE"
+	ErrCodeBadRequestInvalidParent       ErrorCode = PrefixBadRequest + "_INVALID_PARENT_MESSAGE"
+	ErrCodeBadRequestInvalidReaction     ErrorCode = PrefixBadRequest + "_INVALID_REACTION"
+	ErrCodeBadRequestGroupNameRequired   ErrorCode = PrefixBadRequest + "_GROUP_NAME_REQUIRED"
+	ErrCodeBadRequestInvalidGroupRole    ErrorCode = PrefixBadRequest + "_INVALID_GROUP_ROLE"
+	ErrCodeBadRequestInvalidInvite       ErrorCode = PrefixBadRequest + "_INVALID_INVITE"
+	ErrCodeBadRequestInvalidVisibility   ErrorCode = PrefixBadRequest + "_INVALID_HISTORY_VISIBILITY"
+	ErrCodeBadRequestInvalidReadMarker   ErrorCode = PrefixBadRequest + "_INVALID_READ_MARKER"
+	ErrCodeBadRequestAckBatchTooLarge    ErrorCode = PrefixBadRequest + "_ACK_BATCH_TOO_LARGE"
+	ErrCodeBadRequestInvalidCursor       ErrorCode = PrefixBadRequest + "_INVALID_CURSOR"
+	ErrCodeBadRequestInvalidLoginName    ErrorCode = PrefixBadRequest + "_INVALID_LOGIN_NAME"
+	ErrCodeBadRequestInvalidPassword     ErrorCode = PrefixBadRequest + "_INVALID_PASSWORD"
+	ErrCodeBadRequestInvalidEmail        ErrorCode = PrefixBadRequest + "_INVALID_EMAIL"
+	ErrCodeBadRequestInvalidName         ErrorCode = PrefixBadRequest + "_INVALID_NAME"
+	ErrCodeBadRequestInvalidStatusText   ErrorCode = PrefixBadRequest + "_INVALID_STATUS_TEXT"
+	ErrCodeBadRequestInvalidAvatarRef    ErrorCode = PrefixBadRequest + "_INVALID_AVATAR_REF"
 
 	// 4xx - Unauthorized errors
 	ErrCodeUnauthorizedAuthRequired             ErrorCode = PrefixUnauthorized + "_AUTH_REQUIRED"
@@ -38,11 +55,27 @@ const (
 	ErrCodeUnauthorizedInvalidAuthFormat        ErrorCode = PrefixUnauthorized + "_INVALID_AUTH_FORMAT"
 	ErrCodeUnauthorizedInvalidBase64            ErrorCode = PrefixUnauthorized + "_INVALID_BASE64"
 	ErrCodeUnauthorizedInvalidCredentialsFormat ErrorCode = PrefixUnauthorized + "_INVALID_CREDENTIALS_FORMAT"
+	ErrCodeUnauthorizedInvalidToken             ErrorCode = PrefixUnauthorized + "_INVALID_TOKEN"
+	ErrCodeUnauthorizedTokenExpired             ErrorCode = PrefixUnauthorized + "_TOKEN_EXPIRED"
+	ErrCodeUnauthorizedSessionRevoked           ErrorCode = PrefixUnauthorized + "_SESSION_REVOKED"
+	ErrCodeUnauthorizedAccountNotFound          ErrorCode = PrefixUnauthorized + "_ACCOUNT_NOT_FOUND"
 
 	// 4xx - Forbidden errors
 	ErrCodeForbiddenAccessDenied        ErrorCode = PrefixForbidden + "_ACCESS_DENIED"
 	ErrCodeForbiddenNotGroupMember      ErrorCode = PrefixForbidden + "_NOT_GROUP_MEMBER"
 	ErrCodeForbiddenNotMessageRecipient ErrorCode = PrefixForbidden + "_NOT_MESSAGE_RECIPIENT"
+	ErrCodeForbiddenNotMessageSender    ErrorCode = PrefixForbidden + "_NOT_MESSAGE_SENDER"
+	ErrCodeForbiddenEditWindowExpired   ErrorCode = PrefixForbidden + "_EDIT_WINDOW_EXPIRED"
+	ErrCodeForbiddenDeleteWindowExpired ErrorCode = PrefixForbidden + "_DELETE_WINDOW_EXPIRED"
+	ErrCodeForbiddenGroupChatDisabled   ErrorCode = PrefixForbidden + "_GROUP_CHAT_DISABLED"
+	ErrCodeForbiddenNotGroupAdmin       ErrorCode = PrefixForbidden + "_NOT_GROUP_ADMIN"
+	ErrCodeForbiddenNotGroupOwner       ErrorCode = PrefixForbidden + "_NOT_GROUP_OWNER"
+	ErrCodeForbiddenGroupOwnerProtected ErrorCode = PrefixForbidden + "_GROUP_OWNER_PROTECTED"
+	ErrCodeForbiddenAnnouncementOnly    ErrorCode = PrefixForbidden + "_ANNOUNCEMENT_ONLY"
+	ErrCodeForbiddenSystemMessage       ErrorCode = PrefixForbidden + "_SYSTEM_MESSAGE"
+	ErrCodeForbiddenInviteExpired       ErrorCode = PrefixForbidden + "_INVITE_EXPIRED"
+	ErrCodeForbiddenInviteRevoked       ErrorCode = PrefixForbidden + "_INVITE_REVOKED"
+	ErrCodeForbiddenInviteExhausted     ErrorCode = PrefixForbidden + "_INVITE_EXHAUSTED"
 
 	// 4xx - Not Found errors
 	ErrCodeNotFoundResourceNotFound     ErrorCode = PrefixNotFound + "_RESOURCE_NOT_FOUND"
@@ -52,10 +85,17 @@ const (
 	ErrCodeNotFoundConversationNotFound ErrorCode = PrefixNotFound + "_CONVERSATION_NOT_FOUND"
 	ErrCodeNotFoundSenderNotFound       ErrorCode = PrefixNotFound + "_SENDER_NOT_FOUND"
 	ErrCodeNotFoundDestinationNotFound  ErrorCode = PrefixNotFound + "_DESTINATION_NOT_FOUND"
+	ErrCodeNotFoundInviteNotFound       ErrorCode = PrefixNotFound + "_INVITE_NOT_FOUND"
 
 	// 4xx - Conflict errors
 	ErrCodeConflictUserAlreadyExists  ErrorCode = PrefixConflict + "_USER_ALREADY_EXISTS"
 	ErrCodeConflictGroupAlreadyExists ErrorCode = PrefixConflict + "_GROUP_ALREADY_EXISTS"
+	ErrCodeConflictMessageDeleted     ErrorCode = PrefixConflict + "_MESSAGE_DELETED"
+	ErrCodeConflictLoginNameTaken     ErrorCode = PrefixConflict + "_LOGIN_NAME_TAKEN"
+
+	// 4xx - Too Many Requests errors
+	ErrCodeTooManyRequestsRateLimited   ErrorCode = PrefixTooManyRequests + "_RATE_LIMITED"
+	ErrCodeTooManyRequestsLoginAttempts ErrorCode = PrefixTooManyRequests + "_LOGIN_ATTEMPTS"
 
 	// 5xx - Server Errors
 	ErrCodeServerErrorInternalError          ErrorCode = PrefixServerError + "_INTERNAL_ERROR"
@@ -105,18 +145,50 @@ var (
 	ErrSearchQueryRequired = NewAppError(ErrCodeBadRequestSearchQueryRequired, "Query parameter is required", http.StatusBadRequest)
 	ErrGroupMemberLimit    = NewAppError(ErrCodeBadRequestGroupMemberLimit, "Group member limit exceeded", http.StatusBadRequest)
 	ErrInvalidConversation = NewAppError(ErrCodeBadRequestInvalidConversation, "Invalid conversation", http.StatusBadRequest)
+	ErrInvalidDeleteScope  = NewAppError(ErrCodeBadRequestInvalidDeleteScope, "Delete scope must be 'me' or 'everyone'", http.StatusBadRequest)
+	ErrInvalidParent       = NewAppError(ErrCodeBadRequestInvalidParent, "Parent message does not exist in this conversation", http.StatusBadRequest)
+	ErrInvalidReaction     = NewAppError(ErrCodeBadRequestInvalidReaction, "Reaction must be a single emoji", http.StatusBadRequest)
+	ErrGroupNameRequired   = NewAppError(ErrCodeBadRequestGroupNameRequired, "Group name cannot be empty", http.StatusBadRequest)
+	ErrInvalidGroupRole    = NewAppError(ErrCodeBadRequestInvalidGroupRole, "Role must be 'owner', 'admin' or 'member'", http.StatusBadRequest)
+	ErrInvalidInvite       = NewAppError(ErrCodeBadRequestInvalidInvite, "expires_at must be in the future and max_uses cannot be negative", http.StatusBadRequest)
+	ErrInvalidVisibility   = NewAppError(ErrCodeBadRequestInvalidVisibility, "History visibility must be 'full' or 'since_joined'", http.StatusBadRequest)
+	ErrInvalidReadMarker   = NewAppError(ErrCodeBadRequestInvalidReadMarker, "Provide exactly one of up_to_message_id or up_to", http.StatusBadRequest)
+	ErrAckBatchTooLarge    = NewAppError(ErrCodeBadRequestAckBatchTooLarge, "Too many message IDs in one acknowledgement", http.StatusBadRequest)
+	ErrInvalidCursor       = NewAppError(ErrCodeBadRequestInvalidCursor, "Cursor is malformed or belongs to another conversation", http.StatusBadRequest)
+	ErrInvalidLoginName    = NewAppError(ErrCodeBadRequestInvalidLoginName, "Login name must be 3 to 32 letters, digits, '.', '_' or '-'", http.StatusBadRequest)
+	ErrInvalidPassword     = NewAppError(ErrCodeBadRequestInvalidPassword, "Password must be 8 to 72 bytes long", http.StatusBadRequest)
+	ErrInvalidEmail        = NewAppError(ErrCodeBadRequestInvalidEmail, "Email must be a plain address such as name@example.com", http.StatusBadRequest)
+	ErrInvalidName         = NewAppError(ErrCodeBadRequestInvalidName, "Name must be 1 to 64 characters without control characters", http.StatusBadRequest)
+	ErrInvalidStatusText   = NewAppError(ErrCodeBadRequestInvalidStatusText, "Status text must be at most 140 characters without control characters", http.StatusBadRequest)
+	ErrInvalidAvatarRef    = NewAppError(ErrCodeBadRequestInvalidAvatarRef, "Avatar must be an http(s) URL or a media ID of at most 512 bytes", http.StatusBadRequest)
 
 	// Unauthorized (401)
 	ErrAuthRequired             = NewAppError(ErrCodeUnauthorizedAuthRequired, "Authorization header required", http.StatusUnauthorized)
 	ErrInvalidCredentials       = NewAppError(ErrCodeUnauthorizedInvalidCredentials, "Invalid username or password", http.StatusUnauthorized)
-	ErrInvalidAuthFormat        = NewAppError(ErrCodeUnauthorizedInvalidAuthFormat, "Invalid authorization header format. Expected: Basic <base64(username:password)>", http.StatusUnauthorized)
+	ErrInvalidAuthFormat        = NewAppError(ErrCodeUnauthorizedInvalidAuthFormat, "Invalid authorization header format. Expected: Basic <base64(username:password)> or Bearer <access_token>", http.StatusUnauthorized)
 	ErrInvalidBase64            = NewAppError(ErrCodeUnauthorizedInvalidBase64, "Invalid base64 encoding in authorization header", http.StatusUnauthorized)
 	ErrInvalidCredentialsFormat = NewAppError(ErrCodeUnauthorizedInvalidCredentialsFormat, "Invalid credentials format. Expected: username:password", http.StatusUnauthorized)
+	ErrInvalidToken             = NewAppError(ErrCodeUnauthorizedInvalidToken, "Token is malformed or was not issued by this server", http.StatusUnauthorized)
+	ErrTokenExpired             = NewAppError(ErrCodeUnauthorizedTokenExpired, "Token has expired", http.StatusUnauthorized)
+	ErrSessionRevoked           = NewAppError(ErrCodeUnauthorizedSessionRevoked, "Session has been signed out", http.StatusUnauthorized)
+	ErrAccountNotFound          = NewAppError(ErrCodeUnauthorizedAccountNotFound, "The user these credentials belong to no longer exists", http.StatusUnauthorized)
 
 	// Forbidden (403)
 	ErrForbiddenAccessDenied = NewAppError(ErrCodeForbiddenAccessDenied, "Access denied", http.StatusForbidden)
 	ErrNotGroupMember        = NewAppError(ErrCodeForbiddenNotGroupMember, "User is not a member of this group", http.StatusForbidden)
 	ErrNotMessageRecipient   = NewAppError(ErrCodeForbiddenNotMessageRecipient, "User is not the recipient of this message", http.StatusForbidden)
+	ErrNotMessageSender      = NewAppError(ErrCodeForbiddenNotMessageSender, "Only the sender can modify this message", http.StatusForbidden)
+	ErrEditWindowExpired     = NewAppError(ErrCodeForbiddenEditWindowExpired, "Message can no longer be edited", http.StatusForbidden)
+	ErrDeleteWindowExpired   = NewAppError(ErrCodeForbiddenDeleteWindowExpired, "Message can no longer be deleted for everyone", http.StatusForbidden)
+	ErrGroupChatDisabled     = NewAppError(ErrCodeForbiddenGroupChatDisabled, "Group chat is disabled", http.StatusForbidden)
+	ErrNotGroupAdmin         = NewAppError(ErrCodeForbiddenNotGroupAdmin, "Only group admins can do this", http.StatusForbidden)
+	ErrNotGroupOwner         = NewAppError(ErrCodeForbiddenNotGroupOwner, "Only the group owner can do this", http.StatusForbidden)
+	ErrGroupOwnerProtected   = NewAppError(ErrCodeForbiddenGroupOwnerProtected, "The group owner cannot be removed or demoted; transfer ownership first", http.StatusForbidden)
+	ErrAnnouncementOnly      = NewAppError(ErrCodeForbiddenAnnouncementOnly, "Only group admins can send messages in this group", http.StatusForbidden)
+	ErrSystemMessage         = NewAppError(ErrCodeForbiddenSystemMessage, "System messages cannot be edited or deleted for everyone", http.StatusForbidden)
+	ErrInviteExpired         = NewAppError(ErrCodeForbiddenInviteExpired, "Invite link has expired", http.StatusForbidden)
+	ErrInviteRevoked         = NewAppError(ErrCodeForbiddenInviteRevoked, "Invite link has been revoked", http.StatusForbidden)
+	ErrInviteExhausted       = NewAppError(ErrCodeForbiddenInviteExhausted, "Invite link has reached its maximum number of uses", http.StatusForbidden)
 
 	// Not Found (404)
 	ErrNotFound             = NewAppError(ErrCodeNotFoundResourceNotFound, "Resource not found", http.StatusNotFound)
@@ -126,10 +198,17 @@ var (
 	ErrConversationNotFound = NewAppError(ErrCodeNotFoundConversationNotFound, "Conversation not found", http.StatusNotFound)
 	ErrSenderNotFound       = NewAppError(ErrCodeNotFoundSenderNotFound, "Sender not found", http.StatusNotFound)
 	ErrDestinationNotFound  = NewAppError(ErrCodeNotFoundDestinationNotFound, "Destination not found", http.StatusNotFound)
+	ErrInviteNotFound       = NewAppError(ErrCodeNotFoundInviteNotFound, "Invite not found", http.StatusNotFound)
 
 	// Conflict (409)
 	ErrUserAlreadyExists  = NewAppError(ErrCodeConflictUserAlreadyExists, "User already exists", http.StatusConflict)
 	ErrGroupAlreadyExists = NewAppError(ErrCodeConflictGroupAlreadyExists, "Group already exists", http.StatusConflict)
+	ErrMessageDeleted     = NewAppError(ErrCodeConflictMessageDeleted, "Message has been deleted", http.StatusConflict)
+	ErrLoginNameTaken     = NewAppError(ErrCodeConflictLoginNameTaken, "Login name is already taken", http.StatusConflict)
+
+	// Too Many Requests (429)
+	ErrRateLimited          = NewAppError(ErrCodeTooManyRequestsRateLimited, "Too many requests; retry after the time in the Retry-After header", http.StatusTooManyRequests)
+	ErrTooManyLoginAttempts = NewAppError(ErrCodeTooManyRequestsLoginAttempts, "Too many failed sign-in attempts; retry after the time in the Retry-After header", http.StatusTooManyRequests)
 )
 
 // Predefined errors - 5xx Server Errors
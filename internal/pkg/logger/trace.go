@@ -10,16 +10,30 @@ const (
 	TraceConfigLoadFailed  = "Failed to load config from %s: %v. Using defaults."
 	TraceLoggerInitialized = "Logger initialized with level: %s"
 	TraceLoggerInitFailed  = "Failed to initialize logger: %v. Using defaults."
+	TraceSigningKeyMissing = "security.signing_key is not set; using a random key, signed links will not survive a restart"
+	TraceConfigDeprecated  = "Deprecated configuration: %s"
 )
 
 // Trace messages for authentication
 const (
 	TraceAuthSuccess       = "User authenticated: %s"
 	TraceAuthFailed        = "Authentication failed for username: %s"
+	TraceAuthUserMissing   = "Credentials accepted but their user no longer exists: login=%s"
 	TraceAuthHeaderMissing = "Authorization header missing"
 	TraceAuthInvalidFormat = "Invalid authorization header format"
 	TraceAuthInvalidBase64 = "Invalid base64 encoding in authorization header"
 	TraceAuthInvalidCreds  = "Invalid credentials format"
+	TraceAuthInvalidToken  = "Invalid bearer token"
+	TraceAuthTokenRejected = "Bearer token rejected: session=%s, user=%s, reason=%s"
+	TraceLogin             = "User logged in: user=%s, session=%s"
+	TraceTokenRefreshed    = "Session refreshed: user=%s, session=%s"
+	TraceRefreshReused     = "Refresh token reused, session revoked: user=%s, session=%s"
+	TraceLogout            = "User logged out: user=%s, session=%s"
+	TraceLoginRenamed      = "Login name changed: user=%s, login=%s"
+	TraceCredentialSeeded  = "Credential created from config: user=%s, login=%s"
+	TraceCredentialSkipped = "Auth client %s not loaded: %v"
+	TraceRateLimited       = "Rate limited: key=%s, route=%s, retry_after=%s"
+	TraceLoginThrottled    = "Sign-in attempts throttled: ip=%s, retry_after=%s"
 )
 
 // Trace messages for user operations
@@ -28,6 +42,7 @@ const (
 	TraceUserNotFound      = "User not found: id=%s"
 	TraceUserAlreadyExists = "User already exists: id=%s"
 	TraceUserRetrieved     = "User retrieved: id=%s"
+	TraceUserUpdated       = "User profile updated: id=%s"
 )
 
 // Trace messages for group operations
@@ -37,6 +52,12 @@ const (
 	TraceGroupMemberAdded   = "Member added to group: groupId=%s, userId=%s"
 	TraceGroupMemberRemoved = "Member removed from group: groupId=%s, userId=%s"
 	TraceGroupMemberCheck   = "Checking group membership: groupId=%s, userId=%s"
+	TraceGroupRoleChanged   = "Group role changed: groupId=%s, userId=%s, role=%s"
+	TraceGroupSettings      = "Group settings changed: groupId=%s, userId=%s, announcementOnly=%t, historyVisibility=%s"
+	TraceInviteCreated      = "Group invite created: groupId=%s, inviteId=%s, userId=%s"
+	TraceInviteRevoked      = "Group invite revoked: groupId=%s, inviteId=%s, userId=%s"
+	TraceInviteUsed         = "Group invite used: groupId=%s, inviteId=%s, userId=%s"
+	TraceSystemMessage      = "System message posted: groupId=%s, type=%s, id=%s"
 )
 
 // Trace messages for message operations
@@ -48,8 +69,13 @@ const (
 	TraceMessageDelivered     = "Message delivered: id=%s, user=%s"
 	TraceMessageRead          = "Message read acknowledged: messageID=%s, userID=%s"
 	TraceMessageReadFailed    = "Failed to create read receipt: messageID=%s, userID=%s, error=%v"
+	TraceConversationRead     = "Conversation marked read: conversation=%s, user=%s, upTo=%s, marked=%d"
 	TraceMessageFetch         = "Fetching messages: destination=%s, limit=%d, cursor=%s"
 	TraceMessageFetched       = "Messages fetched: destination=%s, count=%d"
+	TraceMessageEdited        = "Message edited: id=%s, sender=%s"
+	TraceMessageDeleted       = "Message deleted: id=%s, user=%s, scope=%s"
+	TraceReactionAdded        = "Reaction added: message=%s, user=%s, emoji=%s"
+	TraceReactionRemoved      = "Reaction removed: message=%s, user=%s, emoji=%s"
 )
 
 // Trace messages for conversation operations
@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket: up to Burst requests at once, refilled at Rate requests per second
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// bucket holds one key's tokens as of updated
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Limiter keeps a token bucket per key, such as a user or a client IP. Buckets
+// that have refilled completely hold nothing worth keeping, so they are swept
+// away every cleanup interval and recreated full on the key's next request.
+type Limiter struct {
+	limit           Limit
+	cleanupInterval time.Duration
+
+	mu          sync.Mutex
+	buckets     map[string]*bucket
+	lastCleanup time.Time
+}
+
+// NewLimiter creates a limiter that applies limit to every key
+func NewLimiter(limit Limit, cleanupInterval time.Duration) *Limiter {
+	return &Limiter{
+		limit:           limit,
+		cleanupInterval: cleanupInterval,
+		buckets:         make(map[string]*bucket),
+		lastCleanup:     time.Now(),
+	}
+}
+
+// Allow takes a token for key. Without one left it returns false and how long
+// until the next token is available.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.refill(key, time.Now())
+	if b.tokens < 1 {
+		return false, l.wait(b)
+	}
+	b.tokens--
+	return true, 0
+}
+
+// Check reports whether key has a token left without taking it. Without one it
+// also returns how long until the next token is available.
+func (l *Limiter) Check(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.refill(key, time.Now())
+	if b.tokens < 1 {
+		return false, l.wait(b)
+	}
+	return true, 0
+}
+
+// Refund gives back a token taken by Allow, for a request that turned out not to count
+func (l *Limiter) Refund(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.refill(key, time.Now())
+	b.tokens = math.Min(float64(l.limit.Burst), b.tokens+1)
+}
+
+// refill returns key's bucket with the tokens earned since it was last used.
+// Callers hold l.mu.
+func (l *Limiter) refill(key string, now time.Time) *bucket {
+	if now.Sub(l.lastCleanup) >= l.cleanupInterval {
+		l.cleanup(now)
+	}
+
+	b, exists := l.buckets[key]
+	if !exists {
+		b = &bucket{tokens: float64(l.limit.Burst), updated: now}
+		l.buckets[key] = b
+		return b
+	}
+	b.tokens = math.Min(float64(l.limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*l.limit.Rate)
+	b.updated = now
+	return b
+}
+
+// wait returns how long until b holds a whole token
+func (l *Limiter) wait(b *bucket) time.Duration {
+	return time.Duration((1 - b.tokens) / l.limit.Rate * float64(time.Second))
+}
+
+// cleanup drops the buckets that would be full by now. Callers hold l.mu.
+func (l *Limiter) cleanup(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*l.limit.Rate >= float64(l.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastCleanup = now
+}
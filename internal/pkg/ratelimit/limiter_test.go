@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	l := NewLimiter(Limit{Rate: 10, Burst: 2}, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatalf("request %d within the burst was refused", i)
+		}
+	}
+	ok, wait := l.Allow("a")
+	if ok {
+		t.Fatal("request over the burst was allowed")
+	}
+	if wait <= 0 || wait > 100*time.Millisecond {
+		t.Errorf("wait is %s, want up to 100ms for 10 tokens a second", wait)
+	}
+	if ok, _ := l.Allow("b"); !ok {
+		t.Error("another key shares the first key's bucket")
+	}
+
+	time.Sleep(wait)
+	if ok, _ := l.Allow("a"); !ok {
+		t.Error("request after waiting was refused")
+	}
+}
+
+func TestLimiterCheck(t *testing.T) {
+	l := NewLimiter(Limit{Rate: 10, Burst: 1}, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Check("a"); !ok {
+			t.Fatalf("check %d took the only token", i)
+		}
+	}
+	l.Allow("a")
+	if ok, wait := l.Check("a"); ok || wait <= 0 {
+		t.Errorf("check of an empty bucket returned %t, %s, want false and a wait", ok, wait)
+	}
+}
+
+func TestLimiterRefund(t *testing.T) {
+	l := NewLimiter(Limit{Rate: 0.1, Burst: 1}, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatalf("request %d after a refund was refused", i)
+		}
+		l.Refund("a")
+	}
+	// Refunds never fill the bucket past its burst
+	l.Refund("a")
+	l.Allow("a")
+	if ok, _ := l.Allow("a"); ok {
+		t.Error("refund raised the bucket above its burst")
+	}
+}
+
+func TestLimiterCleanup(t *testing.T) {
+	l := NewLimiter(Limit{Rate: 100, Burst: 2}, 50*time.Millisecond)
+	for i := 0; i < 50; i++ {
+		l.Allow(fmt.Sprint("idle", i))
+	}
+	l.Allow("busy")
+	l.Allow("busy")
+
+	time.Sleep(60 * time.Millisecond)
+	l.Allow("busy")
+	l.Allow("busy")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.buckets) != 1 || l.buckets["busy"] == nil {
+		t.Errorf("%d buckets left after cleanup, want only the busy one", len(l.buckets))
+	}
+}
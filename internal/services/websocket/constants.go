@@ -0,0 +1,28 @@
+package websocket
+
+import "time"
+
+// Frame types sent from server to client
+const (
+	FrameTypeMessage   = "message"
+	FrameTypeDelivered = "delivered"
+	FrameTypeRead      = "read"
+	FrameTypeReadUpTo  = "read_up_to"
+	FrameTypeAckResult = "ack_result"
+	FrameTypeError     = "error"
+)
+
+// Frame types sent from client to server
+const (
+	FrameTypeAckDelivered = "ack_delivered"
+	FrameTypeAckRead      = "ack_read"
+)
+
+// Connection tuning
+const (
+	writeWait      = 10 * time.Second    // Time allowed to write a frame to the peer
+	pongWait       = 60 * time.Second    // Time allowed to read the next pong from the peer
+	pingPeriod     = (pongWait * 9) / 10 // Send pings with this period, must be less than pongWait
+	maxFrameSize   = 64 * 1024           // Maximum frame size accepted from the peer
+	sendBufferSize = 256                 // Outbound frames queued per connection
+)
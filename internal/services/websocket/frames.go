@@ -0,0 +1,56 @@
+package websocket
+
+import (
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+)
+
+// ServerFrame is a JSON frame pushed to clients
+type ServerFrame struct {
+	Type           string                 `json:"type"`
+	Message        *database.Message      `json:"message,omitempty"`
+	MessageID      string                 `json:"message_id,omitempty"`
+	ConversationID string                 `json:"conversation_id,omitempty"` // Set on read_up_to frames
+	UserID         string                 `json:"user_id,omitempty"`
+	ReadUpTo       *time.Time             `json:"read_up_to,omitempty"` // The reader's new watermark
+	Error          map[string]interface{} `json:"error,omitempty"`
+	Results        []AckFrameResult       `json:"results,omitempty"` // Per-ID outcomes of a batch ACK
+}
+
+// AckFrameResult is the outcome for one message of a batch ACK frame
+type AckFrameResult struct {
+	MessageID    string                 `json:"message_id"`
+	Acknowledged bool                   `json:"acknowledged"`
+	Error        map[string]interface{} `json:"error,omitempty"`
+}
+
+// ClientFrame is a JSON frame received from clients.
+// ACK frames carry either MessageID or a batch in MessageIDs.
+type ClientFrame struct {
+	Type       string   `json:"type"`
+	MessageID  string   `json:"message_id"`
+	MessageIDs []string `json:"message_ids,omitempty"`
+}
+
+// newAckBatchFrame reports the outcome of a batch ACK, in request order
+func newAckBatchFrame(messageIDs []string, outcomes []*errors.AppError) *ServerFrame {
+	results := make([]AckFrameResult, len(messageIDs))
+	for i, messageID := range messageIDs {
+		results[i] = AckFrameResult{MessageID: messageID, Acknowledged: outcomes[i] == nil}
+		if outcomes[i] != nil {
+			results[i].Error = outcomes[i].ToJSONResponse()["error"].(map[string]interface{})
+		}
+	}
+	return &ServerFrame{Type: FrameTypeAckResult, Results: results}
+}
+
+// newErrorFrame wraps an AppError in the same shape as HTTP error responses
+func newErrorFrame(messageID string, err *errors.AppError) *ServerFrame {
+	return &ServerFrame{
+		Type:      FrameTypeError,
+		MessageID: messageID,
+		Error:     err.ToJSONResponse()["error"].(map[string]interface{}),
+	}
+}
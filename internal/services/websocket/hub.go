@@ -0,0 +1,387 @@
+package websocket
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	gorillaws "github.com/gorilla/websocket"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+	"github.com/kasasunil/chat_app/internal/pkg/logger"
+	"github.com/kasasunil/chat_app/internal/pkg/utils"
+)
+
+// Hub is a WebSocketManager backed by real WebSocket connections.
+// A user may hold several connections at once (one per device); every
+// frame addressed to the user is written to all of them.
+type Hub struct {
+	mu           sync.RWMutex
+	store        database.Repository
+	acks         AckProcessor
+	upgrader     gorillaws.Upgrader
+	connections  map[string]map[string]*Connection // userID -> connectionID -> connection
+	shutdownOnce sync.Once
+}
+
+// Connection is a single client connection (one device) of a user
+type Connection struct {
+	ID     string
+	UserID string
+	conn   *gorillaws.Conn // nil for connections registered through AddConnection
+	send   chan []byte
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewHub creates a new WebSocket hub
+// Accepts interface, returns struct (following Go best practices)
+func NewHub(store database.Repository) *Hub {
+	return &Hub{
+		store:       store,
+		upgrader:    gorillaws.Upgrader{ReadBufferSize: 1024, WriteBufferSize: 1024},
+		connections: make(map[string]map[string]*Connection),
+	}
+}
+
+// SetAckProcessor sets the processor for ACK frames received from clients.
+// It is set after construction because the processor (the HTTP handler) depends on the hub.
+func (h *Hub) SetAckProcessor(acks AckProcessor) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.acks = acks
+}
+
+// HandleWebSocket handles GET /ws
+// Upgrades the authenticated request and serves the connection until it closes.
+func (h *Hub) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		http.Error(w, errors.ErrAuthRequired.Message, errors.ErrAuthRequired.HTTPStatus)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already written an HTTP error response
+		logger.Warn("WebSocket upgrade failed: user=%s, error=%v", userID, err)
+		return
+	}
+
+	c := &Connection{
+		ID:     utils.GenerateID(),
+		UserID: userID,
+		conn:   conn,
+		send:   make(chan []byte, sendBufferSize),
+	}
+	h.register(c)
+
+	go h.writePump(c)
+	h.readPump(c)
+}
+
+// register adds a connection to the user's connection set
+func (h *Hub) register(c *Connection) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.connections[c.UserID] == nil {
+		h.connections[c.UserID] = make(map[string]*Connection)
+		logger.Info(logger.TraceWSUserConnected, c.UserID)
+	}
+	h.connections[c.UserID][c.ID] = c
+	logger.Info(logger.TraceWSConnectionAdded, c.UserID, c.ID)
+}
+
+// unregister removes a connection and releases its resources.
+// Safe to call more than once for the same connection.
+func (h *Hub) unregister(c *Connection) {
+	h.mu.Lock()
+	if conns := h.connections[c.UserID]; conns != nil && conns[c.ID] == c {
+		delete(conns, c.ID)
+		logger.Info(logger.TraceWSConnectionRemoved, c.UserID, c.ID)
+		if len(conns) == 0 {
+			delete(h.connections, c.UserID)
+			logger.Info(logger.TraceWSUserDisconnected, c.UserID)
+		}
+	}
+	h.mu.Unlock()
+
+	c.close()
+}
+
+// close stops the write pump; the write pump closes the socket on exit
+func (c *Connection) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
+
+// enqueue queues a payload without blocking.
+// Returns false when the send buffer is full.
+func (c *Connection) enqueue(payload []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return true
+	}
+	select {
+	case c.send <- payload:
+		return true
+	default:
+		return false
+	}
+}
+
+// readPump reads client frames until the connection fails or is closed
+func (h *Hub) readPump(c *Connection) {
+	defer h.unregister(c)
+
+	c.conn.SetReadLimit(maxFrameSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		var frame ClientFrame
+		if err := c.conn.ReadJSON(&frame); err != nil {
+			// A malformed frame is reported back; the connection stays open
+			switch err.(type) {
+			case *json.SyntaxError, *json.UnmarshalTypeError:
+				h.sendFrame(c, newErrorFrame("", errors.ErrInvalidRequest))
+				continue
+			}
+			if gorillaws.IsUnexpectedCloseError(err, gorillaws.CloseGoingAway, gorillaws.CloseNormalClosure) {
+				logger.Warn("WebSocket read failed: user=%s, connection=%s, error=%v", c.UserID, c.ID, err)
+			}
+			return
+		}
+		h.handleFrame(c, &frame)
+	}
+}
+
+// handleFrame dispatches a client frame and replies with its result
+func (h *Hub) handleFrame(c *Connection, frame *ClientFrame) {
+	h.mu.RLock()
+	acks := h.acks
+	h.mu.RUnlock()
+
+	if acks == nil {
+		h.sendFrame(c, newErrorFrame(frame.MessageID, errors.ErrInternalError))
+		return
+	}
+
+	if frame.MessageIDs != nil {
+		h.handleBatchFrame(c, frame, acks)
+		return
+	}
+
+	var appErr *errors.AppError
+	switch frame.Type {
+	case FrameTypeAckDelivered:
+		appErr = acks.ProcessAckDelivered(c.UserID, frame.MessageID)
+	case FrameTypeAckRead:
+		appErr = acks.ProcessAckRead(c.UserID, frame.MessageID)
+	default:
+		appErr = errors.ErrInvalidRequest
+	}
+
+	if appErr != nil {
+		h.sendFrame(c, newErrorFrame(frame.MessageID, appErr))
+		return
+	}
+	h.sendFrame(c, &ServerFrame{Type: FrameTypeAckResult, MessageID: frame.MessageID})
+}
+
+// handleBatchFrame processes an ACK frame carrying several message IDs and
+// replies with one ack_result frame listing the outcome of each
+func (h *Hub) handleBatchFrame(c *Connection, frame *ClientFrame, acks AckProcessor) {
+	if frame.MessageID != "" {
+		h.sendFrame(c, newErrorFrame("", errors.ErrInvalidRequest))
+		return
+	}
+
+	var outcomes []*errors.AppError
+	var appErr *errors.AppError
+	switch frame.Type {
+	case FrameTypeAckDelivered:
+		outcomes, appErr = acks.ProcessAckDeliveredBatch(c.UserID, frame.MessageIDs)
+	case FrameTypeAckRead:
+		outcomes, appErr = acks.ProcessAckReadBatch(c.UserID, frame.MessageIDs)
+	default:
+		appErr = errors.ErrInvalidRequest
+	}
+
+	if appErr != nil {
+		h.sendFrame(c, newErrorFrame("", appErr))
+		return
+	}
+	h.sendFrame(c, newAckBatchFrame(frame.MessageIDs, outcomes))
+}
+
+// writePump writes queued frames and keepalive pings to the socket.
+// It owns all writes to the connection, as gorilla/websocket allows a single writer.
+func (h *Hub) writePump(c *Connection) {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case payload, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				// Hub closed the channel
+				c.conn.WriteMessage(gorillaws.CloseMessage, gorillaws.FormatCloseMessage(gorillaws.CloseNormalClosure, ""))
+				return
+			}
+			if err := c.conn.WriteMessage(gorillaws.TextMessage, payload); err != nil {
+				h.unregister(c)
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(gorillaws.PingMessage, nil); err != nil {
+				h.unregister(c)
+				return
+			}
+		}
+	}
+}
+
+// sendFrame queues a frame on a single connection.
+// A connection whose buffer is full is too slow to keep up and is dropped.
+func (h *Hub) sendFrame(c *Connection, frame *ServerFrame) {
+	if c.conn == nil {
+		return
+	}
+
+	payload, err := json.Marshal(frame)
+	if err != nil {
+		logger.Error("Failed to encode WebSocket frame: %v", err)
+		return
+	}
+
+	if !c.enqueue(payload) {
+		logger.Warn("WebSocket send buffer full, dropping connection: user=%s, connection=%s", c.UserID, c.ID)
+		h.unregister(c)
+	}
+}
+
+// sendToUser queues a frame on every connection of a user
+func (h *Hub) sendToUser(userID string, frame *ServerFrame) {
+	h.mu.RLock()
+	conns := make([]*Connection, 0, len(h.connections[userID]))
+	for _, c := range h.connections[userID] {
+		conns = append(conns, c)
+	}
+	h.mu.RUnlock()
+
+	for _, c := range conns {
+		h.sendFrame(c, frame)
+	}
+}
+
+// SendMessage pushes a message to every connection the user has open.
+// Offline users are skipped; they pick the message up through the history API.
+func (h *Hub) SendMessage(userID string, message *database.Message) error {
+	h.sendToUser(userID, &ServerFrame{Type: FrameTypeMessage, Message: message})
+	logger.Debug(logger.TraceWSMessageSent, userID, message.ID)
+	return nil
+}
+
+// AckDelivered tells the message sender that userID received the message
+func (h *Hub) AckDelivered(userID string, messageID string) error {
+	return h.notifySender(FrameTypeDelivered, userID, messageID)
+}
+
+// AckRead tells the message sender that userID read the message
+func (h *Hub) AckRead(userID string, messageID string) error {
+	return h.notifySender(FrameTypeRead, userID, messageID)
+}
+
+// AckConversationRead tells every participant of a conversation, including the reader's
+// other devices, that userID read it up to upTo. One frame stands for all the messages
+// the read watermark moved over.
+func (h *Hub) AckConversationRead(userID, conversationID string, upTo time.Time) error {
+	var participants []string
+	if userA, userB, ok := database.ParseOneToOneConversationID(conversationID); ok {
+		participants = []string{userA, userB}
+	} else {
+		members, err := h.store.ListGroupMembers(conversationID)
+		if err != nil {
+			return err
+		}
+		participants = database.GroupMemberIDs(members)
+	}
+
+	frame := &ServerFrame{Type: FrameTypeReadUpTo, ConversationID: conversationID, UserID: userID, ReadUpTo: &upTo}
+	for _, participantID := range participants {
+		h.sendToUser(participantID, frame)
+	}
+	return nil
+}
+
+func (h *Hub) notifySender(frameType, userID, messageID string) error {
+	message, err := h.store.GetMessage(messageID)
+	if err != nil {
+		return err
+	}
+	h.sendToUser(message.SenderID, &ServerFrame{Type: frameType, MessageID: messageID, UserID: userID})
+	return nil
+}
+
+// AddConnection registers a connection without a socket (presence only).
+// Real clients connect through HandleWebSocket instead.
+func (h *Hub) AddConnection(userID string, connectionID string) {
+	h.register(&Connection{
+		ID:     connectionID,
+		UserID: userID,
+		send:   make(chan []byte),
+	})
+}
+
+// RemoveConnection removes a connection for a user and closes its socket
+func (h *Hub) RemoveConnection(userID string, connectionID string) {
+	h.mu.RLock()
+	c := h.connections[userID][connectionID]
+	h.mu.RUnlock()
+
+	if c != nil {
+		h.unregister(c)
+	}
+}
+
+// IsUserConnected checks if a user has any active connections
+func (h *Hub) IsUserConnected(userID string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.connections[userID]) > 0
+}
+
+// Shutdown closes every open connection
+func (h *Hub) Shutdown() {
+	h.shutdownOnce.Do(func() {
+		h.mu.RLock()
+		conns := make([]*Connection, 0)
+		for _, userConns := range h.connections {
+			for _, c := range userConns {
+				conns = append(conns, c)
+			}
+		}
+		h.mu.RUnlock()
+
+		for _, c := range conns {
+			h.unregister(c)
+		}
+	})
+}
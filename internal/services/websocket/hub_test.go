@@ -0,0 +1,305 @@
+package websocket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gorillaws "github.com/gorilla/websocket"
+
+	"github.com/kasasunil/chat_app/database"
+	in_memory "github.com/kasasunil/chat_app/database/in-memory"
+	"github.com/kasasunil/chat_app/internal/middleware"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+)
+
+// testTimeout bounds every wait for a frame or a change in the hub
+const testTimeout = 2 * time.Second
+
+// recordingAcks is an AckProcessor that records the ACKs it is given.
+// Message IDs listed in missing are answered with ErrMessageNotFound.
+type recordingAcks struct {
+	mu      sync.Mutex
+	acks    []string // "type:userID:messageID"
+	missing map[string]bool
+}
+
+func (a *recordingAcks) record(frameType, userID, messageID string) *errors.AppError {
+	if a.missing[messageID] {
+		return errors.ErrMessageNotFound
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.acks = append(a.acks, frameType+":"+userID+":"+messageID)
+	return nil
+}
+
+func (a *recordingAcks) recorded() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string(nil), a.acks...)
+}
+
+func (a *recordingAcks) ProcessAckDelivered(userID, messageID string) *errors.AppError {
+	return a.record(FrameTypeAckDelivered, userID, messageID)
+}
+
+func (a *recordingAcks) ProcessAckRead(userID, messageID string) *errors.AppError {
+	return a.record(FrameTypeAckRead, userID, messageID)
+}
+
+func (a *recordingAcks) ProcessAckDeliveredBatch(userID string, messageIDs []string) ([]*errors.AppError, *errors.AppError) {
+	outcomes := make([]*errors.AppError, len(messageIDs))
+	for i, messageID := range messageIDs {
+		outcomes[i] = a.record(FrameTypeAckDelivered, userID, messageID)
+	}
+	return outcomes, nil
+}
+
+func (a *recordingAcks) ProcessAckReadBatch(userID string, messageIDs []string) ([]*errors.AppError, *errors.AppError) {
+	outcomes := make([]*errors.AppError, len(messageIDs))
+	for i, messageID := range messageIDs {
+		outcomes[i] = a.record(FrameTypeAckRead, userID, messageID)
+	}
+	return outcomes, nil
+}
+
+// newTestHub serves a hub over an in-process HTTP server. The user a client
+// connects as is taken from the "user" query parameter in place of authentication.
+func newTestHub(t *testing.T) (*Hub, *in_memory.MemoryStore, *recordingAcks, *httptest.Server) {
+	t.Helper()
+	store := in_memory.NewStore()
+	hub := NewHub(store)
+	acks := &recordingAcks{missing: map[string]bool{"missing": true}}
+	hub.SetAckProcessor(acks)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), middleware.UserIDKey, r.URL.Query().Get("user"))
+		hub.HandleWebSocket(w, r.WithContext(ctx))
+	}))
+	t.Cleanup(func() {
+		hub.Shutdown()
+		server.Close()
+	})
+	return hub, store, acks, server
+}
+
+// dial opens a connection as userID and waits until the hub has registered it
+func dial(t *testing.T, hub *Hub, server *httptest.Server, userID string) *gorillaws.Conn {
+	t.Helper()
+	before := connectionCount(hub, userID)
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/?user=" + userID
+	conn, _, err := gorillaws.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial as %s: %v", userID, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	waitFor(t, "connection to register", func() bool { return connectionCount(hub, userID) == before+1 })
+	return conn
+}
+
+func connectionCount(hub *Hub, userID string) int {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	return len(hub.connections[userID])
+}
+
+func waitFor(t *testing.T, what string, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(testTimeout)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func readFrame(t *testing.T, conn *gorillaws.Conn) ServerFrame {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(testTimeout))
+	var frame ServerFrame
+	if err := conn.ReadJSON(&frame); err != nil {
+		t.Fatalf("read frame: %v", err)
+	}
+	return frame
+}
+
+func TestHubSendsMessageToEveryConnectionOfUser(t *testing.T) {
+	hub, _, _, server := newTestHub(t)
+	phone := dial(t, hub, server, "user1")
+	laptop := dial(t, hub, server, "user1")
+	other := dial(t, hub, server, "user2")
+
+	hub.SendMessage("user1", &database.Message{ID: "m1", SenderID: "user2", MessageText: "hi"})
+	hub.SendMessage("user2", &database.Message{ID: "m2", SenderID: "user1", MessageText: "hello"})
+
+	for name, conn := range map[string]*gorillaws.Conn{"phone": phone, "laptop": laptop} {
+		frame := readFrame(t, conn)
+		if frame.Type != FrameTypeMessage || frame.Message == nil || frame.Message.ID != "m1" {
+			t.Errorf("%s got %+v, want message m1", name, frame)
+		}
+	}
+	// The other user's first frame is their own message, not user1's
+	if frame := readFrame(t, other); frame.Message == nil || frame.Message.ID != "m2" {
+		t.Errorf("other user got %+v, want message m2", frame)
+	}
+}
+
+func TestHubAckFrames(t *testing.T) {
+	tests := []struct {
+		name       string
+		send       ClientFrame
+		wantType   string
+		wantAcks   []string
+		wantResult []AckFrameResult
+	}{
+		{
+			name:     "delivered",
+			send:     ClientFrame{Type: FrameTypeAckDelivered, MessageID: "m1"},
+			wantType: FrameTypeAckResult,
+			wantAcks: []string{"ack_delivered:user1:m1"},
+		},
+		{
+			name:     "read",
+			send:     ClientFrame{Type: FrameTypeAckRead, MessageID: "m1"},
+			wantType: FrameTypeAckResult,
+			wantAcks: []string{"ack_read:user1:m1"},
+		},
+		{
+			name:     "unknown message",
+			send:     ClientFrame{Type: FrameTypeAckRead, MessageID: "missing"},
+			wantType: FrameTypeError,
+		},
+		{
+			name:     "unknown frame type",
+			send:     ClientFrame{Type: "ack_sideways", MessageID: "m1"},
+			wantType: FrameTypeError,
+		},
+		{
+			name:     "batch",
+			send:     ClientFrame{Type: FrameTypeAckRead, MessageIDs: []string{"m1", "missing", "m2"}},
+			wantType: FrameTypeAckResult,
+			wantAcks: []string{"ack_read:user1:m1", "ack_read:user1:m2"},
+			wantResult: []AckFrameResult{
+				{MessageID: "m1", Acknowledged: true},
+				{MessageID: "missing", Acknowledged: false},
+				{MessageID: "m2", Acknowledged: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub, _, acks, server := newTestHub(t)
+			conn := dial(t, hub, server, "user1")
+
+			if err := conn.WriteJSON(tt.send); err != nil {
+				t.Fatalf("write frame: %v", err)
+			}
+			frame := readFrame(t, conn)
+			if frame.Type != tt.wantType {
+				t.Fatalf("got %s frame %+v, want %s", frame.Type, frame, tt.wantType)
+			}
+			if frame.MessageID != tt.send.MessageID {
+				t.Errorf("reply is for message %q, want %q", frame.MessageID, tt.send.MessageID)
+			}
+			if got := acks.recorded(); strings.Join(got, ",") != strings.Join(tt.wantAcks, ",") {
+				t.Errorf("processed %v, want %v", got, tt.wantAcks)
+			}
+			if len(frame.Results) != len(tt.wantResult) {
+				t.Fatalf("got %d results, want %d", len(frame.Results), len(tt.wantResult))
+			}
+			for i, want := range tt.wantResult {
+				got := frame.Results[i]
+				if got.MessageID != want.MessageID || got.Acknowledged != want.Acknowledged {
+					t.Errorf("result %d is %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestHubNotifiesSenderOfReceipts(t *testing.T) {
+	hub, store, _, server := newTestHub(t)
+	message := &database.Message{ID: "m1", SenderID: "user1", DestinationID: "user2", ConversationType: database.ConversationTypeOneToOne, MessageText: "hi"}
+	if err := store.CreateMessage(message); err != nil {
+		t.Fatal(err)
+	}
+	phone := dial(t, hub, server, "user1")
+	laptop := dial(t, hub, server, "user1")
+
+	hub.AckDelivered("user2", "m1")
+	hub.AckRead("user2", "m1")
+
+	for name, conn := range map[string]*gorillaws.Conn{"phone": phone, "laptop": laptop} {
+		for _, wantType := range []string{FrameTypeDelivered, FrameTypeRead} {
+			frame := readFrame(t, conn)
+			if frame.Type != wantType || frame.MessageID != "m1" || frame.UserID != "user2" {
+				t.Errorf("%s got %+v, want %s of m1 by user2", name, frame, wantType)
+			}
+		}
+	}
+}
+
+func TestHubNotifiesParticipantsOfReadWatermark(t *testing.T) {
+	hub, store, _, server := newTestHub(t)
+	group := &database.Group{ID: "group1", Name: "Team", CreatedBy: "user1"}
+	if err := store.CreateGroup(group); err != nil {
+		t.Fatal(err)
+	}
+	for _, userID := range []string{"user1", "user2", "user3"} {
+		store.AddGroupMember(group.ID, userID, database.GroupRoleMember)
+	}
+	conns := map[string]*gorillaws.Conn{
+		"sender":          dial(t, hub, server, "user1"),
+		"other member":    dial(t, hub, server, "user3"),
+		"reader's device": dial(t, hub, server, "user2"),
+	}
+
+	upTo := time.Now().Truncate(time.Millisecond)
+	if err := hub.AckConversationRead("user2", group.ID, upTo); err != nil {
+		t.Fatal(err)
+	}
+	for name, conn := range conns {
+		frame := readFrame(t, conn)
+		if frame.Type != FrameTypeReadUpTo || frame.ConversationID != group.ID || frame.UserID != "user2" ||
+			frame.ReadUpTo == nil || !frame.ReadUpTo.Equal(upTo) {
+			t.Errorf("%s got %+v, want read_up_to of group1 by user2", name, frame)
+		}
+	}
+}
+
+func TestHubUnregistersClosedConnections(t *testing.T) {
+	hub, _, _, server := newTestHub(t)
+	phone := dial(t, hub, server, "user1")
+	laptop := dial(t, hub, server, "user1")
+
+	// A client hanging up is noticed by the read pump
+	phone.Close()
+	waitFor(t, "closed connection to unregister", func() bool { return connectionCount(hub, "user1") == 1 })
+	if !hub.IsUserConnected("user1") {
+		t.Fatal("user1 should still be connected on the laptop")
+	}
+
+	// Removing a connection on the server side closes the client's socket
+	hub.mu.RLock()
+	var connectionID string
+	for id := range hub.connections["user1"] {
+		connectionID = id
+	}
+	hub.mu.RUnlock()
+	hub.RemoveConnection("user1", connectionID)
+
+	laptop.SetReadDeadline(time.Now().Add(testTimeout))
+	if _, _, err := laptop.ReadMessage(); !gorillaws.IsCloseError(err, gorillaws.CloseNormalClosure) {
+		t.Errorf("laptop read %v, want a normal close", err)
+	}
+	if hub.IsUserConnected("user1") {
+		t.Error("user1 should be disconnected once every connection is gone")
+	}
+}
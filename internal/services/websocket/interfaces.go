@@ -1,19 +1,27 @@
 package websocket
 
-import "github.com/kasasunil/chat_app/database"
+import (
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+	"github.com/kasasunil/chat_app/internal/pkg/errors"
+)
 
 // WebSocketManager defines the interface for WebSocket operations
-// This is mocked - real WebSocket implementation is not required
+// Hub is the real implementation, MockWebSocketManager only records connection IDs
 type WebSocketManager interface {
 	// SendMessage sends a message to a user's active connections
 	SendMessage(userID string, message *database.Message) error
 
-	// AckDelivered acknowledges that a message was delivered to a user's inbox
+	// AckDelivered notifies the sender that a message was delivered to a user's inbox
 	AckDelivered(userID string, messageID string) error
 
-	// AckRead acknowledges that a user has read a message
+	// AckRead notifies the sender that a user has read a message
 	AckRead(userID string, messageID string) error
 
+	// AckConversationRead notifies a conversation's participants that a user read it up to a time
+	AckConversationRead(userID, conversationID string, upTo time.Time) error
+
 	// AddConnection adds a connection for a user (simulating multiple devices)
 	AddConnection(userID string, connectionID string)
 
@@ -23,3 +31,14 @@ type WebSocketManager interface {
 	// IsUserConnected checks if a user has any active connections
 	IsUserConnected(userID string) bool
 }
+
+// AckProcessor applies acknowledgements received from WebSocket clients.
+// It must perform the same checks and store updates as the /ack/* HTTP handlers.
+type AckProcessor interface {
+	ProcessAckDelivered(userID string, messageID string) *errors.AppError
+	ProcessAckRead(userID string, messageID string) *errors.AppError
+
+	// Batch forms return one outcome per ID (nil when acknowledged), or an error for the whole batch
+	ProcessAckDeliveredBatch(userID string, messageIDs []string) ([]*errors.AppError, *errors.AppError)
+	ProcessAckReadBatch(userID string, messageIDs []string) ([]*errors.AppError, *errors.AppError)
+}
@@ -1,6 +1,10 @@
 package websocket
 
-import "github.com/kasasunil/chat_app/database"
+import (
+	"time"
+
+	"github.com/kasasunil/chat_app/database"
+)
 
 // MockWebSocketManager is an in-memory implementation of WebSocketManager
 type MockWebSocketManager struct {
@@ -68,3 +72,8 @@ func (m *MockWebSocketManager) AckRead(userID string, messageID string) error {
 	// In real implementation, this would come from WebSocket handler
 	return nil
 }
+
+// AckConversationRead simulates notifying a conversation that a user read it
+func (m *MockWebSocketManager) AckConversationRead(userID, conversationID string, upTo time.Time) error {
+	return nil
+}